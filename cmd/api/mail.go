@@ -0,0 +1,54 @@
+package main
+
+import (
+	"database/sql"
+	"os"
+	"school-api/internal/repositeries/repo"
+	"school-api/pkg/mailer"
+	"strconv"
+	"time"
+)
+
+// mailStore keeps the outbox in the mail_outbox table.
+type mailStore struct {
+	db *sql.DB
+}
+
+func (s mailStore) Claim(limit int, lease time.Duration) ([]mailer.Queued, error) {
+	return repo.ClaimMail(s.db, limit, lease)
+}
+
+func (s mailStore) MarkSent(id int) error {
+	return repo.MarkMailSent(s.db, id)
+}
+
+func (s mailStore) MarkFailed(id int, sendErr error, retryIn time.Duration, giveUp bool) error {
+	return repo.MarkMailFailed(s.db, id, sendErr, retryIn, giveUp)
+}
+
+// runMailOutbox delivers queued mail every MAIL_POLL_INTERVAL (10s by
+// default). A failed message is retried with doubling delays until
+// MAIL_MAX_ATTEMPTS (8 by default) is reached.
+func runMailOutbox(db *sql.DB, m mailer.Mailer) {
+	interval := 10 * time.Second
+	if d, err := time.ParseDuration(os.Getenv("MAIL_POLL_INTERVAL")); err == nil && d > 0 {
+		interval = d
+	}
+
+	maxAttempts := 8
+	if n, err := strconv.Atoi(os.Getenv("MAIL_MAX_ATTEMPTS")); err == nil && n > 0 {
+		maxAttempts = n
+	}
+
+	outbox := &mailer.Outbox{
+		Store:         mailStore{db: db},
+		Mailer:        m,
+		Batch:         10,
+		Lease:         10 * time.Minute,
+		RetryDelay:    time.Minute,
+		MaxRetryDelay: time.Hour,
+		MaxAttempts:   maxAttempts,
+	}
+
+	outbox.Run(interval)
+}
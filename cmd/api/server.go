@@ -6,6 +6,9 @@ import (
 	mw "school-api/internal/api/middlewares"
 	"school-api/internal/api/router"
 	"school-api/internal/repositeries/sqlconnect"
+	"school-api/pkg/keyring"
+	"school-api/pkg/mailer"
+	"school-api/pkg/utils"
 	"time"
 
 	"net/http"
@@ -27,11 +30,31 @@ func main() {
 		return
 	}
 
-	_, err = sqlconnect.ConnectDB()
+	// fail early on a bad JWT_KEYS_FILE rather than at the first login
+	if _, err := keyring.Default(); err != nil {
+		fmt.Println("Error loading signing keys:", err)
+		return
+	}
+
+	// the login cookies are useless for writes without a CSRF secret
+	if err := utils.CheckCSRFSecret(); err != nil {
+		fmt.Println("Error setting up CSRF protection:", err)
+		return
+	}
+
+	conn, err := sqlconnect.ConnectDB()
 	if err != nil {
 		fmt.Println("Error connecting to database:", err)
 		return
 	}
+
+	m, err := mailer.FromEnv()
+	if err != nil {
+		fmt.Println("Error setting up mail:", err)
+		return
+	}
+	go runMailOutbox(conn, m)
+
 	port := ":5173"
 
 	mux := http.NewServeMux()
@@ -46,7 +69,19 @@ func main() {
 	router.RegisterStudentsRoutes(mux)
 	router.RegisterTeachersRoutes(mux)
 	router.RegisterExecRoutes(mux)
-	jwtMiddleware := mw.MiddlewareExcludePaths(mw.JWTMiddleware, "/execs/login", "/execs/forgotPassword")
+	router.RegisterClassesRoutes(mux)
+	router.RegisterTermsRoutes(mux)
+	router.RegisterGradesRoutes(mux)
+	router.RegisterGuardiansRoutes(mux)
+	router.RegisterTimetableRoutes(mux)
+	router.RegisterExamsRoutes(mux)
+	router.RegisterHomeworkRoutes(mux)
+	router.RegisterAttachmentsRoutes(mux)
+	router.RegisterFeesRoutes(mux)
+	router.RegisterAdmissionsRoutes(mux)
+	router.RegisterWellKnownRoutes(mux)
+	router.RegisterOAuthRoutes(mux)
+	jwtMiddleware := mw.MiddlewareExcludePaths(mw.JWTMiddleware, "/execs/login", "/execs/refresh", "/execs/logout", "/execs/forgotPassword", "/execs/reset/password/", "/.well-known/", "/oauth/authorize", "/oauth/token", "/oauth/userinfo")
 	rl := mw.NewRateLimiter(400, time.Minute)
 	handler := applyMiddlewares(
 		mux,
@@ -0,0 +1,346 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"school-api/internal/models"
+	"school-api/internal/repositeries/db"
+	"school-api/internal/repositeries/repo"
+	"school-api/pkg/utils"
+	"strconv"
+	"time"
+)
+
+func GetApplicationsHandler(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status != "" && !repo.IsValidApplicationStatus(status) {
+		utils.Error(w, "Invalid status", nil)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	filters := map[string]string{
+		"status":      status,
+		"grade_level": r.URL.Query().Get("grade_level"),
+		"term_id":     r.URL.Query().Get("term"),
+		"email":       r.URL.Query().Get("email"),
+	}
+
+	search := r.URL.Query().Get("search")
+	sort := utils.BuildSort(r, map[string]bool{
+		"first_name": true,
+		"last_name":  true,
+		"created_at": true,
+		"updated_at": true,
+	})
+
+	applications, err := repo.FindApplications(db.DB, search, filters, sort)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if applications == nil {
+		applications = []models.Application{}
+	}
+
+	utils.SuccessWithCount(w, "Applications fetched successfully", len(applications), applications)
+}
+
+func GetApplicationByIdHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid application ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	application, err := repo.FindApplicationByID(id, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if application == nil {
+		utils.Error(w, "Application not found", nil)
+		return
+	}
+
+	utils.Success(w, "Application fetched successfully", application)
+}
+
+func AddApplicationHandler(w http.ResponseWriter, r *http.Request) {
+	var application models.Application
+	if err := json.NewDecoder(r.Body).Decode(&application); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	if application.FirstName == "" || application.LastName == "" || application.Email == "" {
+		utils.Error(w, "first_name, last_name and email are required", nil)
+		return
+	}
+
+	if application.DateOfBirth != "" {
+		if _, err := time.Parse(dateLayout, application.DateOfBirth); err != nil {
+			utils.Error(w, "date_of_birth must be in YYYY-MM-DD format", err)
+			return
+		}
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	submittedBy, _ := utils.UserFromContext(r.Context())
+
+	res, err := repo.AddApplication(db.DB, &application, submittedBy)
+	if err != nil {
+		utils.Error(w, "Failed to add application", err)
+		return
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		utils.Error(w, "Failed to retrieve ID", err)
+		return
+	}
+
+	application.ID = int(lastId)
+	application.Status = models.ApplicationSubmitted
+
+	utils.Success(w, "Application added successfully", application)
+}
+
+func UpdateApplicationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid application ID", err)
+		return
+	}
+
+	var update models.Application
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	if update.DateOfBirth != "" {
+		if _, err := time.Parse(dateLayout, update.DateOfBirth); err != nil {
+			utils.Error(w, "date_of_birth must be in YYYY-MM-DD format", err)
+			return
+		}
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	var existing models.Application
+
+	_, err = repo.UpdateApplication(db.DB, &existing, &update, id)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Application not found", err)
+		return
+	} else if err != nil {
+		utils.Error(w, "Failed to update application", err)
+		return
+	}
+
+	utils.Success(w, "Application updated successfully", update)
+}
+
+func DeleteApplicationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid application ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	err = repo.DeleteApplication(db.DB, id)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Application not found", err)
+		return
+	} else if err != nil {
+		utils.Error(w, "Failed to delete application", err)
+		return
+	}
+
+	utils.Success(w, "Application deleted successfully", nil)
+}
+
+func ChangeApplicationStatusHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid application ID", err)
+		return
+	}
+
+	var req models.ApplicationStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	if !repo.IsValidApplicationStatus(req.Status) {
+		utils.Error(w, "status must be one of under_review, interview, accepted, rejected, waitlisted", nil)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	changedBy, _ := utils.UserFromContext(r.Context())
+
+	err = repo.ChangeApplicationStatus(db.DB, id, req.Status, req.Note, changedBy)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Application not found", err)
+		return
+	} else if err != nil {
+		utils.Error(w, "Failed to change application status", err)
+		return
+	}
+
+	application, err := repo.FindApplicationByID(id, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	utils.Success(w, "Application status changed successfully", application)
+}
+
+func AddApplicationNoteHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid application ID", err)
+		return
+	}
+
+	var note models.ApplicationNote
+	if err := json.NewDecoder(r.Body).Decode(&note); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	if note.Note == "" {
+		utils.Error(w, "note is required", nil)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	application, err := repo.FindApplicationByID(id, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if application == nil {
+		utils.Error(w, "Application not found", nil)
+		return
+	}
+
+	note.AuthorId, _ = utils.UserFromContext(r.Context())
+
+	res, err := repo.AddApplicationNote(db.DB, id, note.Note, note.AuthorId)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		utils.Error(w, "Failed to retrieve ID", err)
+		return
+	}
+
+	note.ID = int(lastId)
+
+	utils.Success(w, "Note added successfully", note)
+}
+
+// EnrollApplicationHandler accepts the application and creates the student
+// in a class with room. When every suitable class is full the application
+// is waitlisted instead.
+func EnrollApplicationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid application ID", err)
+		return
+	}
+
+	var req models.EnrollApplicationRequest
+	// the body is optional
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	changedBy, _ := utils.UserFromContext(r.Context())
+
+	enrolled, err := repo.EnrollApplication(db.DB, id, &req, changedBy)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Application not found", err)
+		return
+	} else if err != nil {
+		utils.Error(w, "Failed to enroll application", err)
+		return
+	}
+
+	application, err := repo.FindApplicationByID(id, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if !enrolled {
+		utils.Success(w, "No class has room, application waitlisted", application)
+		return
+	}
+
+	utils.Success(w, "Application enrolled successfully", application)
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"school-api/internal/models"
+	"school-api/internal/repositeries/db"
+	"school-api/internal/repositeries/repo"
+	"school-api/pkg/rbac"
+	"school-api/pkg/utils"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultAPIKeyDays = 90
+	maxAPIKeyDays     = 365
+)
+
+func GetAPIKeysHandler(w http.ResponseWriter, r *http.Request) {
+	execId, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid exec ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	keys, err := repo.FindAPIKeys(db.DB, execId)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if keys == nil {
+		keys = []models.APIKey{}
+	}
+
+	utils.SuccessWithCount(w, "API keys fetched successfully", len(keys), keys)
+}
+
+// CreateAPIKeyHandler issues a key for the exec. The permissions must be a
+// subset of what the exec's role grants. The key is only ever shown in
+// this response.
+func CreateAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	execId, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid exec ID", err)
+		return
+	}
+
+	var req models.APIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+	defer r.Body.Close()
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		utils.Error(w, "Name is required", nil)
+		return
+	}
+
+	if len(req.Permissions) == 0 {
+		utils.Error(w, "At least one permission is required", nil)
+		return
+	}
+
+	if req.ExpiresInDays == 0 {
+		req.ExpiresInDays = defaultAPIKeyDays
+	}
+
+	if req.ExpiresInDays < 1 || req.ExpiresInDays > maxAPIKeyDays {
+		utils.Error(w, "expires_in_days must be between 1 and "+strconv.Itoa(maxAPIKeyDays), nil)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	exec, err := repo.FindExecByID(execId, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if exec == nil {
+		utils.Error(w, "Exec not found", nil)
+		return
+	}
+
+	granted := rbac.Permissions(exec.Role)
+	for _, p := range req.Permissions {
+		if !slices.Contains(granted, p) {
+			utils.Error(w, "Permission "+p+" is not granted to role "+exec.Role, nil)
+			return
+		}
+	}
+
+	slices.Sort(req.Permissions)
+	req.Permissions = slices.Compact(req.Permissions)
+
+	key, err := repo.CreateAPIKey(db.DB, execId, req.Name, req.Permissions, req.ExpiresInDays)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	utils.Success(w, "API key created successfully, store it now as it will not be shown again", key)
+}
+
+func RevokeAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	execId, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid exec ID", err)
+		return
+	}
+
+	keyId, err := strconv.Atoi(r.PathValue("keyId"))
+	if err != nil {
+		utils.Error(w, "Invalid API key ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	err = repo.RevokeAPIKey(db.DB, execId, keyId)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "API key not found", err)
+		return
+	} else if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	utils.Success(w, "API key revoked successfully", nil)
+}
@@ -0,0 +1,377 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"school-api/internal/models"
+	"school-api/internal/repositeries/db"
+	"school-api/internal/repositeries/repo"
+	"school-api/pkg/rbac"
+	"school-api/pkg/storage"
+	"school-api/pkg/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultAttachmentMaxBytes = 10 << 20
+
+// sniffed content types accepted for upload
+var allowedAttachmentTypes = map[string]bool{
+	"application/pdf":           true,
+	"image/jpeg":                true,
+	"image/png":                 true,
+	"image/gif":                 true,
+	"image/webp":                true,
+	"text/plain; charset=utf-8": true,
+}
+
+// fileStore opens the attachment storage configured by ATTACHMENT_DIR.
+func fileStore() (storage.Storage, error) {
+	dir := os.Getenv("ATTACHMENT_DIR")
+	if dir == "" {
+		dir = "uploads"
+	}
+	return storage.NewLocal(dir)
+}
+
+func attachmentMaxBytes() int64 {
+	if n, err := strconv.ParseInt(os.Getenv("ATTACHMENT_MAX_BYTES"), 10, 64); err == nil && n > 0 {
+		return n
+	}
+	return defaultAttachmentMaxBytes
+}
+
+func UploadStudentAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	uploadAttachment(w, r, models.OwnerStudent)
+}
+
+func UploadTeacherAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	uploadAttachment(w, r, models.OwnerTeacher)
+}
+
+func UploadExecAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	uploadAttachment(w, r, models.OwnerExec)
+}
+
+func GetStudentAttachmentsHandler(w http.ResponseWriter, r *http.Request) {
+	listAttachments(w, r, models.OwnerStudent)
+}
+
+func GetTeacherAttachmentsHandler(w http.ResponseWriter, r *http.Request) {
+	listAttachments(w, r, models.OwnerTeacher)
+}
+
+func GetExecAttachmentsHandler(w http.ResponseWriter, r *http.Request) {
+	listAttachments(w, r, models.OwnerExec)
+}
+
+// uploadAttachment reads the "file" part of a multipart body and stores it
+// for the owner in the path. The category comes from ?category=.
+func uploadAttachment(w http.ResponseWriter, r *http.Request, ownerType string) {
+	ownerId, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid "+ownerType+" ID", err)
+		return
+	}
+
+	category := r.URL.Query().Get("category")
+	if category == "" {
+		category = "other"
+	}
+
+	if !repo.IsValidAttachmentCategory(category) {
+		utils.Error(w, "category must be one of birth_certificate, medical_form, profile_photo, assignment, other", nil)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	exists, err := attachmentOwnerExists(r.Context(), db.DB, ownerType, ownerId)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if !exists {
+		utils.Error(w, strings.ToUpper(ownerType[:1])+ownerType[1:]+" not found", nil)
+		return
+	}
+
+	maxBytes := attachmentMaxBytes()
+	// leave room for the multipart framing around the file
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes+1<<20)
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		utils.Error(w, "Expected a multipart/form-data body", err)
+		return
+	}
+
+	var part io.Reader
+	var filename string
+	for {
+		p, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			utils.Error(w, "Invalid multipart body", err)
+			return
+		}
+
+		if p.FormName() == "file" {
+			part, filename = p, filepath.Base(p.FileName())
+			break
+		}
+	}
+
+	if part == nil || filename == "" || filename == "." {
+		utils.Error(w, "A file field is required", nil)
+		return
+	}
+
+	// nothing reaches the store until the content has been checked
+	upload, err := storage.Stage(part, maxBytes)
+
+	var maxErr *http.MaxBytesError
+	if errors.Is(err, storage.ErrTooLarge) || errors.As(err, &maxErr) {
+		utils.Error(w, fmt.Sprintf("File must be at most %d bytes", maxBytes), err)
+		return
+	} else if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer upload.Close()
+
+	if !allowedAttachmentTypes[upload.ContentType] {
+		utils.Error(w, "File type "+upload.ContentType+" is not allowed", nil)
+		return
+	}
+
+	if category == "profile_photo" && !strings.HasPrefix(upload.ContentType, "image/") {
+		utils.Error(w, "A profile photo must be an image", nil)
+		return
+	}
+
+	attachment := models.Attachment{
+		OwnerType:   ownerType,
+		OwnerId:     ownerId,
+		Category:    category,
+		Filename:    filename,
+		ContentType: upload.ContentType,
+		Size:        upload.Size,
+		Checksum:    upload.Key,
+	}
+	attachment.UploadedBy, _ = utils.UserFromContext(r.Context())
+
+	store, err := fileStore()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	res, err := repo.AddAttachment(db.DB, &attachment, store, upload)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		utils.Error(w, "Failed to retrieve ID", err)
+		return
+	}
+
+	attachment.ID = int(lastId)
+
+	utils.Success(w, "Attachment uploaded successfully", attachment)
+}
+
+func listAttachments(w http.ResponseWriter, r *http.Request, ownerType string) {
+	ownerId, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid "+ownerType+" ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	exists, err := attachmentOwnerExists(r.Context(), db.DB, ownerType, ownerId)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if !exists {
+		utils.Error(w, strings.ToUpper(ownerType[:1])+ownerType[1:]+" not found", nil)
+		return
+	}
+
+	attachments, err := repo.FindAttachments(db.DB, ownerType, ownerId, r.URL.Query().Get("category"))
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if attachments == nil {
+		attachments = []models.Attachment{}
+	}
+
+	utils.SuccessWithCount(w, "Attachments fetched successfully", len(attachments), attachments)
+}
+
+func GetAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid attachment ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	attachment, err := findVisibleAttachment(r.Context(), db.DB, id)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if attachment == nil {
+		utils.Error(w, "Attachment not found", nil)
+		return
+	}
+
+	utils.Success(w, "Attachment fetched successfully", attachment)
+}
+
+// DownloadAttachmentHandler streams the file. http.ServeContent handles
+// Range and conditional requests.
+func DownloadAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid attachment ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	attachment, err := findVisibleAttachment(r.Context(), db.DB, id)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if attachment == nil {
+		utils.Error(w, "Attachment not found", nil)
+		return
+	}
+
+	store, err := fileStore()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	file, err := store.Open(attachment.Checksum)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer file.Close()
+
+	disposition := "attachment"
+	if r.URL.Query().Get("inline") == "true" {
+		disposition = "inline"
+	}
+
+	modified, _ := time.Parse(time.DateTime, attachment.CreatedAt)
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("%s; filename=%q", disposition, attachment.Filename))
+	w.Header().Set("ETag", `"`+attachment.Checksum+`"`)
+	http.ServeContent(w, r, attachment.Filename, modified, file)
+}
+
+func DeleteAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid attachment ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	store, err := fileStore()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	err = repo.DeleteAttachment(db.DB, id, store)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Attachment not found", err)
+		return
+	} else if err != nil {
+		utils.Error(w, "Failed to delete attachment", err)
+		return
+	}
+
+	utils.Success(w, "Attachment deleted successfully", nil)
+}
+
+// findVisibleAttachment returns nil for attachments whose owner is outside
+// the caller's scope, as if they did not exist.
+func findVisibleAttachment(ctx context.Context, db *sql.DB, id int) (*models.Attachment, error) {
+	attachment, err := repo.FindAttachmentByID(id, db)
+	if err != nil || attachment == nil {
+		return nil, err
+	}
+
+	exists, err := attachmentOwnerExists(ctx, db, attachment.OwnerType, attachment.OwnerId)
+	if err != nil || !exists {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+func attachmentOwnerExists(ctx context.Context, db *sql.DB, ownerType string, id int) (bool, error) {
+	switch ownerType {
+	case models.OwnerStudent:
+		s, err := repo.FindStudentByID(ctx, id, db)
+		return s != nil, err
+	case models.OwnerTeacher:
+		t, err := repo.FindTeacherByID(ctx, id, db)
+		return t != nil, err
+	case models.OwnerExec:
+		// teachers only see their own exec record's files
+		if execId, role := utils.UserFromContext(ctx); role == rbac.RoleTeacher && execId != id {
+			return false, nil
+		}
+		e, err := repo.FindExecByID(id, db)
+		return e != nil, err
+	}
+	return false, nil
+}
@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"school-api/internal/models"
+	"school-api/internal/repositeries/db"
+	"school-api/internal/repositeries/repo"
+	"school-api/pkg/rbac"
+	"school-api/pkg/utils"
+	"strconv"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+func MarkClassAttendanceHandler(w http.ResponseWriter, r *http.Request) {
+	classId, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid class ID", err)
+		return
+	}
+
+	var req models.BulkAttendanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	if req.Date == "" {
+		req.Date = time.Now().Format(dateLayout)
+	}
+
+	if _, err := time.Parse(dateLayout, req.Date); err != nil {
+		utils.Error(w, "date must be in YYYY-MM-DD format", err)
+		return
+	}
+
+	if req.Period < 0 {
+		utils.Error(w, "period cannot be negative", nil)
+		return
+	}
+
+	if len(req.Records) == 0 {
+		utils.Error(w, "At least one attendance record is required", nil)
+		return
+	}
+
+	for _, record := range req.Records {
+		if !repo.IsValidAttendanceStatus(record.Status) {
+			utils.Error(w, fmt.Sprintf("Invalid status %q for student %d", record.Status, record.StudentId), nil)
+			return
+		}
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	class, err := repo.FindClassByID(classId, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if class == nil {
+		utils.Error(w, "Class not found", nil)
+		return
+	}
+
+	allowed, err := canManageClass(r, db.DB, classId)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if !allowed {
+		utils.Error(w, "You are not assigned to this class", nil)
+		return
+	}
+
+	execId, _ := utils.UserFromContext(r.Context())
+
+	marked, err := repo.MarkClassAttendance(db.DB, classId, &req, execId)
+	if err != nil {
+		utils.Error(w, "Failed to mark attendance", err)
+		return
+	}
+
+	utils.SuccessWithCount(w, "Attendance marked successfully", len(marked), marked)
+}
+
+func GetClassAttendanceHandler(w http.ResponseWriter, r *http.Request) {
+	classId, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid class ID", err)
+		return
+	}
+
+	from, to, err := getDateRange(r)
+	if err != nil {
+		utils.Error(w, "Invalid date range", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	if !classVisible(w, r, db.DB, classId, "Class not found") {
+		return
+	}
+
+	records, err := repo.FindAttendance(db.DB, 0, classId, from, to)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	summaries, err := repo.SummarizeAttendance(db.DB, 0, classId, from, to)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if records == nil {
+		records = []models.Attendance{}
+	}
+
+	utils.SuccessWithMeta(w, "Attendance fetched successfully", records, repo.TotalAttendance(summaries))
+}
+
+func GetClassAttendanceSummaryHandler(w http.ResponseWriter, r *http.Request) {
+	classId, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid class ID", err)
+		return
+	}
+
+	from, to, err := getDateRange(r)
+	if err != nil {
+		utils.Error(w, "Invalid date range", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	if !classVisible(w, r, db.DB, classId, "Class not found") {
+		return
+	}
+
+	summaries, err := repo.SummarizeAttendance(db.DB, 0, classId, from, to)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if summaries == nil {
+		summaries = []models.AttendanceSummary{}
+	}
+
+	utils.SuccessWithMeta(w, "Attendance summary fetched successfully", summaries, repo.TotalAttendance(summaries))
+}
+
+func GetStudentAttendanceHandler(w http.ResponseWriter, r *http.Request) {
+	studentId, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid student ID", err)
+		return
+	}
+
+	from, to, err := getDateRange(r)
+	if err != nil {
+		utils.Error(w, "Invalid date range", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	student, err := repo.FindStudentByID(r.Context(), studentId, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if student == nil {
+		utils.Error(w, "Student not found", nil)
+		return
+	}
+
+	records, err := repo.FindAttendance(db.DB, studentId, 0, from, to)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	summaries, err := repo.SummarizeAttendance(db.DB, studentId, 0, from, to)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if records == nil {
+		records = []models.Attendance{}
+	}
+
+	summary := repo.TotalAttendance(summaries)
+	summary.StudentId = studentId
+
+	utils.SuccessWithMeta(w, "Attendance fetched successfully", records, summary)
+}
+
+func getDateRange(r *http.Request) (string, string, error) {
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+
+	if from != "" {
+		if _, err := time.Parse(dateLayout, from); err != nil {
+			return "", "", fmt.Errorf("from must be in YYYY-MM-DD format")
+		}
+	}
+	if to != "" {
+		if _, err := time.Parse(dateLayout, to); err != nil {
+			return "", "", fmt.Errorf("to must be in YYYY-MM-DD format")
+		}
+	}
+	if from != "" && to != "" && from > to {
+		return "", "", fmt.Errorf("from must not be after to")
+	}
+
+	return from, to, nil
+}
+
+// canManageClass reports whether the logged in user may record data for a
+// class. Teachers are limited to the classes they are assigned to; other
+// roles are not restricted here.
+func canManageClass(r *http.Request, db *sql.DB, classId int) (bool, error) {
+	execId, role := utils.UserFromContext(r.Context())
+
+	if role != rbac.RoleTeacher {
+		return true, nil
+	}
+
+	teacherId, err := repo.FindTeacherIDByExec(db, execId)
+	if err != nil || teacherId == 0 {
+		return false, err
+	}
+
+	return repo.TeacherTeachesClass(db, teacherId, classId)
+}
+
+// classVisible reports whether the class is in the caller's scope. When it
+// is not, notFound has been written, so the row looks like it does not
+// exist.
+func classVisible(w http.ResponseWriter, r *http.Request, db *sql.DB, classId int, notFound string) bool {
+	visible, err := repo.ClassInScope(r.Context(), db, classId)
+	if err != nil {
+		utils.Http500(w, err)
+		return false
+	} else if !visible {
+		utils.Error(w, notFound, nil)
+		return false
+	}
+	return true
+}
+
+// studentVisible is classVisible for a student.
+func studentVisible(w http.ResponseWriter, r *http.Request, db *sql.DB, studentId int) bool {
+	student, err := repo.FindStudentByID(r.Context(), studentId, db)
+	if err != nil {
+		utils.Http500(w, err)
+		return false
+	} else if student == nil {
+		utils.Error(w, "Student not found", nil)
+		return false
+	}
+	return true
+}
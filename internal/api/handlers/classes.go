@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"school-api/internal/models"
+	"school-api/internal/repositeries/db"
+	"school-api/internal/repositeries/repo"
+	"school-api/pkg/utils"
+	"strconv"
+)
+
+func GetClassByIdHandler(w http.ResponseWriter, r *http.Request) {
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid class ID", err)
+		return
+	}
+
+	if !classVisible(w, r, db.DB, id, "Class not found") {
+		return
+	}
+
+	class, err := repo.FindClassByID(id, db.DB)
+
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if class == nil {
+		utils.Error(w, "Class not found", nil)
+		return
+	}
+
+	utils.Success(w, "Class fetched successfully", class)
+}
+
+func GetClassesHandler(w http.ResponseWriter, r *http.Request) {
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	filters := map[string]string{
+		"name":                r.URL.Query().Get("name"),
+		"grade_level":         r.URL.Query().Get("grade_level"),
+		"room":                r.URL.Query().Get("room"),
+		"homeroom_teacher_id": r.URL.Query().Get("homeroom_teacher_id"),
+	}
+
+	search := r.URL.Query().Get("search")
+	sort := utils.BuildSort(r, map[string]bool{
+		"name":        true,
+		"grade_level": true,
+		"room":        true,
+		"capacity":    true,
+	})
+
+	classes, err := repo.FindClass(r.Context(), db.DB, search, filters, sort)
+
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if classes == nil {
+		classes = []models.Class{}
+	}
+
+	utils.SuccessWithCount(w, "Classes fetched successfully", len(classes), classes)
+}
+
+func AddClassHandler(w http.ResponseWriter, r *http.Request) {
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	var class models.Class
+
+	if err := json.NewDecoder(r.Body).Decode(&class); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	if class.Name == "" {
+		utils.Error(w, "Class name is required", nil)
+		return
+	}
+
+	if class.Capacity < 0 {
+		utils.Error(w, "Capacity cannot be negative", nil)
+		return
+	}
+
+	res, err := repo.AddClass(db.DB, &class)
+
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		utils.Error(w, "Failed to retrieve ID", err)
+		return
+	}
+
+	class.ID = int(lastId)
+
+	utils.Success(w, "Class added successfully", class)
+}
+
+func UpdateClassHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid class ID", err)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	var updateClass models.Class
+	if err := json.Unmarshal(body, &updateClass); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	// an omitted or 0 homeroom_teacher_id keeps the current one; null clears it
+	var fields map[string]json.RawMessage
+	json.Unmarshal(body, &fields)
+	clearHomeroom := string(fields["homeroom_teacher_id"]) == "null"
+
+	if updateClass.Capacity < 0 {
+		utils.Error(w, "Capacity cannot be negative", nil)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	var existingClass models.Class
+
+	_, err = repo.UpdateClass(db.DB, &existingClass, &updateClass, id, clearHomeroom)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Class not found", err)
+		return
+	} else if err != nil {
+		utils.Error(w, "Failed to update class", err)
+		return
+	}
+
+	utils.Success(w, "Class updated successfully", updateClass)
+}
+
+func DeleteClassHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid class ID", err)
+		return
+	}
+
+	reassignTo := 0
+	if v := r.URL.Query().Get("reassign_to"); v != "" {
+		reassignTo, err = strconv.Atoi(v)
+		if err != nil {
+			utils.Error(w, "Invalid reassign_to class ID", err)
+			return
+		}
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	err = repo.DeleteClass(db.DB, id, reassignTo)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Class not found", err)
+		return
+	} else if errors.Is(err, repo.ErrClassFull) {
+		utils.Error(w, "The class to reassign students to is full", err)
+		return
+	} else if err != nil {
+		utils.Error(w, "Failed to delete class", err)
+		return
+	}
+
+	utils.Success(w, "Class deleted successfully", nil)
+}
+
+func GetClassStudentsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid class ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	if !classVisible(w, r, db.DB, id, "Class not found") {
+		return
+	}
+
+	termId, err := getTermParam(r, db.DB)
+	if err != nil {
+		utils.Error(w, "Invalid term", err)
+		return
+	}
+
+	students, err := repo.FindStudentsByClass(db.DB, id, termId)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if students == nil {
+		students = []models.Student{}
+	}
+
+	utils.SuccessWithCount(w, "Students fetched successfully", len(students), students)
+}
+
+func GetClassTeachersHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid class ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	if !classVisible(w, r, db.DB, id, "Class not found") {
+		return
+	}
+
+	termId, err := getTermParam(r, db.DB)
+	if err != nil {
+		utils.Error(w, "Invalid term", err)
+		return
+	}
+
+	teachers, err := repo.FindTeachersByClass(db.DB, id, termId)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if teachers == nil {
+		teachers = []models.Teacher{}
+	}
+
+	utils.SuccessWithCount(w, "Teachers fetched successfully", len(teachers), teachers)
+}
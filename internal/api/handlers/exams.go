@@ -0,0 +1,368 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"school-api/internal/models"
+	"school-api/internal/repositeries/db"
+	"school-api/internal/repositeries/repo"
+	"school-api/pkg/utils"
+	"strconv"
+	"time"
+)
+
+func GetExamsHandler(w http.ResponseWriter, r *http.Request) {
+	classId := 0
+	if v := r.URL.Query().Get("class_id"); v != "" {
+		var err error
+		classId, err = strconv.Atoi(v)
+		if err != nil {
+			utils.Error(w, "Invalid class ID", err)
+			return
+		}
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	termId, err := getTermParam(r, db.DB)
+	if err != nil {
+		utils.Error(w, "Invalid term", err)
+		return
+	}
+
+	exams, err := repo.FindExams(db.DB, termId, classId)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if exams == nil {
+		exams = []models.Exam{}
+	}
+
+	utils.SuccessWithCount(w, "Exams fetched successfully", len(exams), exams)
+}
+
+func GetExamByIdHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid exam ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	exam, err := repo.FindExamByID(id, db.DB)
+
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if exam == nil {
+		utils.Error(w, "Exam not found", nil)
+		return
+	}
+
+	utils.Success(w, "Exam fetched successfully", exam)
+}
+
+func AddExamHandler(w http.ResponseWriter, r *http.Request) {
+	var exam models.Exam
+	if err := json.NewDecoder(r.Body).Decode(&exam); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	if exam.Name == "" || exam.Subject == "" || exam.ExamDate == "" || exam.StartTime == "" || exam.DurationMinutes == 0 || len(exam.ClassIds) == 0 {
+		utils.Error(w, "name, subject, exam_date, start_time, duration_minutes and class_ids are required", nil)
+		return
+	}
+
+	if err := validateExam(&exam); err != nil {
+		utils.Error(w, "Invalid exam", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	if exam.TermId == 0 {
+		exam.TermId, err = repo.CurrentTermID(db.DB)
+		if err != nil {
+			utils.Http500(w, err)
+			return
+		} else if exam.TermId == 0 {
+			utils.Error(w, "term_id is required when no current term is set", nil)
+			return
+		}
+	}
+
+	res, err := repo.AddExam(db.DB, &exam)
+	if err != nil {
+		utils.Error(w, "Failed to add exam", err)
+		return
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		utils.Error(w, "Failed to retrieve ID", err)
+		return
+	}
+
+	exam.ID = int(lastId)
+
+	utils.Success(w, "Exam added successfully", exam)
+}
+
+func UpdateExamHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid exam ID", err)
+		return
+	}
+
+	var update models.Exam
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	if err := validateExam(&update); err != nil {
+		utils.Error(w, "Invalid exam", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	var existing models.Exam
+
+	_, err = repo.UpdateExam(db.DB, &existing, &update, id)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Exam not found", err)
+		return
+	} else if err != nil {
+		utils.Error(w, "Failed to update exam", err)
+		return
+	}
+
+	utils.Success(w, "Exam updated successfully", update)
+}
+
+func DeleteExamHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid exam ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	err = repo.DeleteExam(db.DB, id)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Exam not found", err)
+		return
+	} else if err != nil {
+		utils.Error(w, "Failed to delete exam", err)
+		return
+	}
+
+	utils.Success(w, "Exam deleted successfully", nil)
+}
+
+func AssignInvigilatorHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid exam ID", err)
+		return
+	}
+
+	var inv models.ExamInvigilator
+	if err := json.NewDecoder(r.Body).Decode(&inv); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	if inv.TeacherId == 0 {
+		utils.Error(w, "teacher_id is required", nil)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	teacher, err := repo.FindTeacherByID(r.Context(), inv.TeacherId, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if teacher == nil {
+		utils.Error(w, "Teacher not found", nil)
+		return
+	}
+
+	err = repo.AssignInvigilator(db.DB, id, &inv)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Exam not found", err)
+		return
+	} else if err != nil {
+		utils.Error(w, "Failed to assign invigilator", err)
+		return
+	}
+
+	inv.TeacherName = teacher.FirstName + " " + teacher.LastName
+
+	utils.Success(w, "Invigilator assigned successfully", inv)
+}
+
+func RemoveInvigilatorHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid exam ID", err)
+		return
+	}
+
+	teacherId, err := strconv.Atoi(r.PathValue("teacherId"))
+	if err != nil {
+		utils.Error(w, "Invalid teacher ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	err = repo.RemoveInvigilator(db.DB, id, teacherId)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Teacher does not invigilate this exam", err)
+		return
+	} else if err != nil {
+		utils.Error(w, "Failed to remove invigilator", err)
+		return
+	}
+
+	utils.Success(w, "Invigilator removed successfully", nil)
+}
+
+func GenerateSeatingPlanHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid exam ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	seats, err := repo.GenerateSeatingPlan(db.DB, id)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Exam not found", err)
+		return
+	} else if err != nil {
+		utils.Error(w, "Failed to generate seating plan", err)
+		return
+	}
+
+	utils.SuccessWithCount(w, "Seating plan generated successfully", len(seats), seats)
+}
+
+func GetSeatingPlanHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid exam ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	seats, err := repo.FindSeatingPlan(db.DB, id)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if seats == nil {
+		seats = []models.ExamSeat{}
+	}
+
+	utils.SuccessWithCount(w, "Seating plan fetched successfully", len(seats), seats)
+}
+
+// validateExam checks the fields that are set, so it serves both adds and
+// partial updates.
+func validateExam(e *models.Exam) error {
+	if e.ExamDate != "" {
+		if _, err := time.Parse(dateLayout, e.ExamDate); err != nil {
+			return fmt.Errorf("exam_date must be YYYY-MM-DD")
+		}
+	}
+
+	if e.StartTime != "" {
+		start, err := time.Parse(clockLayout, e.StartTime)
+		if err != nil {
+			return fmt.Errorf("start_time must be HH:MM")
+		}
+		e.StartTime = start.Format(clockLayout)
+	}
+
+	if e.DurationMinutes < 0 {
+		return fmt.Errorf("duration_minutes must be positive")
+	}
+
+	rooms := map[string]bool{}
+	for _, room := range e.Rooms {
+		if room.Room == "" || room.Capacity <= 0 {
+			return fmt.Errorf("each room needs a name and a positive capacity")
+		}
+		if rooms[room.Room] {
+			return fmt.Errorf("room %s is listed twice", room.Room)
+		}
+		rooms[room.Room] = true
+	}
+
+	return nil
+}
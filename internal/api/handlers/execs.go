@@ -13,8 +13,12 @@ import (
 	"school-api/internal/repositeries/db"
 	"school-api/internal/repositeries/repo"
 	"school-api/internal/repositeries/sqlconnect"
+	"school-api/pkg/mailer"
+	"school-api/pkg/rbac"
 	"school-api/pkg/utils"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -106,8 +110,36 @@ func AddExecHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// service accounts get a password nobody knows, and so do people who
+	// are invited to choose their own
+	invite := !exec.ServiceAccount && exec.Password == ""
+	if invite && exec.Email == "" {
+		utils.Error(w, "Email is required to invite an exec without a password", nil)
+		return
+	}
+
+	if _, err := resetURL(); invite && err != nil {
+		utils.Error(w, "Invitations cannot be sent", err)
+		return
+	}
+
 	if exec.Password == "" {
-		utils.Error(w, "Password is required", nil)
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			utils.Http500(w, err)
+			return
+		}
+		exec.Password = hex.EncodeToString(b)
+	}
+
+	if !rbac.IsValidRole(exec.Role) {
+		utils.Error(w, "role must be one of "+strings.Join(rbac.Roles(), ", "), nil)
+		return
+	}
+
+	// only admins may hand out the admin role
+	if _, role := utils.UserFromContext(r.Context()); exec.Role == rbac.RoleAdmin && role != rbac.RoleAdmin {
+		utils.Error(w, "Only admins can create admins", nil)
 		return
 	}
 
@@ -138,9 +170,50 @@ func AddExecHandler(w http.ResponseWriter, r *http.Request) {
 	exec.ID = int(lastId)
 	exec.Password = ""
 
+	if invite {
+		if err := queuePasswordLink(db.DB, &exec, mailer.Invitation, inviteValidity()); err != nil {
+			utils.Error(w, "Exec added but the invitation could not be sent, they can use forgot password instead", err)
+			return
+		}
+
+		utils.Success(w, "Exec added successfully, an invitation has been sent", exec)
+		return
+	}
+
 	utils.Success(w, "Exec added successfully", exec)
 }
 
+// inviteValidity is how long an invitation link works, INVITE_EXPIRY_HOURS
+// or three days.
+func inviteValidity() time.Duration {
+	if hours, err := strconv.Atoi(os.Getenv("INVITE_EXPIRY_HOURS")); err == nil && hours > 0 {
+		return time.Duration(hours) * time.Hour
+	}
+	return 72 * time.Hour
+}
+
+// GetMyPermissionsHandler returns the logged in exec's role and the
+// permissions it grants, for the front end to decide what to show.
+func GetMyPermissionsHandler(w http.ResponseWriter, r *http.Request) {
+	id, role := utils.UserFromContext(r.Context())
+	username, _ := r.Context().Value("username").(string)
+
+	// an API key only holds what it was given
+	permissions := rbac.Permissions(role)
+	if keyPermissions, ok := utils.APIKeyPermissionsFromContext(r.Context()); ok {
+		permissions = slices.DeleteFunc(permissions, func(p string) bool {
+			return !slices.Contains(keyPermissions, p)
+		})
+	}
+
+	utils.Success(w, "Permissions fetched successfully", models.ExecPermissions{
+		ID:          id,
+		Username:    username,
+		Role:        role,
+		Permissions: permissions,
+	})
+}
+
 func UpdateExecHandler(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(r.PathValue("id"))
 	if err != nil {
@@ -163,7 +236,7 @@ func UpdateExecHandler(w http.ResponseWriter, r *http.Request) {
 
 	var existingStudent models.Student
 
-	_, err = repo.UpdateStudent(db.DB, &existingStudent, &updateStudent, id)
+	_, err = repo.UpdateStudent(r.Context(), db.DB, &existingStudent, &updateStudent, id)
 
 	if err == sql.ErrNoRows {
 		utils.Error(w, "Teacher not found", err)
@@ -217,6 +290,35 @@ func DeleteExecHandler(w http.ResponseWriter, r *http.Request) {
 	utils.Success(w, "Teacher deleted successfully", nil)
 }
 
+// UnlockExecHandler lifts a lockout from too many failed sign-ins before it
+// runs out.
+func UnlockExecHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid exec ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	err = repo.UnlockExec(db.DB, id)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Exec not found", err)
+		return
+	} else if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	utils.Success(w, "Exec unlocked successfully", nil)
+}
+
 func LoginHandler(w http.ResponseWriter, r *http.Request) {
 
 	var req models.Exec
@@ -246,70 +348,108 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	defer db.Close()
 
-	err = db.QueryRow("SELECT id , username, role, password ,inactive FROM execs WHERE username=?", req.Username).Scan(&exec.ID, &exec.Username, &exec.Role, &exec.Password, &exec.Inactive)
-
-	if err == sql.ErrNoRows {
-		utils.Error(w, "user not found", err)
+	keys := throttleKeys(r, throttleLogin, req.Username)
+	if throttled(w, db, keys) {
 		return
 	}
 
-	if err != nil {
-		utils.Http500(w, err)
-		return
-	}
+	var twoFactor bool
 
-	if exec.Inactive {
-		utils.Error(w, "user is not active", nil)
+	err = db.QueryRow("SELECT id , username, email, role, password ,inactive, totp_enabled, service_account FROM execs WHERE username=?", req.Username).Scan(&exec.ID, &exec.Username, &exec.Email, &exec.Role, &exec.Password, &exec.Inactive, &twoFactor, &exec.ServiceAccount)
+
+	if err != nil && err != sql.ErrNoRows {
+		utils.Http500(w, err)
 		return
 	}
 
 	//verify passs
+	// an unknown username fails the same way, and as slowly, as a wrong
+	// password
+	isVerified := false
+	if err == sql.ErrNoRows {
+		repo.VerifyDummyPassword(req.Password)
+	} else {
+		isVerified, err = repo.VerifyPassword(req.Password, exec.Password)
 
-	isVerified, err := repo.VerifyPassword(req.Password, exec.Password)
-
-	if err != nil {
-		utils.Error(w, "Error during pass verify", err)
-		return
+		if err != nil {
+			utils.Error(w, "Error during pass verify", err)
+			return
+		}
 	}
 
 	if !isVerified {
+		locked, err := recordFailure(db, keys)
+		if err != nil {
+			utils.Http500(w, err)
+			return
+		}
+
+		if locked {
+			queueLockoutNotice(db, exec, throttlePolicy(throttleLogin, repo.ThrottleUser).Lockout)
+		}
+
 		utils.Error(w, "username or pass is wrong", nil)
 		return
 	}
 
-	//genreate token
-	token, err := utils.SignToken(exec.ID, req.Username, exec.Role)
+	if exec.Inactive {
+		utils.Error(w, "user is not active", nil)
+		return
+	}
 
-	if err != nil {
-		utils.Error(w, "JWT error", err)
+	if exec.ServiceAccount {
+		utils.Error(w, "Service accounts sign in with API keys", nil)
 		return
 	}
 
-	// send token as cookie
+	// with 2FA on, the password only earns a challenge for the second step
+	if twoFactor {
+		challenge, expires, err := utils.SignChallengeToken(exec.ID)
+		if err != nil {
+			utils.Error(w, "JWT error", err)
+			return
+		}
 
-	http.SetCookie(w, &http.Cookie{
-		Name:     "Bearer",
-		Value:    token,
-		Path:     "/",
-		HttpOnly: true,
-		Secure:   true,
-		Expires:  time.Now().Add(24 * time.Hour),
-	})
+		utils.Success(w, "Two-factor code required", models.LoginChallenge{
+			TwoFactorRequired: true,
+			ChallengeToken:    challenge,
+			ExpiresAt:         expires.Format(time.RFC3339),
+		})
+		return
+	}
 
-	utils.Success(w, "Logged in Successfully", token)
+	//start a session and issue its tokens
+	startSession(w, r, db, exec, false)
 
 }
 
+// LogoutHandler revokes the session the refresh token belongs to, so it
+// works after the access token has expired, then clears the cookies.
 func LogoutHandler(w http.ResponseWriter, r *http.Request) {
-	http.SetCookie(w, &http.Cookie{
-
-		Name:     "Bearer",
-		Value:    "",
-		Path:     "/",
-		HttpOnly: true,
-		Secure:   true,
-		Expires:  time.Unix(0, 0),
-	})
+	refreshToken, ok := readRefreshToken(w, r)
+	if !ok {
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	err = repo.RevokeSessionByRefreshToken(db.DB, refreshToken, "logout")
+
+	if err == sql.ErrNoRows {
+		clearAuthCookies(w)
+		http.Error(w, "Unauthorized: invalid refresh token", http.StatusUnauthorized)
+		return
+	} else if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	clearAuthCookies(w)
 
 	utils.Success(w, "Logged out successfully", nil)
 
@@ -352,8 +492,9 @@ func UpdatePasswordHandler(w http.ResponseWriter, r *http.Request) {
 
 	var username string
 	var userPassword string
+	var role string
 
-	err = db.QueryRow("SELECT username, password FROM execs WHERE id =?", userId).Scan(&username, &userPassword)
+	err = db.QueryRow("SELECT username, password, role FROM execs WHERE id =?", userId).Scan(&username, &userPassword, &role)
 
 	if err != nil {
 		utils.Error(w, "User not found", err)
@@ -378,16 +519,48 @@ func UpdatePasswordHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	currentTime := time.Now().Format(time.RFC3339)
-
-	_, err = db.Exec("UPDATE execs SET password =? , password_changed_at =? WHERE id= ?", hashedPassword, currentTime, userId)
+	// NOW() so IsTokenValid can compare it with a token's issue time in SQL
+	_, err = db.Exec("UPDATE execs SET password =? , password_changed_at =NOW() WHERE id= ?", hashedPassword, userId)
 
 	if err != nil {
 		utils.Error(w, "Error while updating password", err)
 		return
 	}
 
-	utils.Success(w, "Password updated successfully", nil)
+	// tokens issued before now are rejected from here on; sign the other
+	// sessions out and give the exec's own session a fresh token
+	callerId, _ := utils.UserFromContext(r.Context())
+	sid := utils.SessionFromContext(r.Context())
+
+	if callerId != userId {
+		err = repo.RevokeAllSessions(db, userId, "password_changed")
+		if err != nil {
+			utils.Http500(w, err)
+			return
+		}
+
+		utils.Success(w, "Password updated successfully", models.UpdatePasswordResponse{PasswordUpdated: true})
+		return
+	}
+
+	err = repo.RevokeOtherSessions(db, userId, sid, "password_changed")
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	token, expires, err := utils.SignToken(userId, username, role, sid, utils.TwoFactorFromContext(r.Context()))
+	if err != nil {
+		utils.Error(w, "JWT error", err)
+		return
+	}
+
+	setAccessCookie(w, token, expires)
+
+	utils.Success(w, "Password updated successfully", models.UpdatePasswordResponse{
+		Token:           token,
+		PasswordUpdated: true,
+	})
 
 }
 
@@ -405,19 +578,8 @@ func ForgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	r.Body.Close()
 
-	db, err := sqlconnect.ConnectDB()
-
-	if err != nil {
-		utils.Error(w, "failed to connect db", err)
-		return
-	}
-
-	defer db.Close()
-	var exec models.Exec
-	err = db.QueryRow("SELECT id FROM execs WHERE email=?", req.Email).Scan(&exec.ID)
-
-	if err != nil {
-		utils.Error(w, "user not found", err)
+	if req.Email == "" {
+		utils.Error(w, "Email is required", nil)
 		return
 	}
 
@@ -430,35 +592,39 @@ func ForgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
 
 	mins := time.Duration(duration)
 
-	expiry := time.Now().Add(mins * time.Minute).Format(time.RFC3339)
+	// refuse before looking anyone up, so the answer does not depend on
+	// whether the email is known
+	if _, err := resetURL(); err != nil {
+		utils.Http500(w, err)
+		return
+	}
 
-	tokenByte := make([]byte, 32)
-	_, err = rand.Read(tokenByte)
+	db, err := sqlconnect.ConnectDB()
 
 	if err != nil {
-		utils.Error(w, "Error parsing token", err)
+		utils.Error(w, "failed to connect db", err)
 		return
 	}
 
-	token := hex.EncodeToString(tokenByte)
-
-	hashedToken := sha256.Sum256(tokenByte)
-
-	hashedTokenStr := hex.EncodeToString(hashedToken[:])
-	fmt.Println(hashedTokenStr, expiry, exec.ID, "expiry")
-
-	_, err = db.Exec("UPDATE execs SET password_reset_token =?, password_token_expires=? WHERE id=?", hashedTokenStr, expiry, exec.ID)
+	defer db.Close()
 
-	if err != nil {
-		utils.Error(w, "failed to update db", err)
+	// every request counts, whether or not the email is known
+	keys := throttleKeys(r, throttleForgot, req.Email)
+	if throttled(w, db, keys) {
 		return
 	}
 
-	resetUrl := fmt.Sprintf("/execs/resetpassword/%s", token)
+	if _, err := recordFailure(db, keys); err != nil {
+		utils.Http500(w, err)
+		return
+	}
 
-	message := fmt.Sprintf("Reset pass using the following link: %s , you have %d minutes", resetUrl, int(mins))
+	// the answer is the same for unknown emails so they cannot be probed,
+	// and it does not wait for the lookup, which would take longer for a
+	// known email
+	go sendPasswordReset(req.Email, mins*time.Minute)
 
-	utils.Success(w, message, nil)
+	utils.Success(w, "If an account exists for that email, a password reset link has been sent", nil)
 
 }
 
@@ -498,22 +664,37 @@ func ResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
 
 	defer db.Close()
 
+	// guessing codes is limited per address
+	keys := throttleKeys(r, throttleReset, "")
+	if throttled(w, db, keys) {
+		return
+	}
+
 	var user models.Exec
 	bytes, err := hex.DecodeString(token)
 	if err != nil {
-		utils.Error(w, "failed to read token", err)
+		if _, err := recordFailure(db, keys); err != nil {
+			utils.Http500(w, err)
+			return
+		}
+		utils.Error(w, "Invalid or expired code", nil)
 		return
 	}
 	hashedToken := sha256.Sum256(bytes)
 	hashedTokenString := hex.EncodeToString(hashedToken[:])
 
-	fmt.Println(token, "<<<>>>")
-
 	query := "SELECT id,email FROM execs WHERE password_reset_token=? AND password_token_expires >?"
 	err = db.QueryRow(query, hashedTokenString, time.Now().Format(time.RFC3339)).Scan(&user.ID, &user.Email)
 
-	if err != nil {
-		utils.Error(w, "Invalid or expired code", err)
+	if err == sql.ErrNoRows {
+		if _, err := recordFailure(db, keys); err != nil {
+			utils.Http500(w, err)
+			return
+		}
+		utils.Error(w, "Invalid or expired code", nil)
+		return
+	} else if err != nil {
+		utils.Http500(w, err)
 		return
 	}
 
@@ -523,10 +704,17 @@ func ResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
 		utils.Error(w, "Internal error", err)
 		return
 	}
-	updateQuery := "UPDATE execs SET password=? ,password_reset_token =NULL , password_token_expires=NULL ,password_changed_at=? WHERE id =?"
+	updateQuery := "UPDATE execs SET password=? ,password_reset_token =NULL , password_token_expires=NULL ,password_changed_at=NOW() WHERE id =?"
+
+	_, err = db.Exec(updateQuery, hashedPassword, user.ID)
 
-	_, err = db.Exec(updateQuery, hashedPassword, time.Now().Format(time.RFC3339), user.ID)
+	if err != nil {
+		utils.Error(w, "Internal db error", err)
+		return
+	}
 
+	// whoever held the old password is signed out everywhere
+	err = repo.RevokeAllSessions(db, user.ID, "password_reset")
 	if err != nil {
 		utils.Error(w, "Internal db error", err)
 		return
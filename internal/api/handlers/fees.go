@@ -0,0 +1,573 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"school-api/internal/models"
+	"school-api/internal/repositeries/db"
+	"school-api/internal/repositeries/repo"
+	"school-api/pkg/utils"
+	"strconv"
+	"time"
+)
+
+func GetFeeStructuresHandler(w http.ResponseWriter, r *http.Request) {
+	classId := 0
+	if v := r.URL.Query().Get("class_id"); v != "" {
+		var err error
+		classId, err = strconv.Atoi(v)
+		if err != nil {
+			utils.Error(w, "Invalid class ID", err)
+			return
+		}
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	termId, err := getTermParam(r, db.DB)
+	if err != nil {
+		utils.Error(w, "Invalid term", err)
+		return
+	}
+
+	fees, err := repo.FindFeeStructures(db.DB, termId, classId)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if fees == nil {
+		fees = []models.FeeStructure{}
+	}
+
+	utils.SuccessWithCount(w, "Fee structures fetched successfully", len(fees), fees)
+}
+
+func GetFeeStructureByIdHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid fee structure ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	fee, err := repo.FindFeeStructureByID(id, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if fee == nil {
+		utils.Error(w, "Fee structure not found", nil)
+		return
+	}
+
+	utils.Success(w, "Fee structure fetched successfully", fee)
+}
+
+func AddFeeStructureHandler(w http.ResponseWriter, r *http.Request) {
+	var fee models.FeeStructure
+	if err := json.NewDecoder(r.Body).Decode(&fee); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	if fee.TermId == 0 || fee.Name == "" {
+		utils.Error(w, "term_id and name are required", nil)
+		return
+	}
+
+	if fee.Amount <= 0 {
+		utils.Error(w, "amount must be a positive number of minor units", nil)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	res, err := repo.AddFeeStructure(db.DB, &fee)
+	if err != nil {
+		utils.Error(w, "Failed to add fee structure", err)
+		return
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		utils.Error(w, "Failed to retrieve ID", err)
+		return
+	}
+
+	fee.ID = int(lastId)
+
+	utils.Success(w, "Fee structure added successfully", fee)
+}
+
+func UpdateFeeStructureHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid fee structure ID", err)
+		return
+	}
+
+	var update models.FeeStructure
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	if update.Amount < 0 {
+		utils.Error(w, "amount must be a positive number of minor units", nil)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	var existing models.FeeStructure
+
+	_, err = repo.UpdateFeeStructure(db.DB, &existing, &update, id)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Fee structure not found", err)
+		return
+	} else if err != nil {
+		utils.Error(w, "Failed to update fee structure", err)
+		return
+	}
+
+	utils.Success(w, "Fee structure updated successfully", update)
+}
+
+func DeleteFeeStructureHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid fee structure ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	err = repo.DeleteFeeStructure(db.DB, id)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Fee structure not found", err)
+		return
+	} else if err != nil {
+		utils.Error(w, "Failed to delete fee structure", err)
+		return
+	}
+
+	utils.Success(w, "Fee structure deleted successfully", nil)
+}
+
+func GetStudentDiscountsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid student ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	discounts, err := repo.FindDiscounts(db.DB, id)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if discounts == nil {
+		discounts = []models.Discount{}
+	}
+
+	utils.SuccessWithCount(w, "Discounts fetched successfully", len(discounts), discounts)
+}
+
+func AddStudentDiscountHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid student ID", err)
+		return
+	}
+
+	var discount models.Discount
+	if err := json.NewDecoder(r.Body).Decode(&discount); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	if discount.Name == "" {
+		utils.Error(w, "name is required", nil)
+		return
+	}
+
+	switch discount.Kind {
+	case models.DiscountPercent:
+		if discount.Value <= 0 || discount.Value > 10000 {
+			utils.Error(w, "a percent discount value is in basis points, between 1 and 10000", nil)
+			return
+		}
+	case models.DiscountFixed:
+		if discount.Value <= 0 {
+			utils.Error(w, "a fixed discount value must be a positive number of minor units", nil)
+			return
+		}
+	default:
+		utils.Error(w, "kind must be percent or fixed", nil)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	student, err := repo.FindStudentByID(r.Context(), id, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if student == nil {
+		utils.Error(w, "Student not found", nil)
+		return
+	}
+
+	discount.StudentId = id
+
+	res, err := repo.AddDiscount(db.DB, &discount)
+	if err != nil {
+		utils.Error(w, "Failed to add discount", err)
+		return
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		utils.Error(w, "Failed to retrieve ID", err)
+		return
+	}
+
+	discount.ID = int(lastId)
+
+	utils.Success(w, "Discount added successfully", discount)
+}
+
+func DeleteDiscountHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid discount ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	err = repo.DeleteDiscount(db.DB, id)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Discount not found", err)
+		return
+	} else if err != nil {
+		utils.Error(w, "Failed to delete discount", err)
+		return
+	}
+
+	utils.Success(w, "Discount deleted successfully", nil)
+}
+
+func GetInvoicesHandler(w http.ResponseWriter, r *http.Request) {
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	filters := map[string]string{
+		"student_id": r.URL.Query().Get("student_id"),
+		"term_id":    r.URL.Query().Get("term"),
+		"class_id":   r.URL.Query().Get("class_id"),
+		"status":     r.URL.Query().Get("status"),
+	}
+
+	invoices, err := repo.FindInvoices(db.DB, filters)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if invoices == nil {
+		invoices = []models.Invoice{}
+	}
+
+	utils.SuccessWithCount(w, "Invoices fetched successfully", len(invoices), invoices)
+}
+
+func GetInvoiceByIdHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid invoice ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	invoice, err := repo.FindInvoiceByID(id, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if invoice == nil {
+		utils.Error(w, "Invoice not found", nil)
+		return
+	}
+
+	utils.Success(w, "Invoice fetched successfully", invoice)
+}
+
+func GenerateInvoicesHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.GenerateInvoicesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	if req.DueDate != "" {
+		if _, err := time.Parse(dateLayout, req.DueDate); err != nil {
+			utils.Error(w, "due_date must be in YYYY-MM-DD format", err)
+			return
+		}
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	if req.TermId == 0 {
+		req.TermId, err = repo.CurrentTermID(db.DB)
+		if err != nil {
+			utils.Http500(w, err)
+			return
+		}
+	}
+
+	term, err := repo.FindTermByID(req.TermId, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if term == nil {
+		utils.Error(w, "Term not found", nil)
+		return
+	}
+
+	createdBy, _ := utils.UserFromContext(r.Context())
+
+	result, err := repo.GenerateInvoices(db.DB, &req, createdBy)
+	if err != nil {
+		utils.Error(w, "Failed to generate invoices", err)
+		return
+	}
+
+	utils.SuccessWithCount(w, "Invoices generated successfully", len(result.Invoices), result)
+}
+
+func RecordPaymentHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid invoice ID", err)
+		return
+	}
+
+	var payment models.Payment
+	if err := json.NewDecoder(r.Body).Decode(&payment); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	if payment.Amount <= 0 {
+		utils.Error(w, "amount must be a positive number of minor units", nil)
+		return
+	}
+
+	if payment.Method == "" {
+		utils.Error(w, "method is required", nil)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	recordedBy, _ := utils.UserFromContext(r.Context())
+
+	err = repo.RecordPayment(db.DB, id, &payment, recordedBy)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Invoice not found", err)
+		return
+	} else if err != nil {
+		utils.Error(w, "Failed to record payment", err)
+		return
+	}
+
+	invoice, err := repo.FindInvoiceByID(id, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	utils.Success(w, "Payment recorded successfully", invoice)
+}
+
+func VoidInvoiceHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid invoice ID", err)
+		return
+	}
+
+	var req models.VoidInvoiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	voidedBy, _ := utils.UserFromContext(r.Context())
+
+	err = repo.VoidInvoice(db.DB, id, req.Reason, voidedBy)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Invoice not found", err)
+		return
+	} else if err != nil {
+		utils.Error(w, "Failed to void invoice", err)
+		return
+	}
+
+	utils.Success(w, "Invoice voided successfully", nil)
+}
+
+func GetLedgerHandler(w http.ResponseWriter, r *http.Request) {
+	var studentId, invoiceId int
+	var err error
+
+	if v := r.URL.Query().Get("student_id"); v != "" {
+		if studentId, err = strconv.Atoi(v); err != nil {
+			utils.Error(w, "Invalid student ID", err)
+			return
+		}
+	}
+
+	if v := r.URL.Query().Get("invoice_id"); v != "" {
+		if invoiceId, err = strconv.Atoi(v); err != nil {
+			utils.Error(w, "Invalid invoice ID", err)
+			return
+		}
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	entries, err := repo.FindLedger(db.DB, studentId, invoiceId)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if entries == nil {
+		entries = []models.LedgerEntry{}
+	}
+
+	utils.SuccessWithCount(w, "Ledger fetched successfully", len(entries), entries)
+}
+
+// GetBalancesHandler reports charges, discounts, payments and outstanding
+// balances grouped by ?group=student|class|term.
+func GetBalancesHandler(w http.ResponseWriter, r *http.Request) {
+	group := r.URL.Query().Get("group")
+	if group == "" {
+		group = "student"
+	}
+
+	if !repo.IsValidBalanceGroup(group) {
+		utils.Error(w, "group must be student, class or term", nil)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	filters := map[string]string{
+		"term_id":    r.URL.Query().Get("term"),
+		"class_id":   r.URL.Query().Get("class_id"),
+		"student_id": r.URL.Query().Get("student_id"),
+	}
+
+	balances, err := repo.FindBalances(db.DB, group, filters)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if balances == nil {
+		balances = []models.BalanceRow{}
+	}
+
+	utils.SuccessWithCount(w, "Balances fetched successfully", len(balances), balances)
+}
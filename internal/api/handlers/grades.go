@@ -0,0 +1,414 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"school-api/internal/models"
+	"school-api/internal/repositeries/db"
+	"school-api/internal/repositeries/repo"
+	"school-api/pkg/utils"
+	"strconv"
+	"time"
+)
+
+func GetAssessmentsHandler(w http.ResponseWriter, r *http.Request) {
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	termId, err := getTermParam(r, db.DB)
+	if err != nil {
+		utils.Error(w, "Invalid term", err)
+		return
+	}
+
+	filters := map[string]string{
+		"class_id": r.URL.Query().Get("class_id"),
+		"subject":  r.URL.Query().Get("subject"),
+		"type":     r.URL.Query().Get("type"),
+	}
+	if termId != 0 {
+		filters["term_id"] = strconv.Itoa(termId)
+	}
+
+	sort := utils.BuildSort(r, map[string]bool{
+		"title":    true,
+		"subject":  true,
+		"due_date": true,
+		"weight":   true,
+	})
+
+	page, limit := getPaginationParams(r)
+
+	assessments, meta, err := repo.FindAssessment(r.Context(), db.DB, filters, sort, limit, page)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if assessments == nil {
+		assessments = []models.Assessment{}
+	}
+
+	utils.SuccessWithMeta(w, "Assessments fetched successfully", assessments, meta)
+}
+
+func GetAssessmentByIdHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid assessment ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	assessment, err := repo.FindAssessmentByID(id, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if assessment == nil {
+		utils.Error(w, "Assessment not found", nil)
+		return
+	}
+
+	if !classVisible(w, r, db.DB, assessment.ClassId, "Assessment not found") {
+		return
+	}
+
+	utils.Success(w, "Assessment fetched successfully", assessment)
+}
+
+func AddAssessmentHandler(w http.ResponseWriter, r *http.Request) {
+	var assessment models.Assessment
+	if err := json.NewDecoder(r.Body).Decode(&assessment); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	if assessment.ClassId == 0 || assessment.Subject == "" || assessment.Title == "" {
+		utils.Error(w, "class_id, subject and title are required", nil)
+		return
+	}
+
+	if !repo.IsValidAssessmentType(assessment.Type) {
+		utils.Error(w, "type must be one of quiz, test, exam, homework, project, other", nil)
+		return
+	}
+
+	if assessment.MaxScore <= 0 {
+		utils.Error(w, "max_score must be greater than zero", nil)
+		return
+	}
+
+	if assessment.Weight < 0 {
+		utils.Error(w, "weight cannot be negative", nil)
+		return
+	} else if assessment.Weight == 0 {
+		assessment.Weight = 1
+	}
+
+	if assessment.DueDate != "" {
+		if _, err := time.Parse(dateLayout, assessment.DueDate); err != nil {
+			utils.Error(w, "due_date must be in YYYY-MM-DD format", err)
+			return
+		}
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	class, err := repo.FindClassByID(assessment.ClassId, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if class == nil {
+		utils.Error(w, "Class not found", nil)
+		return
+	}
+
+	allowed, err := canManageClass(r, db.DB, assessment.ClassId)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if !allowed {
+		utils.Error(w, "You are not assigned to this class", nil)
+		return
+	}
+
+	if assessment.TermId == 0 {
+		assessment.TermId, err = repo.CurrentTermID(db.DB)
+		if err != nil {
+			utils.Http500(w, err)
+			return
+		}
+	}
+
+	if assessment.TermId == 0 {
+		utils.Error(w, "term_id is required when no term is current", nil)
+		return
+	}
+
+	term, err := repo.FindTermByID(assessment.TermId, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if term == nil {
+		utils.Error(w, "Term not found", nil)
+		return
+	}
+
+	res, err := repo.AddAssessment(db.DB, &assessment)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		utils.Error(w, "Failed to retrieve ID", err)
+		return
+	}
+
+	assessment.ID = int(lastId)
+
+	utils.Success(w, "Assessment added successfully", assessment)
+}
+
+func UpdateAssessmentHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid assessment ID", err)
+		return
+	}
+
+	var update models.Assessment
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	if update.Type != "" && !repo.IsValidAssessmentType(update.Type) {
+		utils.Error(w, "type must be one of quiz, test, exam, homework, project, other", nil)
+		return
+	}
+
+	if update.MaxScore < 0 || update.Weight < 0 {
+		utils.Error(w, "max_score and weight cannot be negative", nil)
+		return
+	}
+
+	if update.DueDate != "" {
+		if _, err := time.Parse(dateLayout, update.DueDate); err != nil {
+			utils.Error(w, "due_date must be in YYYY-MM-DD format", err)
+			return
+		}
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	var existing models.Assessment
+
+	_, err = repo.UpdateAssessment(db.DB, &existing, &update, id)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Assessment not found", err)
+		return
+	} else if err != nil {
+		utils.Error(w, "Failed to update assessment", err)
+		return
+	}
+
+	utils.Success(w, "Assessment updated successfully", update)
+}
+
+func DeleteAssessmentHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid assessment ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	err = repo.DeleteAssessment(db.DB, id)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Assessment not found", err)
+		return
+	} else if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	utils.Success(w, "Assessment deleted successfully", nil)
+}
+
+func SaveScoresHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid assessment ID", err)
+		return
+	}
+
+	var req models.BulkScoreRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	if len(req.Scores) == 0 {
+		utils.Error(w, "At least one score is required", nil)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	assessment, err := repo.FindAssessmentByID(id, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if assessment == nil {
+		utils.Error(w, "Assessment not found", nil)
+		return
+	}
+
+	allowed, err := canManageClass(r, db.DB, assessment.ClassId)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if !allowed {
+		utils.Error(w, "You are not assigned to this class", nil)
+		return
+	}
+
+	execId, _ := utils.UserFromContext(r.Context())
+
+	err = repo.SaveScores(db.DB, assessment, req.Scores, execId)
+	if err != nil {
+		utils.Error(w, "Failed to save scores", err)
+		return
+	}
+
+	scores, err := repo.FindScores(db.DB, id)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	utils.SuccessWithMeta(w, "Scores saved successfully", scores, assessment)
+}
+
+func GetScoresHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid assessment ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	assessment, err := repo.FindAssessmentByID(id, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if assessment == nil {
+		utils.Error(w, "Assessment not found", nil)
+		return
+	}
+
+	if !classVisible(w, r, db.DB, assessment.ClassId, "Assessment not found") {
+		return
+	}
+
+	scores, err := repo.FindScores(db.DB, id)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if scores == nil {
+		scores = []models.Score{}
+	}
+
+	utils.SuccessWithMeta(w, "Scores fetched successfully", scores, assessment)
+}
+
+func GetStudentGradesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid student ID", err)
+		return
+	}
+
+	subject := r.URL.Query().Get("subject")
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	if !studentVisible(w, r, db.DB, id) {
+		return
+	}
+
+	termId, err := getTermParam(r, db.DB)
+	if err != nil {
+		utils.Error(w, "Invalid term", err)
+		return
+	}
+
+	averages, err := repo.FindSubjectAverages(db.DB, id, termId, subject)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if averages == nil {
+		averages = []models.SubjectAverage{}
+	}
+
+	utils.SuccessWithMeta(w, "Grades fetched successfully", averages, map[string]any{
+		"student_id": id,
+		"term_id":    termId,
+		"subject":    subject,
+	})
+}
@@ -0,0 +1,468 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"school-api/internal/models"
+	"school-api/internal/repositeries/db"
+	"school-api/internal/repositeries/repo"
+	"school-api/pkg/mailer"
+	"school-api/pkg/utils"
+	"strconv"
+	"strings"
+)
+
+func GetGuardianByIdHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid guardian ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	guardian, err := repo.FindGuardianByID(id, db.DB)
+
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if guardian == nil {
+		utils.Error(w, "Guardian not found", nil)
+		return
+	}
+
+	utils.Success(w, "Guardian fetched successfully", guardian)
+}
+
+func GetGuardiansHandler(w http.ResponseWriter, r *http.Request) {
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	filters := map[string]string{
+		"first_name": r.URL.Query().Get("first_name"),
+		"last_name":  r.URL.Query().Get("last_name"),
+		"email":      r.URL.Query().Get("email"),
+		"phone":      r.URL.Query().Get("phone"),
+	}
+
+	search := r.URL.Query().Get("search")
+	sort := utils.BuildSort(r, map[string]bool{
+		"first_name": true,
+		"last_name":  true,
+		"email":      true,
+	})
+
+	guardians, err := repo.FindGuardian(db.DB, search, filters, sort)
+
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if guardians == nil {
+		guardians = []models.Guardian{}
+	}
+
+	utils.SuccessWithCount(w, "Guardians fetched successfully", len(guardians), guardians)
+}
+
+func AddGuardianHandler(w http.ResponseWriter, r *http.Request) {
+	var guardian models.Guardian
+	if err := json.NewDecoder(r.Body).Decode(&guardian); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	if guardian.FirstName == "" || guardian.LastName == "" {
+		utils.Error(w, "first_name and last_name are required", nil)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	res, err := repo.AddGuardian(db.DB, &guardian)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		utils.Error(w, "Failed to retrieve ID", err)
+		return
+	}
+
+	guardian.ID = int(lastId)
+
+	utils.Success(w, "Guardian added successfully", guardian)
+}
+
+func UpdateGuardianHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid guardian ID", err)
+		return
+	}
+
+	var update models.Guardian
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	var existing models.Guardian
+
+	_, err = repo.UpdateGuardian(db.DB, &existing, &update, id)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Guardian not found", err)
+		return
+	} else if err != nil {
+		utils.Error(w, "Failed to update guardian", err)
+		return
+	}
+
+	utils.Success(w, "Guardian updated successfully", update)
+}
+
+func DeleteGuardianHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid guardian ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	err = repo.DeleteGuardian(db.DB, id)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Guardian not found", err)
+		return
+	} else if err != nil {
+		utils.Error(w, "Failed to delete guardian", err)
+		return
+	}
+
+	utils.Success(w, "Guardian deleted successfully", nil)
+}
+
+func GetGuardianStudentsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid guardian ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	links, err := repo.FindGuardianStudents(db.DB, id)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if links == nil {
+		links = []models.StudentGuardian{}
+	}
+
+	utils.SuccessWithCount(w, "Students fetched successfully", len(links), links)
+}
+
+func GetStudentGuardiansHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid student ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	student, err := repo.FindStudentByID(r.Context(), id, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if student == nil {
+		utils.Error(w, "Student not found", nil)
+		return
+	}
+
+	links, err := repo.FindStudentGuardians(db.DB, id)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if links == nil {
+		links = []models.StudentGuardian{}
+	}
+
+	utils.SuccessWithCount(w, "Guardians fetched successfully", len(links), links)
+}
+
+// LinkStudentGuardianHandler links an existing guardian by guardian_id, or
+// creates the guardian given in the body and links it.
+func LinkStudentGuardianHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid student ID", err)
+		return
+	}
+
+	var link models.StudentGuardian
+	if err := json.NewDecoder(r.Body).Decode(&link); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	if link.Relationship == "" {
+		utils.Error(w, "relationship is required", nil)
+		return
+	}
+
+	if link.GuardianId == 0 && (link.Guardian == nil || link.Guardian.FirstName == "" || link.Guardian.LastName == "") {
+		utils.Error(w, "guardian_id or a guardian with first_name and last_name is required", nil)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	student, err := repo.FindStudentByID(r.Context(), id, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if student == nil {
+		utils.Error(w, "Student not found", nil)
+		return
+	}
+
+	if link.GuardianId != 0 {
+		link.Guardian, err = repo.FindGuardianByID(link.GuardianId, db.DB)
+		if err != nil {
+			utils.Http500(w, err)
+			return
+		} else if link.Guardian == nil {
+			utils.Error(w, "Guardian not found", nil)
+			return
+		}
+	}
+
+	link.StudentId = id
+
+	if err := repo.LinkGuardian(db.DB, &link); err != nil {
+		utils.Error(w, "Failed to link guardian", err)
+		return
+	}
+
+	utils.Success(w, "Guardian linked successfully", link)
+}
+
+func UpdateStudentGuardianHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid student ID", err)
+		return
+	}
+
+	guardianId, err := strconv.Atoi(r.PathValue("guardianId"))
+	if err != nil {
+		utils.Error(w, "Invalid guardian ID", err)
+		return
+	}
+
+	var link models.StudentGuardian
+	if err := json.NewDecoder(r.Body).Decode(&link); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	link.StudentId = id
+	link.GuardianId = guardianId
+	link.Guardian = nil
+
+	err = repo.UpdateGuardianLink(db.DB, &link)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Guardian is not linked to this student", err)
+		return
+	} else if err != nil {
+		utils.Error(w, "Failed to update guardian link", err)
+		return
+	}
+
+	utils.Success(w, "Guardian link updated successfully", link)
+}
+
+func UnlinkStudentGuardianHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid student ID", err)
+		return
+	}
+
+	guardianId, err := strconv.Atoi(r.PathValue("guardianId"))
+	if err != nil {
+		utils.Error(w, "Invalid guardian ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	err = repo.UnlinkGuardian(db.DB, id, guardianId)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Guardian is not linked to this student", err)
+		return
+	} else if err != nil {
+		utils.Error(w, "Failed to unlink guardian", err)
+		return
+	}
+
+	utils.Success(w, "Guardian unlinked successfully", nil)
+}
+
+// NotifyStudentGuardiansHandler emails a notice about a student to the
+// student's guardians. The notices are queued together, so either all of
+// them go out or none do.
+func NotifyStudentGuardiansHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid student ID", err)
+		return
+	}
+
+	var notice models.GuardianNotice
+	if err := json.NewDecoder(r.Body).Decode(&notice); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	notice.Subject = strings.TrimSpace(notice.Subject)
+	notice.Message = strings.TrimSpace(notice.Message)
+	if notice.Subject == "" || notice.Message == "" {
+		utils.Error(w, "subject and message are required", nil)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	student, err := repo.FindStudentByID(r.Context(), id, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if student == nil {
+		utils.Error(w, "Student not found", nil)
+		return
+	}
+
+	links, err := repo.FindStudentGuardians(db.DB, id)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	result := models.GuardianNoticeResult{Queued: []int{}, Skipped: []int{}}
+
+	for _, link := range links {
+		if notice.PrimaryOnly && !link.IsPrimaryContact {
+			continue
+		}
+
+		g := link.Guardian
+		if g.Email == "" {
+			result.Skipped = append(result.Skipped, g.ID)
+			continue
+		}
+
+		err := repo.QueueMail(tx, g.Email, mailer.GuardianNotice, map[string]any{
+			"Name":    displayName(g.FirstName, g.LastName, "guardian"),
+			"Student": displayName(student.FirstName, student.LastName, ""),
+			"Subject": notice.Subject,
+			"Message": notice.Message,
+		})
+		if err != nil {
+			tx.Rollback()
+			utils.Http500(w, err)
+			return
+		}
+
+		result.Queued = append(result.Queued, g.ID)
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if len(result.Queued) == 0 {
+		utils.Error(w, "No guardian with an email address to notify", nil)
+		return
+	}
+
+	utils.Success(w, "Notice queued for "+strconv.Itoa(len(result.Queued))+" guardian(s)", result)
+}
@@ -0,0 +1,445 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"school-api/internal/models"
+	"school-api/internal/repositeries/db"
+	"school-api/internal/repositeries/repo"
+	"school-api/pkg/utils"
+	"strconv"
+	"time"
+)
+
+func GetHomeworkListHandler(w http.ResponseWriter, r *http.Request) {
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	termId, err := getTermParam(r, db.DB)
+	if err != nil {
+		utils.Error(w, "Invalid term", err)
+		return
+	}
+
+	filters := map[string]string{
+		"class_id": r.URL.Query().Get("class_id"),
+		"subject":  r.URL.Query().Get("subject"),
+	}
+
+	homework, err := repo.FindHomework(r.Context(), db.DB, filters, termId)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if homework == nil {
+		homework = []models.Homework{}
+	}
+
+	utils.SuccessWithCount(w, "Homework fetched successfully", len(homework), homework)
+}
+
+func GetHomeworkByIdHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid homework ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	homework, err := repo.FindHomeworkByID(id, db.DB)
+
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if homework == nil {
+		utils.Error(w, "Homework not found", nil)
+		return
+	}
+
+	if !classVisible(w, r, db.DB, homework.ClassId, "Homework not found") {
+		return
+	}
+
+	utils.Success(w, "Homework fetched successfully", homework)
+}
+
+func AddHomeworkHandler(w http.ResponseWriter, r *http.Request) {
+	var homework models.Homework
+	if err := json.NewDecoder(r.Body).Decode(&homework); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	if homework.ClassId == 0 || homework.Subject == "" || homework.Title == "" || homework.DueDate == "" {
+		utils.Error(w, "class_id, subject, title and due_date are required", nil)
+		return
+	}
+
+	if homework.MaxScore <= 0 {
+		utils.Error(w, "max_score must be greater than zero", nil)
+		return
+	}
+
+	if _, err := time.Parse(dateLayout, homework.DueDate); err != nil {
+		utils.Error(w, "due_date must be in YYYY-MM-DD format", err)
+		return
+	}
+
+	for _, a := range homework.Attachments {
+		if a.Name == "" || a.URL == "" {
+			utils.Error(w, "each attachment needs a name and url", nil)
+			return
+		}
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	class, err := repo.FindClassByID(homework.ClassId, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if class == nil {
+		utils.Error(w, "Class not found", nil)
+		return
+	}
+
+	allowed, err := canManageClass(r, db.DB, homework.ClassId)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if !allowed {
+		utils.Error(w, "You are not assigned to this class", nil)
+		return
+	}
+
+	if homework.TermId == 0 {
+		homework.TermId, err = repo.CurrentTermID(db.DB)
+		if err != nil {
+			utils.Http500(w, err)
+			return
+		}
+	}
+
+	homework.CreatedBy, _ = utils.UserFromContext(r.Context())
+
+	res, err := repo.AddHomework(db.DB, &homework)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		utils.Error(w, "Failed to retrieve ID", err)
+		return
+	}
+
+	homework.ID = int(lastId)
+
+	utils.Success(w, "Homework added successfully", homework)
+}
+
+func UpdateHomeworkHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid homework ID", err)
+		return
+	}
+
+	var update models.Homework
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	if update.MaxScore < 0 {
+		utils.Error(w, "max_score must be greater than zero", nil)
+		return
+	}
+
+	if update.DueDate != "" {
+		if _, err := time.Parse(dateLayout, update.DueDate); err != nil {
+			utils.Error(w, "due_date must be in YYYY-MM-DD format", err)
+			return
+		}
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	homework, ok := loadManagedHomework(w, r, db.DB, id)
+	if !ok {
+		return
+	}
+
+	_, err = repo.UpdateHomework(db.DB, homework, &update, id)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Homework not found", err)
+		return
+	} else if err != nil {
+		utils.Error(w, "Failed to update homework", err)
+		return
+	}
+
+	utils.Success(w, "Homework updated successfully", update)
+}
+
+func DeleteHomeworkHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid homework ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	if _, ok := loadManagedHomework(w, r, db.DB, id); !ok {
+		return
+	}
+
+	err = repo.DeleteHomework(db.DB, id)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Homework not found", err)
+		return
+	} else if err != nil {
+		utils.Error(w, "Failed to delete homework", err)
+		return
+	}
+
+	utils.Success(w, "Homework deleted successfully", nil)
+}
+
+func GetSubmissionsHandler(w http.ResponseWriter, r *http.Request) {
+	listSubmissions(w, r, r.URL.Query().Get("status"))
+}
+
+// GetOutstandingSubmissionsHandler lists the students who have not handed
+// in the homework yet.
+func GetOutstandingSubmissionsHandler(w http.ResponseWriter, r *http.Request) {
+	listSubmissions(w, r, models.SubmissionNotSubmitted)
+}
+
+func listSubmissions(w http.ResponseWriter, r *http.Request, status string) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid homework ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	homework, err := repo.FindHomeworkByID(id, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if homework == nil {
+		utils.Error(w, "Homework not found", nil)
+		return
+	}
+
+	if !classVisible(w, r, db.DB, homework.ClassId, "Homework not found") {
+		return
+	}
+
+	submissions, err := repo.FindSubmissions(db.DB, homework, status)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if submissions == nil {
+		submissions = []models.Submission{}
+	}
+
+	utils.SuccessWithCount(w, "Submissions fetched successfully", len(submissions), submissions)
+}
+
+func SubmitHomeworkHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid homework ID", err)
+		return
+	}
+
+	var submission models.Submission
+	if err := json.NewDecoder(r.Body).Decode(&submission); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	if submission.StudentId == 0 {
+		utils.Error(w, "student_id is required", nil)
+		return
+	}
+
+	if submission.Content == "" && submission.FileURL == "" {
+		utils.Error(w, "content or file_url is required", nil)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	homework, err := repo.FindHomeworkByID(id, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if homework == nil {
+		utils.Error(w, "Homework not found", nil)
+		return
+	}
+
+	if err := repo.SubmitHomework(db.DB, homework, &submission); err != nil {
+		utils.Error(w, "Failed to submit homework", err)
+		return
+	}
+
+	utils.Success(w, "Homework submitted successfully", submission)
+}
+
+func GradeSubmissionHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid homework ID", err)
+		return
+	}
+
+	studentId, err := strconv.Atoi(r.PathValue("studentId"))
+	if err != nil {
+		utils.Error(w, "Invalid student ID", err)
+		return
+	}
+
+	var req models.GradeSubmissionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	homework, ok := loadManagedHomework(w, r, db.DB, id)
+	if !ok {
+		return
+	}
+
+	gradedBy, _ := utils.UserFromContext(r.Context())
+
+	err = repo.GradeSubmission(db.DB, homework, studentId, &req, gradedBy)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Student has not submitted this homework", err)
+		return
+	} else if err != nil {
+		utils.Error(w, "Failed to grade submission", err)
+		return
+	}
+
+	utils.Success(w, "Submission graded successfully", nil)
+}
+
+// PublishHomeworkGradesHandler copies graded submissions into the gradebook.
+func PublishHomeworkGradesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid homework ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	homework, ok := loadManagedHomework(w, r, db.DB, id)
+	if !ok {
+		return
+	}
+
+	publishedBy, _ := utils.UserFromContext(r.Context())
+
+	result, err := repo.PublishHomeworkGrades(db.DB, homework, publishedBy)
+	if err != nil {
+		utils.Error(w, "Failed to publish grades", err)
+		return
+	}
+
+	message := "Grades published successfully"
+	if len(result.Skipped) > 0 {
+		message += ", skipping " + strconv.Itoa(len(result.Skipped)) + " student(s) no longer in the class"
+	}
+
+	utils.SuccessWithCount(w, message, result.Published, result)
+}
+
+// loadManagedHomework fetches the homework and checks the caller may manage
+// its class, writing the error response when not.
+func loadManagedHomework(w http.ResponseWriter, r *http.Request, db *sql.DB, id int) (*models.Homework, bool) {
+	homework, err := repo.FindHomeworkByID(id, db)
+	if err != nil {
+		utils.Http500(w, err)
+		return nil, false
+	} else if homework == nil {
+		utils.Error(w, "Homework not found", nil)
+		return nil, false
+	}
+
+	allowed, err := canManageClass(r, db, homework.ClassId)
+	if err != nil {
+		utils.Http500(w, err)
+		return nil, false
+	}
+
+	if !allowed {
+		utils.Error(w, "You are not assigned to this class", nil)
+		return nil, false
+	}
+
+	return homework, true
+}
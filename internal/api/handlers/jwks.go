@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"net/http"
+	"school-api/pkg/keyring"
+	"school-api/pkg/utils"
+)
+
+// JWKSHandler publishes the public keys tokens are signed with, in the
+// standard JWK Set form other services expect rather than the usual
+// response envelope.
+func JWKSHandler(w http.ResponseWriter, r *http.Request) {
+	kr, err := keyring.Default()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	utils.WriteJSON(w, http.StatusOK, kr.JWKS())
+}
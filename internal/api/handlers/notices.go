@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"school-api/internal/models"
+	"school-api/internal/repositeries/repo"
+	"school-api/internal/repositeries/sqlconnect"
+	"school-api/pkg/mailer"
+	"strings"
+	"time"
+)
+
+// Account notices go to the account's email through the mail outbox, so
+// they are delivered and retried in the background.
+
+// displayName is how a notice greets someone.
+func displayName(first, last, fallback string) string {
+	if name := strings.TrimSpace(first + " " + last); name != "" {
+		return name
+	}
+	return fallback
+}
+
+// readableDuration writes d as whole hours or minutes.
+func readableDuration(d time.Duration) string {
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s", unit)
+		}
+		return fmt.Sprintf("%d %ss", n, unit)
+	}
+
+	if d >= time.Hour && d%time.Hour == 0 {
+		return plural(int(d/time.Hour), "hour")
+	}
+	return plural(int(d.Round(time.Minute)/time.Minute), "minute")
+}
+
+var errNoResetURL = errors.New("PASSWORD_RESET_URL with a {token} placeholder is required to email password links")
+
+// resetURL is PASSWORD_RESET_URL, the page where a reset code is used, with
+// {token} standing for the code. Emailed links are never built from the
+// request, whose Host header the caller controls.
+func resetURL() (string, error) {
+	u := os.Getenv("PASSWORD_RESET_URL")
+	if !strings.Contains(u, "{token}") {
+		return "", errNoResetURL
+	}
+	return u, nil
+}
+
+// queuePasswordLink gives the exec a new reset code, good for validity,
+// and queues template with the link to it. Both happen or neither does.
+func queuePasswordLink(db *sql.DB, exec *models.Exec, template string, validity time.Duration) error {
+	link, err := resetURL()
+	if err != nil {
+		return err
+	}
+
+	tokenByte := make([]byte, 32)
+	if _, err := rand.Read(tokenByte); err != nil {
+		return err
+	}
+
+	token := hex.EncodeToString(tokenByte)
+	hashedToken := sha256.Sum256(tokenByte)
+	expiry := time.Now().Add(validity).Format(time.RFC3339)
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE execs SET password_reset_token =?, password_token_expires=? WHERE id=?", hex.EncodeToString(hashedToken[:]), expiry, exec.ID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = repo.QueueMail(tx, exec.Email, template, map[string]any{
+		"Name":     displayName(exec.FirstName, exec.LastName, exec.Username),
+		"Username": exec.Username,
+		"Role":     exec.Role,
+		"Link":     strings.ReplaceAll(link, "{token}", token),
+		"Minutes":  int(validity.Minutes()),
+		"Hours":    int(validity.Hours()),
+	})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// sendPasswordReset queues a reset link for the exec with email, if there
+// is one. It runs after ForgotPasswordHandler has answered, so failures are
+// only logged.
+func sendPasswordReset(email string, validity time.Duration) {
+	db, err := sqlconnect.ConnectDB()
+	if err != nil {
+		log.Println("password reset:", err)
+		return
+	}
+	defer db.Close()
+
+	var exec models.Exec
+	err = db.QueryRow("SELECT id, first_name, last_name, username, email FROM execs WHERE email=?", email).
+		Scan(&exec.ID, &exec.FirstName, &exec.LastName, &exec.Username, &exec.Email)
+
+	if err == sql.ErrNoRows {
+		return
+	} else if err != nil {
+		log.Println("password reset:", err)
+		return
+	}
+
+	if err := queuePasswordLink(db, &exec, mailer.PasswordReset, validity); err != nil {
+		log.Printf("password reset for %s: %v", exec.Username, err)
+	}
+}
+
+// queueLockoutNotice tells the exec their account was locked. The sign-in
+// answer does not depend on it, so failures are only logged.
+func queueLockoutNotice(db *sql.DB, exec *models.Exec, lockout time.Duration) {
+	if exec.Email == "" {
+		return
+	}
+
+	err := repo.QueueMail(db, exec.Email, mailer.Lockout, map[string]any{
+		"Name":     displayName(exec.FirstName, exec.LastName, exec.Username),
+		"Username": exec.Username,
+		"Lockout":  readableDuration(lockout),
+	})
+	if err != nil {
+		log.Printf("lockout notice for %s: %v", exec.Username, err)
+	}
+}
@@ -0,0 +1,638 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"school-api/internal/models"
+	"school-api/internal/repositeries/db"
+	"school-api/internal/repositeries/repo"
+	"school-api/pkg/keyring"
+	"school-api/pkg/rbac"
+	"school-api/pkg/utils"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	oauthCodeTTL        = 2 * time.Minute
+	oauthAccessTokenTTL = time.Hour
+	oauthConsentTTL     = 10 * time.Minute
+
+	oauthAccessTokenType = "oauth_access"
+	oauthConsentType     = "oauth_consent"
+)
+
+var oidcScopes = []string{"openid", "profile", "email"}
+
+var errNoOIDC = errors.New("OIDC_ISSUER and an RS256 or EdDSA signing key are required for OpenID Connect")
+
+// oidcProvider returns the issuer and the keyring to sign with. Clients
+// verify ID tokens against the published JWKS, so a shared HS256 secret
+// cannot sign them, and the issuer is never taken from the request.
+func oidcProvider() (string, *keyring.Keyring, *keyring.Key, error) {
+	issuer := strings.TrimSuffix(os.Getenv("OIDC_ISSUER"), "/")
+	if issuer == "" {
+		return "", nil, nil, errNoOIDC
+	}
+
+	kr, err := keyring.Default()
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	signer, err := kr.SigningKey()
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	if signer.Algorithm == keyring.HS256 {
+		return "", nil, nil, errNoOIDC
+	}
+
+	return issuer, kr, signer, nil
+}
+
+// authorizeRequest holds the parameters of an authorization request. They
+// are carried through the login page as hidden fields.
+type authorizeRequest struct {
+	ResponseType        string
+	ClientId            string
+	RedirectURI         string
+	Scope               string
+	State               string
+	Nonce               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+func parseAuthorizeRequest(r *http.Request) authorizeRequest {
+	return authorizeRequest{
+		ResponseType:        r.FormValue("response_type"),
+		ClientId:            r.FormValue("client_id"),
+		RedirectURI:         r.FormValue("redirect_uri"),
+		Scope:               r.FormValue("scope"),
+		State:               r.FormValue("state"),
+		Nonce:               r.FormValue("nonce"),
+		CodeChallenge:       r.FormValue("code_challenge"),
+		CodeChallengeMethod: r.FormValue("code_challenge_method"),
+	}
+}
+
+// checkAuthorizeRequest validates the request and returns its client and
+// scopes. Until the client and redirect URI are known good, errors are
+// shown to the user; after that they go back to the client. When ok is
+// false the response has been written.
+func checkAuthorizeRequest(w http.ResponseWriter, r *http.Request, db *sql.DB, req authorizeRequest) (*models.OAuthClient, []string, bool) {
+	if _, _, _, err := oidcProvider(); err == errNoOIDC {
+		renderOAuthPage(w, http.StatusNotFound, "error", oauthPage{Error: "Signing in to other applications is not set up"})
+		return nil, nil, false
+	} else if err != nil {
+		renderOAuthPage(w, http.StatusInternalServerError, "error", oauthPage{Error: "Something went wrong, please try again"})
+		return nil, nil, false
+	}
+
+	client, err := repo.FindOAuthClientByClientID(db, req.ClientId)
+	if err != nil {
+		renderOAuthPage(w, http.StatusInternalServerError, "error", oauthPage{Error: "Something went wrong, please try again"})
+		return nil, nil, false
+	}
+
+	if client == nil || !slices.Contains(client.RedirectURIs, req.RedirectURI) {
+		renderOAuthPage(w, http.StatusBadRequest, "error", oauthPage{Error: "The application that sent you here is not registered correctly"})
+		return nil, nil, false
+	}
+
+	if req.ResponseType != "code" {
+		redirectWithError(w, r, req, "unsupported_response_type", "only the code response type is supported")
+		return nil, nil, false
+	}
+
+	if req.CodeChallenge == "" || req.CodeChallengeMethod != "S256" {
+		redirectWithError(w, r, req, "invalid_request", "PKCE with code_challenge_method S256 is required")
+		return nil, nil, false
+	}
+
+	scopes := strings.Fields(req.Scope)
+	if len(scopes) == 0 {
+		scopes = []string{"openid"}
+	}
+
+	for _, s := range scopes {
+		if !slices.Contains(client.Scopes, s) {
+			redirectWithError(w, r, req, "invalid_scope", "scope "+s+" is not allowed for this client")
+			return nil, nil, false
+		}
+	}
+
+	return client, scopes, true
+}
+
+func redirectTo(w http.ResponseWriter, r *http.Request, redirectURI string, params map[string]string) {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		renderOAuthPage(w, http.StatusBadRequest, "error", oauthPage{Error: "The application's redirect address is invalid"})
+		return
+	}
+
+	q := u.Query()
+	for k, v := range params {
+		if v != "" {
+			q.Set(k, v)
+		}
+	}
+	u.RawQuery = q.Encode()
+
+	http.Redirect(w, r, u.String(), http.StatusSeeOther)
+}
+
+func redirectWithError(w http.ResponseWriter, r *http.Request, req authorizeRequest, code, description string) {
+	redirectTo(w, r, req.RedirectURI, map[string]string{"error": code, "error_description": description, "state": req.State})
+}
+
+// AuthorizeHandler starts the authorization code flow by showing the login
+// page.
+func AuthorizeHandler(w http.ResponseWriter, r *http.Request) {
+	db, err := db.New()
+	if err != nil {
+		renderOAuthPage(w, http.StatusInternalServerError, "error", oauthPage{Error: "Something went wrong, please try again"})
+		return
+	}
+	defer db.Close()
+
+	req := parseAuthorizeRequest(r)
+
+	client, scopes, ok := checkAuthorizeRequest(w, r, db.DB, req)
+	if !ok {
+		return
+	}
+
+	renderOAuthPage(w, http.StatusOK, "login", oauthPage{Client: client, Scopes: scopes, Request: req})
+}
+
+// AuthorizeLoginHandler checks the credentials from the login page, with
+// the same lockout as LoginHandler. Accounts whose role requires two-factor
+// authentication must have it turned on. If the exec has not yet agreed to
+// share the requested scopes with the client, the consent page comes next;
+// otherwise the client gets its code straight away.
+func AuthorizeLoginHandler(w http.ResponseWriter, r *http.Request) {
+	db, err := db.New()
+	if err != nil {
+		renderOAuthPage(w, http.StatusInternalServerError, "error", oauthPage{Error: "Something went wrong, please try again"})
+		return
+	}
+	defer db.Close()
+
+	req := parseAuthorizeRequest(r)
+
+	client, scopes, ok := checkAuthorizeRequest(w, r, db.DB, req)
+	if !ok {
+		return
+	}
+
+	if r.FormValue("action") == "deny" {
+		redirectWithError(w, r, req, "access_denied", "the user declined to sign in")
+		return
+	}
+
+	username := strings.TrimSpace(r.FormValue("username"))
+	page := oauthPage{Client: client, Scopes: scopes, Request: req, Username: username}
+
+	keys := throttleKeys(r, throttleLogin, username)
+
+	wait, err := repo.ThrottleWait(db.DB, keys...)
+	if err != nil {
+		page.Error = "Something went wrong, please try again"
+		renderOAuthPage(w, http.StatusInternalServerError, "login", page)
+		return
+	}
+
+	if wait > 0 {
+		page.Error = "Too many failed attempts, try again in " + wait.String()
+		renderOAuthPage(w, http.StatusTooManyRequests, "login", page)
+		return
+	}
+
+	var exec models.Exec
+	var twoFactor bool
+
+	err = db.QueryRow("SELECT id, username, email, role, password, inactive, totp_enabled, service_account FROM execs WHERE username = ?", username).
+		Scan(&exec.ID, &exec.Username, &exec.Email, &exec.Role, &exec.Password, &exec.Inactive, &twoFactor, &exec.ServiceAccount)
+
+	if err != nil && err != sql.ErrNoRows {
+		page.Error = "Something went wrong, please try again"
+		renderOAuthPage(w, http.StatusInternalServerError, "login", page)
+		return
+	}
+
+	// an unknown username fails the same way, and as slowly, as a wrong
+	// password
+	verified := false
+	if err == sql.ErrNoRows {
+		repo.VerifyDummyPassword(r.FormValue("password"))
+	} else {
+		verified, err = repo.VerifyPassword(r.FormValue("password"), exec.Password)
+		if err != nil {
+			page.Error = "Something went wrong, please try again"
+			renderOAuthPage(w, http.StatusInternalServerError, "login", page)
+			return
+		}
+	}
+
+	if verified && twoFactor {
+		verified, err = verifySecondFactor(db.DB, exec.ID, r.FormValue("code"), "")
+		if err != nil {
+			page.Error = "Something went wrong, please try again"
+			renderOAuthPage(w, http.StatusInternalServerError, "login", page)
+			return
+		}
+	}
+
+	if !verified {
+		locked, err := recordFailure(db.DB, keys)
+		if err == nil && locked {
+			queueLockoutNotice(db.DB, &exec, throttlePolicy(throttleLogin, repo.ThrottleUser).Lockout)
+		}
+
+		page.Error = "Invalid username, password or code"
+		renderOAuthPage(w, http.StatusOK, "login", page)
+		return
+	}
+
+	if exec.Inactive || exec.ServiceAccount {
+		page.Error = "This account cannot sign in here"
+		renderOAuthPage(w, http.StatusOK, "login", page)
+		return
+	}
+
+	// JWTMiddleware only lets these accounts set up 2FA; there is nothing
+	// to set up here
+	if !twoFactor && rbac.RequiresTwoFactor(exec.Role) {
+		page.Error = "This account must turn on two-factor authentication before it can sign in here"
+		renderOAuthPage(w, http.StatusForbidden, "login", page)
+		return
+	}
+
+	err = repo.ClearAttempts(db.DB, repo.ThrottleKey{Action: throttleLogin, Kind: repo.ThrottleUser, Subject: exec.Username})
+	if err != nil {
+		page.Error = "Something went wrong, please try again"
+		renderOAuthPage(w, http.StatusInternalServerError, "login", page)
+		return
+	}
+
+	grant := models.AuthorizationCode{
+		ClientId:      client.ClientId,
+		ExecId:        exec.ID,
+		RedirectURI:   req.RedirectURI,
+		Scopes:        scopes,
+		Nonce:         req.Nonce,
+		CodeChallenge: req.CodeChallenge,
+		AuthTime:      time.Now().Unix(),
+	}
+
+	consented, err := repo.HasConsent(db.DB, exec.ID, client.ClientId, scopes)
+	if err != nil {
+		page.Error = "Something went wrong, please try again"
+		renderOAuthPage(w, http.StatusInternalServerError, "login", page)
+		return
+	}
+
+	if consented {
+		issueAuthorizationCode(w, r, db.DB, grant, req.State)
+		return
+	}
+
+	ticket, err := signConsentTicket(grant, req.State)
+	if err != nil {
+		page.Error = "Something went wrong, please try again"
+		renderOAuthPage(w, http.StatusInternalServerError, "login", page)
+		return
+	}
+
+	renderOAuthPage(w, http.StatusOK, "consent", oauthPage{Client: client, Scopes: scopes, Username: exec.Username, Ticket: ticket})
+}
+
+// the consent page carries the signed-in grant as a short-lived signed
+// ticket, so nothing is stored until the exec decides
+func signConsentTicket(grant models.AuthorizationCode, state string) (string, error) {
+	kr, err := keyring.Default()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	return kr.Sign(jwt.MapClaims{
+		"typ":          oauthConsentType,
+		"sub":          strconv.Itoa(grant.ExecId),
+		"client_id":    grant.ClientId,
+		"redirect_uri": grant.RedirectURI,
+		"scope":        strings.Join(grant.Scopes, " "),
+		"nonce":        grant.Nonce,
+		"challenge":    grant.CodeChallenge,
+		"auth_time":    grant.AuthTime,
+		"state":        state,
+		"iat":          jwt.NewNumericDate(now),
+		"exp":          jwt.NewNumericDate(now.Add(oauthConsentTTL)),
+	})
+}
+
+// ConsentHandler records the exec's answer on the consent page.
+func ConsentHandler(w http.ResponseWriter, r *http.Request) {
+	claims, err := utils.ParseToken(r.FormValue("ticket"))
+	if err != nil || claims["typ"] != oauthConsentType {
+		renderOAuthPage(w, http.StatusBadRequest, "error", oauthPage{Error: "This sign-in has expired, please start again from the application"})
+		return
+	}
+
+	execId, _ := strconv.Atoi(fmt.Sprint(claims["sub"]))
+	authTime, _ := claims["auth_time"].(float64)
+	state := fmt.Sprint(claims["state"])
+
+	grant := models.AuthorizationCode{
+		ClientId:      fmt.Sprint(claims["client_id"]),
+		ExecId:        execId,
+		RedirectURI:   fmt.Sprint(claims["redirect_uri"]),
+		Scopes:        strings.Fields(fmt.Sprint(claims["scope"])),
+		Nonce:         fmt.Sprint(claims["nonce"]),
+		CodeChallenge: fmt.Sprint(claims["challenge"]),
+		AuthTime:      int64(authTime),
+	}
+
+	db, err := db.New()
+	if err != nil {
+		renderOAuthPage(w, http.StatusInternalServerError, "error", oauthPage{Error: "Something went wrong, please try again"})
+		return
+	}
+	defer db.Close()
+
+	// the client may have been revoked while the page was open
+	client, err := repo.FindOAuthClientByClientID(db.DB, grant.ClientId)
+	if err != nil || client == nil || !slices.Contains(client.RedirectURIs, grant.RedirectURI) {
+		renderOAuthPage(w, http.StatusBadRequest, "error", oauthPage{Error: "The application that sent you here is not registered correctly"})
+		return
+	}
+
+	if r.FormValue("action") != "allow" {
+		redirectTo(w, r, grant.RedirectURI, map[string]string{"error": "access_denied", "error_description": "the user denied access", "state": state})
+		return
+	}
+
+	if err := repo.SaveConsent(db.DB, grant.ExecId, grant.ClientId, grant.Scopes); err != nil {
+		renderOAuthPage(w, http.StatusInternalServerError, "error", oauthPage{Error: "Something went wrong, please try again"})
+		return
+	}
+
+	issueAuthorizationCode(w, r, db.DB, grant, state)
+}
+
+func issueAuthorizationCode(w http.ResponseWriter, r *http.Request, db *sql.DB, grant models.AuthorizationCode, state string) {
+	code, err := repo.CreateAuthorizationCode(db, grant, oauthCodeTTL)
+	if err != nil {
+		renderOAuthPage(w, http.StatusInternalServerError, "error", oauthPage{Error: "Something went wrong, please try again"})
+		return
+	}
+
+	redirectTo(w, r, grant.RedirectURI, map[string]string{"code": code, "state": state})
+}
+
+// oauthError writes an OAuth2 error response.
+func oauthError(w http.ResponseWriter, status int, code, description string) {
+	utils.WriteJSON(w, status, map[string]string{"error": code, "error_description": description})
+}
+
+// TokenHandler redeems an authorization code for an access token and, with
+// the openid scope, an ID token. Confidential clients authenticate with
+// their secret; every client proves the code is theirs with the PKCE
+// verifier.
+func TokenHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+
+	if err := r.ParseForm(); err != nil {
+		oauthError(w, http.StatusBadRequest, "invalid_request", "the request body could not be read")
+		return
+	}
+
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		oauthError(w, http.StatusBadRequest, "unsupported_grant_type", "only authorization_code is supported")
+		return
+	}
+
+	clientId, secret, basic := r.BasicAuth()
+	if !basic {
+		clientId, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	client, err := repo.FindOAuthClientByClientID(db.DB, clientId)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if client == nil {
+		oauthError(w, http.StatusUnauthorized, "invalid_client", "unknown client")
+		return
+	}
+
+	if client.Confidential {
+		ok, err := repo.VerifyOAuthClientSecret(db.DB, clientId, secret)
+		if err != nil {
+			utils.Http500(w, err)
+			return
+		}
+		if !ok {
+			oauthError(w, http.StatusUnauthorized, "invalid_client", "client authentication failed")
+			return
+		}
+	}
+
+	grant, err := repo.RedeemAuthorizationCode(db.DB, r.PostForm.Get("code"))
+	if err == repo.ErrInvalidGrant {
+		oauthError(w, http.StatusBadRequest, "invalid_grant", err.Error())
+		return
+	} else if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if grant.ClientId != client.ClientId || grant.RedirectURI != r.PostForm.Get("redirect_uri") {
+		oauthError(w, http.StatusBadRequest, "invalid_grant", "the code was not issued to this client or redirect URI")
+		return
+	}
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+	if subtle.ConstantTimeCompare([]byte(challenge), []byte(grant.CodeChallenge)) != 1 {
+		oauthError(w, http.StatusBadRequest, "invalid_grant", "code_verifier does not match the code challenge")
+		return
+	}
+
+	exec, err := repo.FindExecByID(grant.ExecId, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if exec == nil || exec.Inactive {
+		oauthError(w, http.StatusBadRequest, "invalid_grant", "the user can no longer sign in")
+		return
+	}
+
+	issuer, kr, _, err := oidcProvider()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	now := time.Now()
+	sub := strconv.Itoa(exec.ID)
+	scope := strings.Join(grant.Scopes, " ")
+
+	// not usable against the rest of the API: JWTMiddleware refuses typed tokens
+	accessToken, err := kr.Sign(jwt.MapClaims{
+		"typ":       oauthAccessTokenType,
+		"iss":       issuer,
+		"sub":       sub,
+		"aud":       client.ClientId,
+		"client_id": client.ClientId,
+		"scope":     scope,
+		"iat":       jwt.NewNumericDate(now),
+		"exp":       jwt.NewNumericDate(now.Add(oauthAccessTokenTTL)),
+	})
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	res := models.TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(oauthAccessTokenTTL.Seconds()),
+		Scope:       scope,
+	}
+
+	if slices.Contains(grant.Scopes, "openid") {
+		claims := userClaims(exec, grant.Scopes)
+		claims["iss"] = issuer
+		claims["aud"] = client.ClientId
+		claims["auth_time"] = grant.AuthTime
+		claims["iat"] = jwt.NewNumericDate(now)
+		claims["exp"] = jwt.NewNumericDate(now.Add(oauthAccessTokenTTL))
+		if grant.Nonce != "" {
+			claims["nonce"] = grant.Nonce
+		}
+
+		if res.IDToken, err = kr.Sign(claims); err != nil {
+			utils.Http500(w, err)
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, res)
+}
+
+// userClaims are the claims about the exec that scopes release.
+func userClaims(exec *models.Exec, scopes []string) jwt.MapClaims {
+	claims := jwt.MapClaims{"sub": strconv.Itoa(exec.ID)}
+
+	if slices.Contains(scopes, "profile") {
+		claims["preferred_username"] = exec.Username
+		claims["name"] = strings.TrimSpace(exec.FirstName + " " + exec.LastName)
+		claims["role"] = exec.Role
+	}
+
+	if slices.Contains(scopes, "email") {
+		claims["email"] = exec.Email
+	}
+
+	return claims
+}
+
+// UserinfoHandler returns the claims an OAuth access token's scopes allow.
+func UserinfoHandler(w http.ResponseWriter, r *http.Request) {
+	unauthorized := func(description string) {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		oauthError(w, http.StatusUnauthorized, "invalid_token", description)
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		unauthorized("access token missing")
+		return
+	}
+
+	claims, err := utils.ParseToken(strings.TrimSpace(token))
+	if err != nil || claims["typ"] != oauthAccessTokenType {
+		unauthorized("access token is invalid or expired")
+		return
+	}
+
+	execId, _ := strconv.Atoi(fmt.Sprint(claims["sub"]))
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	exec, err := repo.FindExecByID(execId, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if exec == nil || exec.Inactive {
+		unauthorized("the user can no longer sign in")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	utils.WriteJSON(w, http.StatusOK, userClaims(exec, strings.Fields(fmt.Sprint(claims["scope"]))))
+}
+
+// DiscoveryHandler serves the OpenID Connect discovery document, or 404
+// when OpenID Connect is not set up.
+func DiscoveryHandler(w http.ResponseWriter, r *http.Request) {
+	issuer, _, signer, err := oidcProvider()
+	if err == errNoOIDC {
+		http.NotFound(w, r)
+		return
+	} else if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	utils.WriteJSON(w, http.StatusOK, models.OIDCDiscovery{
+		Issuer:                            issuer,
+		AuthorizationEndpoint:             issuer + "/oauth/authorize",
+		TokenEndpoint:                     issuer + "/oauth/token",
+		UserinfoEndpoint:                  issuer + "/oauth/userinfo",
+		JWKSURI:                           issuer + "/.well-known/jwks.json",
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{"authorization_code"},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{signer.Algorithm},
+		ScopesSupported:                   oidcScopes,
+		ClaimsSupported:                   []string{"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "preferred_username", "name", "role", "email"},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+	})
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"school-api/internal/models"
+	"school-api/internal/repositeries/db"
+	"school-api/internal/repositeries/repo"
+	"school-api/pkg/utils"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+func GetOAuthClientsHandler(w http.ResponseWriter, r *http.Request) {
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	clients, err := repo.FindOAuthClients(db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if clients == nil {
+		clients = []models.OAuthClient{}
+	}
+
+	utils.SuccessWithCount(w, "OAuth clients fetched successfully", len(clients), clients)
+}
+
+// CreateOAuthClientHandler registers an application that can sign execs in.
+// A confidential client's secret is only ever shown in this response.
+func CreateOAuthClientHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.OAuthClientRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+	defer r.Body.Close()
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		utils.Error(w, "Name is required", nil)
+		return
+	}
+
+	if len(req.RedirectURIs) == 0 {
+		utils.Error(w, "At least one redirect URI is required", nil)
+		return
+	}
+
+	for _, uri := range req.RedirectURIs {
+		if !validRedirectURI(uri) {
+			utils.Error(w, "Invalid redirect URI "+uri+", it must be an absolute https URL (http only for localhost) without a fragment", nil)
+			return
+		}
+	}
+
+	if len(req.Scopes) == 0 {
+		req.Scopes = oidcScopes
+	}
+
+	for _, s := range req.Scopes {
+		if !slices.Contains(oidcScopes, s) {
+			utils.Error(w, "Unsupported scope "+s, nil)
+			return
+		}
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	client, err := repo.CreateOAuthClient(db.DB, req)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	message := "OAuth client created successfully"
+	if client.Confidential {
+		message += ", store the client secret now as it will not be shown again"
+	}
+
+	utils.Success(w, message, client)
+}
+
+func validRedirectURI(uri string) bool {
+	u, err := url.Parse(uri)
+	if err != nil || !u.IsAbs() || u.Host == "" || u.Fragment != "" || strings.Contains(uri, "#") {
+		return false
+	}
+
+	switch u.Scheme {
+	case "https":
+		return true
+	case "http":
+		host := u.Hostname()
+		return host == "localhost" || host == "127.0.0.1" || host == "::1"
+	}
+	return false
+}
+
+func RevokeOAuthClientHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid OAuth client ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	err = repo.RevokeOAuthClient(db.DB, id)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "OAuth client not found", err)
+		return
+	} else if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	utils.Success(w, "OAuth client revoked successfully", nil)
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"html/template"
+	"net/http"
+	"school-api/internal/models"
+)
+
+// what each scope lets a client see, as shown on the consent page
+var scopeDescriptions = map[string]string{
+	"openid":  "Know who you are",
+	"profile": "Your username, name and role",
+	"email":   "Your email address",
+}
+
+var oauthPages = template.Must(template.New("oauth").Funcs(template.FuncMap{
+	"describe": func(scope string) string { return scopeDescriptions[scope] },
+}).Parse(`
+{{define "head"}}<!DOCTYPE html>
+<html lang="en"><head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Sign in</title>
+<style>body{font-family:sans-serif;max-width:24rem;margin:4rem auto;padding:0 1rem}label,input,button{display:block;width:100%;margin:.5rem 0}.error{color:#b00020}</style>
+</head><body>{{end}}
+
+{{define "scopes"}}<p><strong>{{.Client.Name}}</strong> would like to:</p>
+<ul>{{range .Scopes}}<li>{{describe .}}</li>{{end}}</ul>{{end}}
+
+{{define "login"}}{{template "head"}}
+<h1>Sign in</h1>
+{{template "scopes" .}}
+{{with .Error}}<p class="error">{{.}}</p>{{end}}
+<form method="post" action="/oauth/authorize">
+{{with .Request}}
+<input type="hidden" name="response_type" value="{{.ResponseType}}">
+<input type="hidden" name="client_id" value="{{.ClientId}}">
+<input type="hidden" name="redirect_uri" value="{{.RedirectURI}}">
+<input type="hidden" name="scope" value="{{.Scope}}">
+<input type="hidden" name="state" value="{{.State}}">
+<input type="hidden" name="nonce" value="{{.Nonce}}">
+<input type="hidden" name="code_challenge" value="{{.CodeChallenge}}">
+<input type="hidden" name="code_challenge_method" value="{{.CodeChallengeMethod}}">
+{{end}}
+<label>Username <input name="username" autocomplete="username" value="{{.Username}}" required></label>
+<label>Password <input name="password" type="password" autocomplete="current-password" required></label>
+<label>Authenticator code, if two-factor authentication is on <input name="code" inputmode="numeric" autocomplete="one-time-code"></label>
+<button name="action" value="login">Sign in</button>
+<button name="action" value="deny" formnovalidate>Cancel</button>
+</form></body></html>{{end}}
+
+{{define "consent"}}{{template "head"}}
+<h1>Allow access?</h1>
+<p>Signed in as {{.Username}}.</p>
+{{template "scopes" .}}
+<form method="post" action="/oauth/authorize/consent">
+<input type="hidden" name="ticket" value="{{.Ticket}}">
+<button name="action" value="allow">Allow</button>
+<button name="action" value="deny">Deny</button>
+</form></body></html>{{end}}
+
+{{define "error"}}{{template "head"}}
+<h1>Sign in failed</h1>
+<p class="error">{{.Error}}</p>
+</body></html>{{end}}
+`))
+
+type oauthPage struct {
+	Client   *models.OAuthClient
+	Scopes   []string
+	Request  authorizeRequest
+	Username string
+	Ticket   string
+	Error    string
+}
+
+func renderOAuthPage(w http.ResponseWriter, status int, name string, page oauthPage) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	oauthPages.ExecuteTemplate(w, name, page)
+}
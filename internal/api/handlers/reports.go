@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"school-api/internal/models"
+	"school-api/internal/repositeries/db"
+	"school-api/internal/repositeries/repo"
+	"school-api/pkg/pdf"
+	"school-api/pkg/utils"
+	"strconv"
+	"strings"
+)
+
+func GetReportCardHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid student ID", err)
+		return
+	}
+
+	from, to, err := getDateRange(r)
+	if err != nil {
+		utils.Error(w, "Invalid date range", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	termId, err := getTermParam(r, db.DB)
+	if err != nil {
+		utils.Error(w, "Invalid term", err)
+		return
+	}
+
+	term, err := repo.FindTermByID(termId, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if term == nil {
+		utils.Error(w, "Term not found, pass ?term= or set a current term", nil)
+		return
+	}
+
+	card, err := repo.BuildReportCard(r.Context(), db.DB, id, term, from, to)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if card == nil {
+		utils.Error(w, "Student not found", nil)
+		return
+	}
+
+	if wantsPDF(r) {
+		writePDF(w, fmt.Sprintf("report-card-%d-%d.pdf", id, term.ID), renderReportCard(card))
+		return
+	}
+
+	utils.Success(w, "Report card generated successfully", card)
+}
+
+func GetTranscriptHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid student ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	transcript, err := repo.BuildTranscript(r.Context(), db.DB, id)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if transcript == nil {
+		utils.Error(w, "Student not found", nil)
+		return
+	}
+
+	if wantsPDF(r) {
+		writePDF(w, fmt.Sprintf("transcript-%d.pdf", id), renderTranscript(transcript))
+		return
+	}
+
+	utils.Success(w, "Transcript generated successfully", transcript)
+}
+
+func AddReportCommentHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid student ID", err)
+		return
+	}
+
+	var comment models.ReportComment
+	if err := json.NewDecoder(r.Body).Decode(&comment); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	if comment.Comment == "" {
+		utils.Error(w, "comment is required", nil)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	if comment.TermId == 0 {
+		comment.TermId, err = repo.CurrentTermID(db.DB)
+		if err != nil {
+			utils.Http500(w, err)
+			return
+		}
+	}
+
+	term, err := repo.FindTermByID(comment.TermId, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if term == nil {
+		utils.Error(w, "Term not found", nil)
+		return
+	}
+
+	student, err := repo.FindStudentByID(r.Context(), id, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if student == nil {
+		utils.Error(w, "Student not found", nil)
+		return
+	}
+
+	allowed, err := canManageClass(r, db.DB, student.ClassId)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if !allowed {
+		utils.Error(w, "You are not assigned to this student's class", nil)
+		return
+	}
+
+	comment.StudentId = id
+	comment.AuthorId, _ = utils.UserFromContext(r.Context())
+
+	res, err := repo.AddReportComment(db.DB, &comment)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		utils.Error(w, "Failed to retrieve ID", err)
+		return
+	}
+
+	comment.ID = int(lastId)
+
+	utils.Success(w, "Comment added successfully", comment)
+}
+
+func wantsPDF(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "application/pdf")
+}
+
+func writePDF(w http.ResponseWriter, filename string, doc *pdf.Document) {
+	body := doc.Bytes()
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+func writeStudentHeader(doc *pdf.Document, student *models.Student) {
+	doc.Text(fmt.Sprintf("Student: %s %s (ID %d)", student.FirstName, student.LastName, student.ID))
+	doc.Text(fmt.Sprintf("Class: %s", student.Class.Name))
+	if student.Email != "" {
+		doc.Text(fmt.Sprintf("Email: %s", student.Email))
+	}
+}
+
+func subjectRows(subjects []models.SubjectAverage) [][]string {
+	var rows [][]string
+	for _, s := range subjects {
+		rows = append(rows, []string{
+			s.Subject,
+			fmt.Sprintf("%.2f%%", s.Average),
+			strconv.Itoa(s.AssessmentCount),
+		})
+	}
+	return rows
+}
+
+func writeAttendance(doc *pdf.Document, a models.AttendanceSummary) {
+	doc.Subheading("Attendance")
+	doc.Table(
+		[]float64{0.2, 0.2, 0.2, 0.2, 0.2},
+		[]string{"Present", "Absent", "Late", "Excused", "Absence rate"},
+		[][]string{{
+			strconv.Itoa(a.Present),
+			strconv.Itoa(a.Absent),
+			strconv.Itoa(a.Late),
+			strconv.Itoa(a.Excused),
+			fmt.Sprintf("%.1f%%", a.AbsenceRate*100),
+		}},
+	)
+}
+
+func renderReportCard(card *models.ReportCard) *pdf.Document {
+	doc := pdf.New(fmt.Sprintf("Report card - %s %s", card.Student.FirstName, card.Student.LastName))
+
+	doc.Heading("Report Card")
+	writeStudentHeader(doc, card.Student)
+	doc.Text(fmt.Sprintf("Term: %s (%s to %s)", card.Term.Name, card.Term.StartDate, card.Term.EndDate))
+
+	doc.Subheading("Subject grades")
+	doc.Table([]float64{0.5, 0.25, 0.25}, []string{"Subject", "Average", "Assessments"}, subjectRows(card.Subjects))
+	doc.Text(fmt.Sprintf("Overall average: %.2f%%", card.OverallAverage))
+
+	writeAttendance(doc, card.Attendance)
+
+	doc.Subheading("Teacher comments")
+	if len(card.Comments) == 0 {
+		doc.Text("No comments.")
+	}
+	for _, c := range card.Comments {
+		label := "General"
+		if c.Subject != "" {
+			label = c.Subject
+		}
+		if c.AuthorName != "" {
+			label += " - " + c.AuthorName
+		}
+		doc.Text(label + ": " + c.Comment)
+	}
+
+	doc.Space(10)
+	doc.Text("Generated " + card.GeneratedAt)
+
+	return doc
+}
+
+func renderTranscript(t *models.Transcript) *pdf.Document {
+	doc := pdf.New(fmt.Sprintf("Transcript - %s %s", t.Student.FirstName, t.Student.LastName))
+
+	doc.Heading("Academic Transcript")
+	writeStudentHeader(doc, t.Student)
+
+	if len(t.Terms) == 0 {
+		doc.Text("No grades recorded.")
+	}
+	for _, term := range t.Terms {
+		doc.Subheading("Term " + term.Term)
+		doc.Table([]float64{0.5, 0.25, 0.25}, []string{"Subject", "Average", "Assessments"}, subjectRows(term.Subjects))
+		doc.Text(fmt.Sprintf("Term average: %.2f%%", term.Average))
+	}
+
+	doc.Space(6)
+	doc.Text(fmt.Sprintf("Cumulative average: %.2f%%", t.CumulativeAverage))
+
+	writeAttendance(doc, t.Attendance)
+
+	doc.Space(10)
+	doc.Text("Generated " + t.GeneratedAt)
+
+	return doc
+}
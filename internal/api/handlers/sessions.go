@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"school-api/internal/models"
+	"school-api/internal/repositeries/db"
+	"school-api/internal/repositeries/repo"
+	"school-api/pkg/utils"
+	"time"
+)
+
+const refreshCookie = "Refresh"
+
+func setAccessCookie(w http.ResponseWriter, access string, accessExpires time.Time) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "Bearer",
+		Value:    access,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+		Expires:  accessExpires,
+	})
+}
+
+func setAuthCookies(w http.ResponseWriter, sid, access string, accessExpires time.Time, refresh string) {
+	setAccessCookie(w, access, accessExpires)
+
+	// the refresh token is only sent to /execs/refresh and /execs/logout
+	http.SetCookie(w, &http.Cookie{
+		Name:     refreshCookie,
+		Value:    refresh,
+		Path:     "/execs",
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+		Expires:  time.Now().Add(utils.RefreshTokenTTL()),
+	})
+
+	// readable by scripts, which echo it in the X-CSRF-Token header
+	http.SetCookie(w, &http.Cookie{
+		Name:     utils.CSRFCookie,
+		Value:    utils.CSRFToken(sid),
+		Path:     "/",
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+		Expires:  time.Now().Add(utils.RefreshTokenTTL()),
+	})
+}
+
+func clearAuthCookies(w http.ResponseWriter) {
+	for _, c := range []struct{ name, path string }{{"Bearer", "/"}, {refreshCookie, "/execs"}, {utils.CSRFCookie, "/"}} {
+		http.SetCookie(w, &http.Cookie{
+			Name:     c.name,
+			Value:    "",
+			Path:     c.path,
+			HttpOnly: true,
+			Secure:   true,
+			Expires:  time.Unix(0, 0),
+		})
+	}
+}
+
+// clientIP is the address the request came from, without the port.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// startSession opens a session for a login that has passed every factor it
+// needs, sets the token cookies and writes the response. The username's
+// failed login attempts are forgotten.
+func startSession(w http.ResponseWriter, r *http.Request, db *sql.DB, exec *models.Exec, twoFactor bool) {
+	err := repo.ClearAttempts(db, repo.ThrottleKey{Action: throttleLogin, Kind: repo.ThrottleUser, Subject: exec.Username})
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	sid, refresh, err := repo.CreateSession(db, exec.ID, r.UserAgent(), clientIP(r), twoFactor, utils.RefreshTokenTTL())
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	token, expires, err := utils.SignToken(exec.ID, exec.Username, exec.Role, sid, twoFactor)
+	if err != nil {
+		utils.Error(w, "JWT error", err)
+		return
+	}
+
+	// browsers use the cookies; other clients take the tokens from the body
+	setAuthCookies(w, sid, token, expires, refresh)
+
+	utils.Success(w, "Logged in Successfully", models.AuthTokens{
+		SessionId:        sid,
+		AccessToken:      token,
+		RefreshToken:     refresh,
+		CSRFToken:        utils.CSRFToken(sid),
+		AccessExpiresAt:  expires.Format(time.RFC3339),
+		RefreshExpiresAt: time.Now().Add(utils.RefreshTokenTTL()).Format(time.RFC3339),
+	})
+}
+
+// readRefreshToken takes the refresh token from its cookie or the
+// refresh_token field of the body. When ok is false the response has been
+// written.
+func readRefreshToken(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+
+	if cookie, err := r.Cookie(refreshCookie); err == nil {
+		req.RefreshToken = cookie.Value
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		utils.Error(w, "Invalid request body", err)
+		return "", false
+	}
+
+	if req.RefreshToken == "" {
+		http.Error(w, "Unauthorized: refresh token missing", http.StatusUnauthorized)
+		return "", false
+	}
+
+	return req.RefreshToken, true
+}
+
+// RefreshHandler trades a refresh token, from the cookie or the body, for a
+// new access token and a new refresh token. Reusing a spent refresh token
+// revokes the session.
+func RefreshHandler(w http.ResponseWriter, r *http.Request) {
+	refreshToken, ok := readRefreshToken(w, r)
+	if !ok {
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	session, refresh, err := repo.RotateRefreshToken(db.DB, refreshToken, utils.RefreshTokenTTL())
+
+	if err == repo.ErrInvalidRefreshToken || err == repo.ErrRefreshTokenReused {
+		clearAuthCookies(w)
+		http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+		return
+	} else if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	exec, err := repo.FindExecByID(session.ExecId, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if exec == nil || exec.Inactive {
+		repo.RevokeSession(db.DB, session.ExecId, session.ID, "inactive")
+		clearAuthCookies(w)
+		http.Error(w, "Unauthorized: user is not active", http.StatusUnauthorized)
+		return
+	}
+
+	token, expires, err := utils.SignToken(exec.ID, exec.Username, exec.Role, session.ID, session.TwoFactor)
+	if err != nil {
+		utils.Error(w, "JWT error", err)
+		return
+	}
+
+	setAuthCookies(w, session.ID, token, expires, refresh)
+
+	utils.Success(w, "Token refreshed successfully", models.AuthTokens{
+		SessionId:        session.ID,
+		AccessToken:      token,
+		RefreshToken:     refresh,
+		CSRFToken:        utils.CSRFToken(session.ID),
+		AccessExpiresAt:  expires.Format(time.RFC3339),
+		RefreshExpiresAt: session.ExpiresAt,
+	})
+}
+
+func GetMySessionsHandler(w http.ResponseWriter, r *http.Request) {
+	execId, _ := utils.UserFromContext(r.Context())
+	current := utils.SessionFromContext(r.Context())
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	sessions, err := repo.FindActiveSessions(db.DB, execId)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if sessions == nil {
+		sessions = []models.Session{}
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == current
+	}
+
+	utils.SuccessWithCount(w, "Sessions fetched successfully", len(sessions), sessions)
+}
+
+func RevokeMySessionHandler(w http.ResponseWriter, r *http.Request) {
+	execId, _ := utils.UserFromContext(r.Context())
+	sid := r.PathValue("sid")
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	err = repo.RevokeSession(db.DB, execId, sid, "revoked")
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Session not found", err)
+		return
+	} else if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if sid == utils.SessionFromContext(r.Context()) {
+		clearAuthCookies(w)
+	}
+
+	utils.Success(w, "Session revoked successfully", nil)
+}
+
+// RevokeMySessionsHandler signs the exec out everywhere, including this
+// session.
+func RevokeMySessionsHandler(w http.ResponseWriter, r *http.Request) {
+	execId, _ := utils.UserFromContext(r.Context())
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	if err := repo.RevokeAllSessions(db.DB, execId, "revoked_all"); err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	clearAuthCookies(w)
+
+	utils.Success(w, "All sessions revoked successfully", nil)
+}
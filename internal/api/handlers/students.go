@@ -3,6 +3,7 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"school-api/internal/models"
 	"school-api/internal/repositeries/db"
@@ -10,6 +11,7 @@ import (
 	"school-api/internal/repositeries/sqlconnect"
 	"school-api/pkg/utils"
 	"strconv"
+	"strings"
 )
 
 func GetStudentByIdHandler(w http.ResponseWriter, r *http.Request) {
@@ -28,7 +30,7 @@ func GetStudentByIdHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	student, err := repo.FindStudentByID(id, db.DB)
+	student, err := repo.FindStudentByID(r.Context(), id, db.DB)
 
 	if student == nil {
 		utils.Error(w, "Student not found", nil)
@@ -38,6 +40,14 @@ func GetStudentByIdHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if wantsInclude(r, "guardians") {
+		student.Guardians, err = repo.FindStudentGuardians(db.DB, id)
+		if err != nil {
+			utils.Http500(w, err)
+			return
+		}
+	}
+
 	utils.Success(w, "Student fetched successfully", student)
 }
 
@@ -68,7 +78,13 @@ func GetStudentsHandler(w http.ResponseWriter, r *http.Request) {
 
 	page, limit := getPaginationParams(r)
 
-	students, meta, err := repo.FindStudent(db.DB, search, filters, sort, limit, page)
+	termId, err := getTermParam(r, db.DB)
+	if err != nil {
+		utils.Error(w, "Invalid term", err)
+		return
+	}
+
+	students, meta, err := repo.FindStudent(r.Context(), db.DB, search, filters, sort, limit, page, termId)
 
 	if err != nil {
 		utils.Http500(w, err)
@@ -83,6 +99,17 @@ func GetStudentsHandler(w http.ResponseWriter, r *http.Request) {
 
 }
 
+// wantsInclude reports whether name is listed in the comma-separated
+// ?include= parameter.
+func wantsInclude(r *http.Request, name string) bool {
+	for _, v := range strings.Split(r.URL.Query().Get("include"), ",") {
+		if strings.TrimSpace(v) == name {
+			return true
+		}
+	}
+	return false
+}
+
 func getPaginationParams(r *http.Request) (int, int) {
 
 	page, err := strconv.Atoi(r.URL.Query().Get("page"))
@@ -124,7 +151,10 @@ func AddStudentHandler(w http.ResponseWriter, r *http.Request) {
 
 	res, err := repo.AddStudent(db.DB, &student)
 
-	if err != nil {
+	if errors.Is(err, repo.ErrClassFull) {
+		utils.Error(w, "Class is full", err)
+		return
+	} else if err != nil {
 		utils.Http500(w, err)
 		return
 	}
@@ -162,11 +192,14 @@ func UpdateStudentHandler(w http.ResponseWriter, r *http.Request) {
 
 	var existingStudent models.Student
 
-	_, err = repo.UpdateStudent(db.DB, &existingStudent, &updateStudent, id)
+	_, err = repo.UpdateStudent(r.Context(), db.DB, &existingStudent, &updateStudent, id)
 
 	if err == sql.ErrNoRows {
 		utils.Error(w, "Teacher not found", err)
 		return
+	} else if errors.Is(err, repo.ErrClassFull) {
+		utils.Error(w, "Class is full", err)
+		return
 	} else if err != nil {
 		utils.Http500(w, err)
 		return
@@ -218,7 +251,7 @@ func DeleteStudentHandler(w http.ResponseWriter, r *http.Request) {
 
 func GetStudentOfTeachers(w http.ResponseWriter, r *http.Request) {
 
-	db, err := sqlconnect.ConnectDB()
+	db, err := db.New()
 	if err != nil {
 		utils.Http500(w, err)
 		return
@@ -239,33 +272,26 @@ func GetStudentOfTeachers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var teacherClassId int
-
-	err = db.QueryRow("SELECT class_id FROM teachers WHERE id=? ", teacherId).Scan(&teacherClassId)
+	teacher, err := repo.FindTeacherByID(r.Context(), teacherId, db.DB)
 
-	if err == sql.ErrNoRows {
-		utils.Error(w, "No teacher found ", err)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if teacher == nil {
+		utils.Error(w, "No teacher found ", nil)
 		return
 	}
 
-	var students []models.Student
-
-	rows, err := db.Query("SELECT id,class_id,first_name,last_name ,email FROM student WHERE class_id=?", teacherClassId)
+	students, err := repo.FindStudentsByTeacher(db.DB, teacherId)
 	if err != nil {
-
 		utils.Error(w, "Student data not found", err)
 		return
 	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var s models.Student
-
-		err = rows.Scan(&s.ID, &s.ClassId, &s.FirstName, &s.LastName, &s.Email)
-
-		students = append(students, s)
 
+	if students == nil {
+		students = []models.Student{}
 	}
-	utils.Success(w, "students found successfully", students)
+
+	utils.SuccessWithCount(w, "students found successfully", len(students), students)
 
 }
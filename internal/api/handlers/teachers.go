@@ -28,7 +28,7 @@ func GetTeacherByIdHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	teacher, err := repo.FindTeacherByID(id, db.DB)
+	teacher, err := repo.FindTeacherByID(r.Context(), id, db.DB)
 
 	if teacher == nil {
 		utils.Error(w, "Teacher not found", nil)
@@ -66,7 +66,7 @@ func GetTeachersHandler(w http.ResponseWriter, r *http.Request) {
 		"subject":    true,
 	})
 
-	teachers, err := repo.FindTeacher(db.DB, search, filters, sort)
+	teachers, err := repo.FindTeacher(r.Context(), db.DB, search, filters, sort)
 
 	if err != nil {
 		utils.Http500(w, err)
@@ -136,7 +136,7 @@ func UpdateTeacherHandler(w http.ResponseWriter, r *http.Request) {
 
 	var existingTeacher models.Teacher
 
-	_, err = repo.UpdateTeacher(db.DB, &existingTeacher, &updateTeacher, id)
+	_, err = repo.UpdateTeacher(r.Context(), db.DB, &existingTeacher, &updateTeacher, id)
 
 	if err == sql.ErrNoRows {
 		utils.Error(w, "Teacher not found", err)
@@ -229,3 +229,166 @@ func PatchMultipleTeachersHandler(w http.ResponseWriter, r *http.Request) {
 
 	utils.Success(w, "Teachers updated successfully", nil)
 }
+
+func GetTeacherAssignmentsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid teacher ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	termId := 0
+	if v := r.URL.Query().Get("term"); v != "" {
+		termId, err = strconv.Atoi(v)
+		if err != nil {
+			utils.Error(w, "Invalid term", err)
+			return
+		}
+	}
+
+	assignments, err := repo.FindTeacherAssignments(db.DB, id, termId)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if assignments == nil {
+		assignments = []models.TeacherAssignment{}
+	}
+
+	utils.SuccessWithCount(w, "Assignments fetched successfully", len(assignments), assignments)
+}
+
+func AssignTeacherHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid teacher ID", err)
+		return
+	}
+
+	var assignment models.TeacherAssignment
+	if err := json.NewDecoder(r.Body).Decode(&assignment); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	if assignment.ClassId == 0 || assignment.Subject == "" {
+		utils.Error(w, "class_id and subject are required", nil)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	teacher, err := repo.FindTeacherByID(r.Context(), id, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if teacher == nil {
+		utils.Error(w, "Teacher not found", nil)
+		return
+	}
+
+	class, err := repo.FindClassByID(assignment.ClassId, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if class == nil {
+		utils.Error(w, "Class not found", nil)
+		return
+	}
+
+	if assignment.TermId == 0 {
+		assignment.TermId, err = repo.CurrentTermID(db.DB)
+		if err != nil {
+			utils.Http500(w, err)
+			return
+		}
+	}
+
+	if assignment.TermId != 0 {
+		term, err := repo.FindTermByID(assignment.TermId, db.DB)
+		if err != nil {
+			utils.Http500(w, err)
+			return
+		} else if term == nil {
+			utils.Error(w, "Term not found", nil)
+			return
+		}
+		assignment.TermName = term.Name
+	}
+
+	assignment.TeacherId = id
+	assignment.ClassName = class.Name
+
+	exists, err := repo.CheckTeacherAssignmentExists(db.DB, id, assignment.ClassId, assignment.Subject, assignment.TermId)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if exists {
+		utils.Error(w, "Teacher is already assigned to this class and subject", nil)
+		return
+	}
+
+	res, err := repo.AddTeacherAssignment(db.DB, &assignment)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		utils.Error(w, "Failed to retrieve ID", err)
+		return
+	}
+
+	assignment.ID = int(lastId)
+
+	utils.Success(w, "Teacher assigned successfully", assignment)
+}
+
+func UnassignTeacherHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid teacher ID", err)
+		return
+	}
+
+	assignmentId, err := strconv.Atoi(r.PathValue("assignmentId"))
+	if err != nil {
+		utils.Error(w, "Invalid assignment ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	err = repo.DeleteTeacherAssignment(db.DB, id, assignmentId)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Assignment not found", err)
+		return
+	} else if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	utils.Success(w, "Teacher unassigned successfully", nil)
+}
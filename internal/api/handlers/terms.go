@@ -0,0 +1,488 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"school-api/internal/models"
+	"school-api/internal/repositeries/db"
+	"school-api/internal/repositeries/repo"
+	"school-api/pkg/utils"
+	"strconv"
+	"time"
+)
+
+func GetAcademicYearsHandler(w http.ResponseWriter, r *http.Request) {
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	years, err := repo.FindAcademicYears(db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if years == nil {
+		years = []models.AcademicYear{}
+	}
+
+	utils.SuccessWithCount(w, "Academic years fetched successfully", len(years), years)
+}
+
+func GetAcademicYearByIdHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid academic year ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	year, err := repo.FindAcademicYearByID(id, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if year == nil {
+		utils.Error(w, "Academic year not found", nil)
+		return
+	}
+
+	utils.Success(w, "Academic year fetched successfully", year)
+}
+
+func AddAcademicYearHandler(w http.ResponseWriter, r *http.Request) {
+	var year models.AcademicYear
+	if err := json.NewDecoder(r.Body).Decode(&year); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	if year.Name == "" {
+		utils.Error(w, "name is required", nil)
+		return
+	}
+
+	if err := validateDates(year.StartDate, year.EndDate); err != nil {
+		utils.Error(w, "Invalid dates", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	res, err := repo.AddAcademicYear(db.DB, &year)
+	if err != nil {
+		utils.Error(w, "Failed to add academic year", err)
+		return
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		utils.Error(w, "Failed to retrieve ID", err)
+		return
+	}
+
+	year.ID = int(lastId)
+
+	utils.Success(w, "Academic year added successfully", year)
+}
+
+func UpdateAcademicYearHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid academic year ID", err)
+		return
+	}
+
+	var update models.AcademicYear
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	var existing models.AcademicYear
+
+	_, err = repo.UpdateAcademicYear(db.DB, &existing, &update, id)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Academic year not found", err)
+		return
+	} else if err != nil {
+		utils.Error(w, "Failed to update academic year", err)
+		return
+	}
+
+	utils.Success(w, "Academic year updated successfully", update)
+}
+
+func DeleteAcademicYearHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid academic year ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	err = repo.DeleteAcademicYear(db.DB, id)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Academic year not found", err)
+		return
+	} else if err != nil {
+		utils.Error(w, "Failed to delete academic year", err)
+		return
+	}
+
+	utils.Success(w, "Academic year deleted successfully", nil)
+}
+
+func GetTermsHandler(w http.ResponseWriter, r *http.Request) {
+	yearId := 0
+	if v := r.URL.Query().Get("academic_year_id"); v != "" {
+		var err error
+		yearId, err = strconv.Atoi(v)
+		if err != nil {
+			utils.Error(w, "Invalid academic year ID", err)
+			return
+		}
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	terms, err := repo.FindTerms(db.DB, yearId)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if terms == nil {
+		terms = []models.Term{}
+	}
+
+	utils.SuccessWithCount(w, "Terms fetched successfully", len(terms), terms)
+}
+
+func GetTermByIdHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid term ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	term, err := repo.FindTermByID(id, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if term == nil {
+		utils.Error(w, "Term not found", nil)
+		return
+	}
+
+	utils.Success(w, "Term fetched successfully", term)
+}
+
+func GetCurrentTermHandler(w http.ResponseWriter, r *http.Request) {
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	term, err := repo.CurrentTerm(db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if term == nil {
+		utils.Error(w, "No current term is set", nil)
+		return
+	}
+
+	utils.Success(w, "Current term fetched successfully", term)
+}
+
+func AddTermHandler(w http.ResponseWriter, r *http.Request) {
+	var term models.Term
+	if err := json.NewDecoder(r.Body).Decode(&term); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	if term.Name == "" || term.AcademicYearId == 0 {
+		utils.Error(w, "name and academic_year_id are required", nil)
+		return
+	}
+
+	if err := validateDates(term.StartDate, term.EndDate); err != nil {
+		utils.Error(w, "Invalid dates", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	res, err := repo.AddTerm(db.DB, &term)
+	if err != nil {
+		utils.Error(w, "Failed to add term", err)
+		return
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		utils.Error(w, "Failed to retrieve ID", err)
+		return
+	}
+
+	term.ID = int(lastId)
+	term.IsCurrent = false
+
+	utils.Success(w, "Term added successfully", term)
+}
+
+func UpdateTermHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid term ID", err)
+		return
+	}
+
+	var update models.Term
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	var existing models.Term
+
+	_, err = repo.UpdateTerm(db.DB, &existing, &update, id)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Term not found", err)
+		return
+	} else if err != nil {
+		utils.Error(w, "Failed to update term", err)
+		return
+	}
+
+	utils.Success(w, "Term updated successfully", update)
+}
+
+func DeleteTermHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid term ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	err = repo.DeleteTerm(db.DB, id)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Term not found", err)
+		return
+	} else if err != nil {
+		utils.Error(w, "Failed to delete term", err)
+		return
+	}
+
+	utils.Success(w, "Term deleted successfully", nil)
+}
+
+func SetCurrentTermHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid term ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	err = repo.SetCurrentTerm(db.DB, id)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Term not found", err)
+		return
+	} else if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	utils.Success(w, "Current term updated successfully", nil)
+}
+
+func GetStudentEnrollmentsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid student ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	if !studentVisible(w, r, db.DB, id) {
+		return
+	}
+
+	enrollments, err := repo.FindEnrollments(db.DB, id)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if enrollments == nil {
+		enrollments = []models.Enrollment{}
+	}
+
+	utils.SuccessWithCount(w, "Enrollments fetched successfully", len(enrollments), enrollments)
+}
+
+// getTermParam returns the term id from ?term=, defaulting to the current
+// term. It returns 0 when no term is given and none is current.
+func getTermParam(r *http.Request, db *sql.DB) (int, error) {
+	if v := r.URL.Query().Get("term"); v != "" {
+		return strconv.Atoi(v)
+	}
+	return repo.CurrentTermID(db)
+}
+
+func validateDates(start, end string) error {
+	startDate, err := time.Parse(dateLayout, start)
+	if err != nil {
+		return err
+	}
+
+	endDate, err := time.Parse(dateLayout, end)
+	if err != nil {
+		return err
+	}
+
+	if !startDate.Before(endDate) {
+		return fmt.Errorf("start_date must be before end_date")
+	}
+
+	return nil
+}
+
+// RolloverHandler moves students into the term in the path. The previous
+// term defaults to the current one.
+func RolloverHandler(w http.ResponseWriter, r *http.Request) {
+	toTermId, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid term ID", err)
+		return
+	}
+
+	var req models.RolloverRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	toTerm, err := repo.FindTermByID(toTermId, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if toTerm == nil {
+		utils.Error(w, "Term not found", nil)
+		return
+	}
+
+	if req.FromTermId == 0 {
+		req.FromTermId, err = repo.CurrentTermID(db.DB)
+		if err != nil {
+			utils.Http500(w, err)
+			return
+		}
+	}
+
+	if req.FromTermId == toTermId {
+		utils.Error(w, "Cannot roll over into the same term", nil)
+		return
+	}
+
+	report, err := repo.RunRollover(db.DB, toTermId, &req)
+	if err != nil {
+		utils.Error(w, "Rollover failed", err)
+		return
+	}
+
+	message := "Rollover completed successfully"
+	if req.DryRun {
+		message = "Rollover preview generated successfully"
+	}
+
+	utils.Success(w, message, report)
+}
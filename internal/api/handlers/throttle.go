@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"os"
+	"school-api/internal/repositeries/repo"
+	"school-api/pkg/utils"
+	"strconv"
+	"time"
+)
+
+const (
+	throttleLogin  = "login"
+	throttleForgot = "forgot"
+	throttleReset  = "reset"
+)
+
+// throttlePolicy builds the policy for an action and key kind. Logins lock a
+// username out after LOGIN_MAX_FAILURES (default 5) and an address after
+// LOGIN_IP_MAX_FAILURES (default 20), for LOGIN_LOCKOUT (default 15m).
+// Password reset requests and reset codes use fixed, stricter limits.
+func throttlePolicy(action, kind string) repo.ThrottlePolicy {
+	switch action {
+	case throttleLogin:
+		lockout := 15 * time.Minute
+		if d, err := time.ParseDuration(os.Getenv("LOGIN_LOCKOUT")); err == nil && d > 0 {
+			lockout = d
+		}
+
+		limit := intEnv("LOGIN_MAX_FAILURES", 5)
+		if kind == repo.ThrottleIP {
+			limit = intEnv("LOGIN_IP_MAX_FAILURES", 20)
+		}
+
+		return repo.ThrottlePolicy{MaxFailures: limit, Delay: time.Second, MaxDelay: 30 * time.Second, Lockout: lockout, Window: lockout}
+
+	case throttleForgot:
+		// every request counts, as the caller is never told if it worked
+		limit := 5
+		if kind == repo.ThrottleIP {
+			limit = 20
+		}
+		return repo.ThrottlePolicy{MaxFailures: limit, Delay: time.Second, MaxDelay: time.Minute, Lockout: time.Hour, Window: time.Hour}
+
+	default:
+		return repo.ThrottlePolicy{MaxFailures: 10, Delay: time.Second, MaxDelay: time.Minute, Lockout: time.Hour, Window: time.Hour}
+	}
+}
+
+func intEnv(name string, fallback int) int {
+	if n, err := strconv.Atoi(os.Getenv(name)); err == nil && n > 0 {
+		return n
+	}
+	return fallback
+}
+
+// throttleKeys are the keys an action is counted against: the username or
+// email when there is one, and the client address.
+func throttleKeys(r *http.Request, action, user string) []repo.ThrottleKey {
+	keys := []repo.ThrottleKey{{Action: action, Kind: repo.ThrottleIP, Subject: clientIP(r)}}
+	if user != "" {
+		keys = append(keys, repo.ThrottleKey{Action: action, Kind: repo.ThrottleUser, Subject: user})
+	}
+	return keys
+}
+
+// throttled writes a 429 and reports true when any of keys is blocked.
+func throttled(w http.ResponseWriter, db *sql.DB, keys []repo.ThrottleKey) bool {
+	wait, err := repo.ThrottleWait(db, keys...)
+	if err != nil {
+		utils.Http500(w, err)
+		return true
+	}
+
+	if wait > 0 {
+		utils.Http429(w, wait)
+		return true
+	}
+
+	return false
+}
+
+// recordFailure counts a failed attempt against each of keys. It reports
+// whether the user key was locked out by it.
+func recordFailure(db *sql.DB, keys []repo.ThrottleKey) (bool, error) {
+	var userLocked bool
+
+	for _, k := range keys {
+		locked, err := repo.RecordFailedAttempt(db, k, throttlePolicy(k.Action, k.Kind))
+		if err != nil {
+			return false, err
+		}
+		if locked && k.Kind == repo.ThrottleUser {
+			userLocked = true
+		}
+	}
+
+	return userLocked, nil
+}
@@ -0,0 +1,363 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"school-api/internal/models"
+	"school-api/internal/repositeries/db"
+	"school-api/internal/repositeries/repo"
+	"school-api/pkg/ical"
+	"school-api/pkg/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const clockLayout = "15:04"
+
+func GetTimetableHandler(w http.ResponseWriter, r *http.Request) {
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	termId, err := getTermParam(r, db.DB)
+	if err != nil {
+		utils.Error(w, "Invalid term", err)
+		return
+	}
+
+	filters := map[string]string{
+		"class_id":    r.URL.Query().Get("class_id"),
+		"teacher_id":  r.URL.Query().Get("teacher_id"),
+		"room":        r.URL.Query().Get("room"),
+		"day_of_week": r.URL.Query().Get("day_of_week"),
+	}
+
+	slots, err := repo.FindTimetable(db.DB, filters, termId)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if slots == nil {
+		slots = []models.TimetableSlot{}
+	}
+
+	utils.SuccessWithCount(w, "Timetable fetched successfully", len(slots), slots)
+}
+
+func GetTimetableSlotByIdHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid slot ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	slot, err := repo.FindTimetableSlotByID(id, db.DB)
+
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if slot == nil {
+		utils.Error(w, "Timetable slot not found", nil)
+		return
+	}
+
+	utils.Success(w, "Timetable slot fetched successfully", slot)
+}
+
+func AddTimetableSlotHandler(w http.ResponseWriter, r *http.Request) {
+	var slot models.TimetableSlot
+	if err := json.NewDecoder(r.Body).Decode(&slot); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	if slot.ClassId == 0 || slot.TeacherId == 0 || slot.Subject == "" || slot.DayOfWeek == 0 || slot.Period == 0 ||
+		slot.StartTime == "" || slot.EndTime == "" {
+		utils.Error(w, "class_id, teacher_id, subject, day_of_week, period, start_time and end_time are required", nil)
+		return
+	}
+
+	if err := validateSlot(&slot); err != nil {
+		utils.Error(w, "Invalid timetable slot", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	if slot.TermId == 0 {
+		slot.TermId, err = repo.CurrentTermID(db.DB)
+		if err != nil {
+			utils.Http500(w, err)
+			return
+		} else if slot.TermId == 0 {
+			utils.Error(w, "term_id is required when no current term is set", nil)
+			return
+		}
+	}
+
+	res, err := repo.AddTimetableSlot(db.DB, &slot)
+	if err != nil {
+		utils.Error(w, "Failed to add timetable slot", err)
+		return
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		utils.Error(w, "Failed to retrieve ID", err)
+		return
+	}
+
+	slot.ID = int(lastId)
+
+	utils.Success(w, "Timetable slot added successfully", slot)
+}
+
+func UpdateTimetableSlotHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid slot ID", err)
+		return
+	}
+
+	var update models.TimetableSlot
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+
+	if err := validateSlot(&update); err != nil {
+		utils.Error(w, "Invalid timetable slot", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	var existing models.TimetableSlot
+
+	_, err = repo.UpdateTimetableSlot(db.DB, &existing, &update, id)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Timetable slot not found", err)
+		return
+	} else if err != nil {
+		utils.Error(w, "Failed to update timetable slot", err)
+		return
+	}
+
+	utils.Success(w, "Timetable slot updated successfully", update)
+}
+
+func DeleteTimetableSlotHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid slot ID", err)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	err = repo.DeleteTimetableSlot(db.DB, id)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Timetable slot not found", err)
+		return
+	} else if err != nil {
+		utils.Error(w, "Failed to delete timetable slot", err)
+		return
+	}
+
+	utils.Success(w, "Timetable slot deleted successfully", nil)
+}
+
+func GetClassTimetableHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid class ID", err)
+		return
+	}
+
+	writeTimetable(w, r, "class_id", strconv.Itoa(id), fmt.Sprintf("class-%d", id))
+}
+
+func GetTeacherTimetableHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.Error(w, "Invalid teacher ID", err)
+		return
+	}
+
+	writeTimetable(w, r, "teacher_id", strconv.Itoa(id), fmt.Sprintf("teacher-%d", id))
+}
+
+func GetRoomTimetableHandler(w http.ResponseWriter, r *http.Request) {
+	room := r.PathValue("room")
+
+	writeTimetable(w, r, "room", room, "room-"+room)
+}
+
+// writeTimetable responds with the slots matching one filter for the
+// requested term, as JSON or as an .ics file.
+func writeTimetable(w http.ResponseWriter, r *http.Request, key, value, name string) {
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	termId, err := getTermParam(r, db.DB)
+	if err != nil {
+		utils.Error(w, "Invalid term", err)
+		return
+	}
+
+	slots, err := repo.FindTimetable(db.DB, map[string]string{key: value}, termId)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if slots == nil {
+		slots = []models.TimetableSlot{}
+	}
+
+	if !wantsICS(r) {
+		utils.SuccessWithCount(w, "Timetable fetched successfully", len(slots), slots)
+		return
+	}
+
+	term, err := repo.FindTermByID(termId, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	} else if term == nil {
+		utils.Error(w, "Term not found, pass ?term= or set a current term", nil)
+		return
+	}
+
+	cal, err := timetableCalendar("Timetable "+name, term, slots)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".ics"))
+	w.WriteHeader(http.StatusOK)
+	w.Write(cal.Bytes())
+}
+
+func wantsICS(r *http.Request) bool {
+	return r.URL.Query().Get("format") == "ics" || strings.Contains(r.Header.Get("Accept"), "text/calendar")
+}
+
+// timetableCalendar turns each slot into an event repeating weekly from its
+// first occurrence in the term until the term ends.
+func timetableCalendar(name string, term *models.Term, slots []models.TimetableSlot) (*ical.Calendar, error) {
+	start, err := time.Parse(dateLayout, term.StartDate)
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := time.Parse(dateLayout, term.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	until := end.Add(24*time.Hour - time.Second)
+
+	cal := ical.New(name)
+
+	for _, s := range slots {
+		from, err := time.Parse(clockLayout, s.StartTime)
+		if err != nil {
+			return nil, err
+		}
+
+		to, err := time.Parse(clockLayout, s.EndTime)
+		if err != nil {
+			return nil, err
+		}
+
+		day := start.AddDate(0, 0, (int(time.Weekday(s.DayOfWeek%7))-int(start.Weekday())+7)%7)
+		if day.After(end) {
+			continue
+		}
+
+		summary := s.Subject + " - " + s.ClassName
+		if s.Room != "" {
+			summary += " (" + s.Room + ")"
+		}
+
+		cal.Add(ical.Event{
+			UID:         fmt.Sprintf("timetable-slot-%d@school-api", s.ID),
+			Summary:     summary,
+			Location:    s.Room,
+			Description: fmt.Sprintf("Period %d with %s", s.Period, s.TeacherName),
+			Start:       day.Add(time.Duration(from.Hour())*time.Hour + time.Duration(from.Minute())*time.Minute),
+			End:         day.Add(time.Duration(to.Hour())*time.Hour + time.Duration(to.Minute())*time.Minute),
+			Until:       until,
+		})
+	}
+
+	return cal, nil
+}
+
+// validateSlot checks the fields that are set and normalises times to
+// HH:MM. Ordering of the times is checked again after an update is merged.
+func validateSlot(s *models.TimetableSlot) error {
+	if s.DayOfWeek < 0 || s.DayOfWeek > 7 {
+		return fmt.Errorf("day_of_week must be between 1 (Monday) and 7 (Sunday)")
+	}
+
+	if s.Period < 0 {
+		return fmt.Errorf("period must be positive")
+	}
+
+	for _, t := range []*string{&s.StartTime, &s.EndTime} {
+		if *t == "" {
+			continue
+		}
+
+		parsed, err := time.Parse(clockLayout, *t)
+		if err != nil {
+			return fmt.Errorf("times must be HH:MM, got %q", *t)
+		}
+		*t = parsed.Format(clockLayout)
+	}
+
+	if s.StartTime != "" && s.EndTime != "" && s.StartTime >= s.EndTime {
+		return fmt.Errorf("start_time must be before end_time")
+	}
+
+	return nil
+}
@@ -0,0 +1,426 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"os"
+	"school-api/internal/models"
+	"school-api/internal/repositeries/db"
+	"school-api/internal/repositeries/repo"
+	"school-api/pkg/rbac"
+	"school-api/pkg/totp"
+	"school-api/pkg/utils"
+	"time"
+)
+
+const defaultTOTPIssuer = "School API"
+
+// verifySecondFactor checks an authenticator code or, failing that, a
+// recovery code. Either is spent on success.
+func verifySecondFactor(db *sql.DB, execId int, code, recoveryCode string) (bool, error) {
+	if recoveryCode != "" {
+		return repo.UseRecoveryCode(db, execId, recoveryCode)
+	}
+
+	secret, enabled, err := repo.FindTOTPSecret(db, execId)
+	if err != nil || !enabled {
+		return false, err
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+
+	return repo.UseTOTPStep(db, execId, step)
+}
+
+// checkPassword re-authenticates the exec for sensitive 2FA changes.
+func checkPassword(db *sql.DB, execId int, password string) (bool, error) {
+	var hashed string
+	if err := db.QueryRow("SELECT password FROM execs WHERE id = ?", execId).Scan(&hashed); err != nil {
+		return false, err
+	}
+	return repo.VerifyPassword(password, hashed)
+}
+
+func GetTwoFactorStatusHandler(w http.ResponseWriter, r *http.Request) {
+	execId, role := utils.UserFromContext(r.Context())
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	_, enabled, err := repo.FindTOTPSecret(db.DB, execId)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	left, err := repo.CountRecoveryCodes(db.DB, execId)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	utils.Success(w, "Two-factor status fetched successfully", models.TwoFactorStatus{
+		Enabled:           enabled,
+		Required:          rbac.RequiresTwoFactor(role),
+		RecoveryCodesLeft: left,
+	})
+}
+
+// EnrollTwoFactorHandler starts enrollment with a new secret. 2FA is not on
+// until a code from it is confirmed at /execs/me/2fa/verify.
+func EnrollTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	execId, _ := utils.UserFromContext(r.Context())
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	exec, err := repo.FindExecByID(execId, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if exec == nil {
+		utils.Error(w, "Exec not found", nil)
+		return
+	}
+
+	secret, err := totp.NewSecret()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	err = repo.SetTOTPSecret(db.DB, execId, secret)
+
+	if err == sql.ErrNoRows {
+		utils.Error(w, "Two-factor authentication is already enabled", nil)
+		return
+	} else if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	issuer := os.Getenv("TOTP_ISSUER")
+	if issuer == "" {
+		issuer = defaultTOTPIssuer
+	}
+
+	utils.Success(w, "Two-factor enrollment started", models.TwoFactorEnrollment{
+		Secret: secret,
+		URI:    totp.URI(issuer, exec.Username, secret),
+	})
+}
+
+// VerifyTwoFactorHandler confirms enrollment with a code from the new
+// secret, turns 2FA on and returns the recovery codes. Other sessions are
+// signed out and the current one gets a token that counts as two-factor.
+func VerifyTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	execId, role := utils.UserFromContext(r.Context())
+	sid := utils.SessionFromContext(r.Context())
+
+	var req models.TwoFactorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+	defer r.Body.Close()
+
+	if req.Code == "" {
+		utils.Error(w, "Code is required", nil)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	secret, enabled, err := repo.FindTOTPSecret(db.DB, execId)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if enabled {
+		utils.Error(w, "Two-factor authentication is already enabled", nil)
+		return
+	}
+
+	if secret == "" {
+		utils.Error(w, "Two-factor enrollment has not been started", nil)
+		return
+	}
+
+	step, ok := totp.Validate(secret, req.Code, time.Now())
+	if !ok {
+		utils.Error(w, "Invalid code", nil)
+		return
+	}
+
+	codes, err := repo.EnableTwoFactor(db.DB, execId, step)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if err := repo.MarkSessionTwoFactor(db.DB, sid); err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if err := repo.RevokeOtherSessions(db.DB, execId, sid, "2fa_enabled"); err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	username, _ := r.Context().Value("username").(string)
+	token, expires, err := utils.SignToken(execId, username, role, sid, true)
+	if err != nil {
+		utils.Error(w, "JWT error", err)
+		return
+	}
+
+	setAccessCookie(w, token, expires)
+
+	utils.Success(w, "Two-factor authentication enabled", models.RecoveryCodes{Codes: codes, Token: token})
+}
+
+// DisableTwoFactorHandler turns 2FA off and signs out the exec's other
+// sessions. It needs the password and a current code or recovery code, and
+// is refused for roles that require 2FA.
+func DisableTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	execId, role := utils.UserFromContext(r.Context())
+
+	var req models.TwoFactorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+	defer r.Body.Close()
+
+	if rbac.RequiresTwoFactor(role) {
+		utils.Error(w, "Two-factor authentication is required for role "+role, nil)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	if !reauthenticate(w, r, db.DB, execId, req) {
+		return
+	}
+
+	if err := repo.DisableTwoFactor(db.DB, execId); err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	// other sessions passed a second factor that no longer exists
+	err = repo.RevokeOtherSessions(db.DB, execId, utils.SessionFromContext(r.Context()), "two_factor_disabled")
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	utils.Success(w, "Two-factor authentication disabled", nil)
+}
+
+// RegenerateRecoveryCodesHandler replaces the recovery codes. Like
+// disabling, it needs the password and a second factor.
+func RegenerateRecoveryCodesHandler(w http.ResponseWriter, r *http.Request) {
+	execId, _ := utils.UserFromContext(r.Context())
+
+	var req models.TwoFactorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+	defer r.Body.Close()
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	if !reauthenticate(w, r, db.DB, execId, req) {
+		return
+	}
+
+	codes, err := repo.RegenerateRecoveryCodes(db.DB, execId)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	utils.Success(w, "Recovery codes regenerated", models.RecoveryCodes{Codes: codes})
+}
+
+// reauthenticate checks the password and second factor in req for an exec
+// with 2FA enabled. Wrong answers count against the login limits. When it
+// reports false it has already written the response.
+func reauthenticate(w http.ResponseWriter, r *http.Request, db *sql.DB, execId int, req models.TwoFactorRequest) bool {
+	if req.Password == "" || (req.Code == "" && req.RecoveryCode == "") {
+		utils.Error(w, "Password and a code or recovery code are required", nil)
+		return false
+	}
+
+	_, enabled, err := repo.FindTOTPSecret(db, execId)
+	if err != nil {
+		utils.Http500(w, err)
+		return false
+	}
+
+	if !enabled {
+		utils.Error(w, "Two-factor authentication is not enabled", nil)
+		return false
+	}
+
+	exec, err := repo.FindExecByID(execId, db)
+	if err != nil {
+		utils.Http500(w, err)
+		return false
+	}
+
+	if exec == nil {
+		utils.Error(w, "user not found", nil)
+		return false
+	}
+
+	keys := throttleKeys(r, throttleLogin, exec.Username)
+	if throttled(w, db, keys) {
+		return false
+	}
+
+	fail := func(message string) bool {
+		locked, err := recordFailure(db, keys)
+		if err != nil {
+			utils.Http500(w, err)
+			return false
+		}
+
+		if locked {
+			queueLockoutNotice(db, exec, throttlePolicy(throttleLogin, repo.ThrottleUser).Lockout)
+		}
+
+		utils.Error(w, message, nil)
+		return false
+	}
+
+	ok, err := checkPassword(db, execId, req.Password)
+	if err != nil {
+		utils.Error(w, "Error verifying password", err)
+		return false
+	}
+
+	if !ok {
+		return fail("Password does not match")
+	}
+
+	ok, err = verifySecondFactor(db, execId, req.Code, req.RecoveryCode)
+	if err != nil {
+		utils.Http500(w, err)
+		return false
+	}
+
+	if !ok {
+		return fail("Invalid code")
+	}
+
+	err = repo.ClearAttempts(db, repo.ThrottleKey{Action: throttleLogin, Kind: repo.ThrottleUser, Subject: exec.Username})
+	if err != nil {
+		utils.Http500(w, err)
+		return false
+	}
+
+	return true
+}
+
+// LoginTwoFactorHandler is the second login step: it redeems the challenge
+// token from LoginHandler together with a code or recovery code.
+func LoginTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.TwoFactorLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.Error(w, "Invalid request body", err)
+		return
+	}
+	defer r.Body.Close()
+
+	if req.ChallengeToken == "" || (req.Code == "" && req.RecoveryCode == "") {
+		utils.Error(w, "Challenge token and a code or recovery code are required", nil)
+		return
+	}
+
+	execId, err := utils.ParseChallengeToken(req.ChallengeToken)
+	if err != nil {
+		http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	db, err := db.New()
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+	defer db.Close()
+
+	exec, err := repo.FindExecByID(execId, db.DB)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if exec == nil || exec.Inactive {
+		utils.Error(w, "user is not active", nil)
+		return
+	}
+
+	// wrong codes count against the same limits as wrong passwords
+	keys := throttleKeys(r, throttleLogin, exec.Username)
+	if throttled(w, db.DB, keys) {
+		return
+	}
+
+	ok, err := verifySecondFactor(db.DB, execId, req.Code, req.RecoveryCode)
+	if err != nil {
+		utils.Http500(w, err)
+		return
+	}
+
+	if !ok {
+		locked, err := recordFailure(db.DB, keys)
+		if err != nil {
+			utils.Http500(w, err)
+			return
+		}
+
+		if locked {
+			queueLockoutNotice(db.DB, exec, throttlePolicy(throttleLogin, repo.ThrottleUser).Lockout)
+		}
+
+		utils.Error(w, "Invalid code", nil)
+		return
+	}
+
+	startSession(w, r, db.DB, exec, true)
+}
@@ -0,0 +1,52 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"school-api/internal/repositeries/db"
+	"school-api/internal/repositeries/repo"
+	"school-api/pkg/utils"
+	"strconv"
+	"strings"
+)
+
+// authenticateAPIKey serves a request that presents an API key instead of
+// an access token. The key's owner becomes the caller, limited to the
+// permissions on the key. Keys cannot be used to manage credentials.
+func authenticateAPIKey(w http.ResponseWriter, r *http.Request, next http.Handler, key string) {
+	if isCredentialPath(r.URL.Path) {
+		utils.Forbidden(w, "Forbidden: API keys cannot manage credentials")
+		return
+	}
+
+	conn, err := db.New()
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	identity, err := repo.AuthenticateAPIKey(conn.DB, strings.TrimSpace(key))
+	conn.Close()
+
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	} else if identity == nil {
+		http.Error(w, "Unauthorized: invalid API key", http.StatusUnauthorized)
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), "role", identity.Role)
+
+	ctx = context.WithValue(ctx, "userId", strconv.Itoa(identity.ExecId))
+	ctx = context.WithValue(ctx, "username", identity.Username)
+	ctx = context.WithValue(ctx, "apiKeyPermissions", identity.Permissions)
+
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+func isCredentialPath(path string) bool {
+	return strings.HasPrefix(path, "/execs/me/2fa") ||
+		strings.HasPrefix(path, "/execs/me/sessions") ||
+		strings.HasSuffix(path, "/updatePassword") ||
+		strings.Contains(path, "/api-keys")
+}
@@ -0,0 +1,49 @@
+package middlewares
+
+import (
+	"net/http"
+	"school-api/pkg/rbac"
+	"school-api/pkg/utils"
+	"slices"
+	"strconv"
+)
+
+// Authorize wraps a route handler so it only runs when the logged in role
+// holds permission, and the API key if one was used carries it too. It
+// relies on JWTMiddleware having put the role on the request context.
+func Authorize(permission string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, role := utils.UserFromContext(r.Context())
+
+		if !rbac.Can(role, permission) || !keyAllows(r, permission) {
+			utils.Forbidden(w, "Forbidden: missing permission "+permission)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// AuthorizeSelfOr lets an exec act on their own record, the {id} in the
+// path, and otherwise requires permission. An API key needs the permission
+// either way.
+func AuthorizeSelfOr(permission string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userId, role := utils.UserFromContext(r.Context())
+
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if ((err != nil || id != userId) && !rbac.Can(role, permission)) || !keyAllows(r, permission) {
+			utils.Forbidden(w, "Forbidden: missing permission "+permission)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// keyAllows reports whether the API key the request was made with, if any,
+// carries permission.
+func keyAllows(r *http.Request, permission string) bool {
+	permissions, ok := utils.APIKeyPermissionsFromContext(r.Context())
+	return !ok || slices.Contains(permissions, permission)
+}
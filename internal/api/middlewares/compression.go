@@ -9,7 +9,8 @@ import (
 func Compression(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
-		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+		// Range responses must be byte ranges of the identity body
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") || r.Header.Get("Range") != "" {
 			next.ServeHTTP(w, r)
 			return
 		}
@@ -32,6 +33,13 @@ type gzipResponseWriter struct {
 	writer *gzip.Writer
 }
 
+// WriteHeader drops any Content-Length set for the uncompressed body, it no
+// longer matches what is sent.
+func (g *gzipResponseWriter) WriteHeader(code int) {
+	g.Header().Del("Content-Length")
+	g.ResponseWriter.WriteHeader(code)
+}
+
 func (g *gzipResponseWriter) Write(b []byte) (int, error) {
 	return g.writer.Write(b)
 }
\ No newline at end of file
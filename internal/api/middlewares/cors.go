@@ -14,6 +14,19 @@ func Cors(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		origin := r.Header.Get("Origin")
 
+		// requests from outside a browser, such as scripts and mobile apps,
+		// send no Origin
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// the OAuth login and consent pages post back to us
+		if origin == "http://"+r.Host || origin == "https://"+r.Host {
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		if !isOriginAllowed(origin) {
 			http.Error(w, "Origin not allowed", http.StatusForbidden)
 			return
@@ -21,7 +34,7 @@ func Cors(next http.Handler) http.Handler {
 
 		w.Header().Set("Access-Control-Allow-Origin", origin)
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-CSRF-Token")
 		fmt.Println(origin, isOriginAllowed(origin))
 		next.ServeHTTP(w, r)
 	})
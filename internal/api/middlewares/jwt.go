@@ -2,46 +2,89 @@ package middlewares
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"os"
-
-	"github.com/golang-jwt/jwt/v5"
+	"school-api/internal/repositeries/db"
+	"school-api/internal/repositeries/repo"
+	"school-api/pkg/rbac"
+	"school-api/pkg/utils"
+	"strconv"
+	"strings"
 )
 
 func JWTMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
-		var jwtSecret = os.Getenv("JWT_SECRET")
-
-		var hmacSampleSecret = []byte(jwtSecret)
+		if key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "ApiKey "); ok {
+			authenticateAPIKey(w, r, next, key)
+			return
+		}
 
-		cookie, err := r.Cookie("Bearer")
+		tokenStr, fromCookie := accessToken(r)
 
-		if err != nil {
+		if tokenStr == "" {
 			http.Error(w, "Unauthorized: token missing", http.StatusUnauthorized)
 			return
 		}
 
-		tokenStr := cookie.Value
+		// Parse and validate token against the key named by its kid
+		claims, err := utils.ParseToken(tokenStr)
 
-		// Parse and validate token
-		token, err := jwt.Parse(tokenStr, func(t *jwt.Token) (any, error) {
-			// Ensure token method is HMAC
-			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return hmacSampleSecret, nil
-		})
+		if err != nil {
+			http.Error(w, "Unauthorized: invalid token", http.StatusUnauthorized)
+			return
+		}
 
-		if err != nil || !token.Valid {
+		// challenge tokens from the first login step are not access tokens
+		if _, ok := claims["typ"]; ok {
 			http.Error(w, "Unauthorized: invalid token", http.StatusUnauthorized)
 			return
 		}
-		claims, ok := token.Claims.(jwt.MapClaims)
 
-		if !ok {
+		// the token is only good while its session is live and no password
+		// change or deactivation has happened since it was issued
+		sid, _ := claims["sid"].(string)
+		userId, _ := strconv.Atoi(fmt.Sprint(claims["uid"]))
+		if sid == "" {
+			http.Error(w, "Unauthorized: session missing", http.StatusUnauthorized)
+			return
+		}
+
+		// a browser sends the cookie on cross-site requests too, so
+		// changes made with it must echo the session's CSRF token
+		if fromCookie && !isSafeMethod(r.Method) && !utils.ValidCSRFToken(sid, r.Header.Get(utils.CSRFHeader)) {
+			utils.Forbidden(w, "Forbidden: CSRF token missing or invalid")
+			return
+		}
+
+		issuedAt, err := claims.GetIssuedAt()
+		if err != nil || issuedAt == nil {
+			http.Error(w, "Unauthorized: token has no issue time", http.StatusUnauthorized)
+			return
+		}
+
+		conn, err := db.New()
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		active, err := repo.IsTokenValid(conn.DB, sid, userId, issuedAt.Time)
+		conn.Close()
+
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		} else if !active {
+			http.Error(w, "Unauthorized: token revoked or expired", http.StatusUnauthorized)
+			return
+		}
 
-			http.Error(w, "Unauthorized: Unable to extract", http.StatusUnauthorized)
+		// roles that must use 2FA can only set it up until they have
+		role, _ := claims["role"].(string)
+		mfa, _ := claims["mfa"].(bool)
+		if !mfa && rbac.RequiresTwoFactor(role) && !isTwoFactorSetupPath(r.URL.Path) {
+			utils.Forbidden(w, "Forbidden: two-factor authentication must be enabled for this account")
 			return
 		}
 
@@ -49,7 +92,38 @@ func JWTMiddleware(next http.Handler) http.Handler {
 
 		ctx = context.WithValue(ctx, "userId", claims["uid"])
 		ctx = context.WithValue(ctx, "username", claims["user"])
+		ctx = context.WithValue(ctx, "sid", sid)
+		ctx = context.WithValue(ctx, "mfa", mfa)
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+func isTwoFactorSetupPath(path string) bool {
+	return strings.HasPrefix(path, "/execs/me/2fa")
+}
+
+// accessToken takes the token from the Authorization: Bearer header or the
+// Bearer cookie. When both are sent the header wins, unless
+// AUTH_TOKEN_PRECEDENCE is "cookie". Other Authorization schemes are
+// ignored.
+func accessToken(r *http.Request) (string, bool) {
+	var header string
+	if h, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		header = strings.TrimSpace(h)
+	}
+
+	var cookie string
+	if c, err := r.Cookie("Bearer"); err == nil {
+		cookie = c.Value
+	}
+
+	if cookie != "" && (header == "" || os.Getenv("AUTH_TOKEN_PRECEDENCE") == "cookie") {
+		return cookie, true
+	}
+	return header, false
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
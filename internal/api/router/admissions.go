@@ -0,0 +1,22 @@
+package router
+
+import (
+	"net/http"
+	"school-api/internal/api/handlers"
+	mw "school-api/internal/api/middlewares"
+)
+
+func RegisterAdmissionsRoutes(mux *http.ServeMux) {
+
+	// Applications
+	mux.HandleFunc("GET /admissions", mw.Authorize("admissions:read", handlers.GetApplicationsHandler))
+	mux.HandleFunc("POST /admissions", mw.Authorize("admissions:create", handlers.AddApplicationHandler))
+	mux.HandleFunc("GET /admissions/{id}", mw.Authorize("admissions:read", handlers.GetApplicationByIdHandler))
+	mux.HandleFunc("PUT /admissions/{id}", mw.Authorize("admissions:update", handlers.UpdateApplicationHandler))
+	mux.HandleFunc("DELETE /admissions/{id}", mw.Authorize("admissions:delete", handlers.DeleteApplicationHandler))
+
+	// Pipeline
+	mux.HandleFunc("PUT /admissions/{id}/status", mw.Authorize("admissions:update", handlers.ChangeApplicationStatusHandler))
+	mux.HandleFunc("POST /admissions/{id}/notes", mw.Authorize("admissions:update", handlers.AddApplicationNoteHandler))
+	mux.HandleFunc("POST /admissions/{id}/enroll", mw.Authorize("admissions:update", handlers.EnrollApplicationHandler))
+}
@@ -0,0 +1,23 @@
+package router
+
+import (
+	"net/http"
+	"school-api/internal/api/handlers"
+	mw "school-api/internal/api/middlewares"
+)
+
+func RegisterAttachmentsRoutes(mux *http.ServeMux) {
+
+	// Upload and list per owner
+	mux.HandleFunc("POST /students/{id}/attachments", mw.Authorize("attachments:create", handlers.UploadStudentAttachmentHandler))
+	mux.HandleFunc("GET /students/{id}/attachments", mw.Authorize("attachments:read", handlers.GetStudentAttachmentsHandler))
+	mux.HandleFunc("POST /teachers/{id}/attachments", mw.Authorize("attachments:create", handlers.UploadTeacherAttachmentHandler))
+	mux.HandleFunc("GET /teachers/{id}/attachments", mw.Authorize("attachments:read", handlers.GetTeacherAttachmentsHandler))
+	mux.HandleFunc("POST /execs/{id}/attachments", mw.Authorize("attachments:create", handlers.UploadExecAttachmentHandler))
+	mux.HandleFunc("GET /execs/{id}/attachments", mw.Authorize("attachments:read", handlers.GetExecAttachmentsHandler))
+
+	// Single attachment
+	mux.HandleFunc("GET /attachments/{id}", mw.Authorize("attachments:read", handlers.GetAttachmentHandler))
+	mux.HandleFunc("GET /attachments/{id}/download", mw.Authorize("attachments:read", handlers.DownloadAttachmentHandler))
+	mux.HandleFunc("DELETE /attachments/{id}", mw.Authorize("attachments:delete", handlers.DeleteAttachmentHandler))
+}
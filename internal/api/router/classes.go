@@ -0,0 +1,28 @@
+package router
+
+import (
+	"net/http"
+	"school-api/internal/api/handlers"
+	mw "school-api/internal/api/middlewares"
+)
+
+func RegisterClassesRoutes(mux *http.ServeMux) {
+
+	// Collection routes
+	mux.HandleFunc("GET /classes", mw.Authorize("classes:read", handlers.GetClassesHandler))
+	mux.HandleFunc("POST /classes", mw.Authorize("classes:create", handlers.AddClassHandler))
+
+	// Single class routes
+	mux.HandleFunc("GET /classes/{id}", mw.Authorize("classes:read", handlers.GetClassByIdHandler))
+	mux.HandleFunc("PUT /classes/{id}", mw.Authorize("classes:update", handlers.UpdateClassHandler))
+	mux.HandleFunc("DELETE /classes/{id}", mw.Authorize("classes:delete", handlers.DeleteClassHandler))
+
+	// Members
+	mux.HandleFunc("GET /classes/{id}/students", mw.Authorize("classes:read", handlers.GetClassStudentsHandler))
+	mux.HandleFunc("GET /classes/{id}/teachers", mw.Authorize("classes:read", handlers.GetClassTeachersHandler))
+
+	// Attendance
+	mux.HandleFunc("POST /classes/{id}/attendance", mw.Authorize("attendance:create", handlers.MarkClassAttendanceHandler))
+	mux.HandleFunc("GET /classes/{id}/attendance", mw.Authorize("attendance:read", handlers.GetClassAttendanceHandler))
+	mux.HandleFunc("GET /classes/{id}/attendance/summary", mw.Authorize("attendance:read", handlers.GetClassAttendanceSummaryHandler))
+}
@@ -0,0 +1,25 @@
+package router
+
+import (
+	"net/http"
+	"school-api/internal/api/handlers"
+	mw "school-api/internal/api/middlewares"
+)
+
+func RegisterExamsRoutes(mux *http.ServeMux) {
+
+	// Exam sessions
+	mux.HandleFunc("GET /exams", mw.Authorize("exams:read", handlers.GetExamsHandler))
+	mux.HandleFunc("POST /exams", mw.Authorize("exams:create", handlers.AddExamHandler))
+	mux.HandleFunc("GET /exams/{id}", mw.Authorize("exams:read", handlers.GetExamByIdHandler))
+	mux.HandleFunc("PUT /exams/{id}", mw.Authorize("exams:update", handlers.UpdateExamHandler))
+	mux.HandleFunc("DELETE /exams/{id}", mw.Authorize("exams:delete", handlers.DeleteExamHandler))
+
+	// Invigilators
+	mux.HandleFunc("POST /exams/{id}/invigilators", mw.Authorize("exams:update", handlers.AssignInvigilatorHandler))
+	mux.HandleFunc("DELETE /exams/{id}/invigilators/{teacherId}", mw.Authorize("exams:update", handlers.RemoveInvigilatorHandler))
+
+	// Seating
+	mux.HandleFunc("GET /exams/{id}/seating", mw.Authorize("exams:read", handlers.GetSeatingPlanHandler))
+	mux.HandleFunc("POST /exams/{id}/seating", mw.Authorize("exams:update", handlers.GenerateSeatingPlanHandler))
+}
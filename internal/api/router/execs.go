@@ -3,22 +3,42 @@ package router
 import (
 	"net/http"
 	"school-api/internal/api/handlers"
+	mw "school-api/internal/api/middlewares"
 )
 
 func RegisterExecRoutes(mux *http.ServeMux) {
 
 	// Collection routes
-	mux.HandleFunc("GET /execs", handlers.GetExecsHandler)
-	mux.HandleFunc("POST /execs", handlers.AddExecHandler)
+	mux.HandleFunc("GET /execs", mw.Authorize("execs:read", handlers.GetExecsHandler))
+	mux.HandleFunc("POST /execs", mw.Authorize("execs:create", handlers.AddExecHandler))
+
+	// Current exec
+	mux.HandleFunc("GET /execs/me/permissions", handlers.GetMyPermissionsHandler)
+	mux.HandleFunc("GET /execs/me/sessions", handlers.GetMySessionsHandler)
+	mux.HandleFunc("DELETE /execs/me/sessions", handlers.RevokeMySessionsHandler)
+	mux.HandleFunc("DELETE /execs/me/sessions/{sid}", handlers.RevokeMySessionHandler)
+	mux.HandleFunc("GET /execs/me/2fa", handlers.GetTwoFactorStatusHandler)
+	mux.HandleFunc("POST /execs/me/2fa/enroll", handlers.EnrollTwoFactorHandler)
+	mux.HandleFunc("POST /execs/me/2fa/verify", handlers.VerifyTwoFactorHandler)
+	mux.HandleFunc("POST /execs/me/2fa/disable", handlers.DisableTwoFactorHandler)
+	mux.HandleFunc("POST /execs/me/2fa/recovery-codes", handlers.RegenerateRecoveryCodesHandler)
 
 	// Single exec routes
-	mux.HandleFunc("GET /execs/{id}", handlers.GetExecByIdHandler)
-	mux.HandleFunc("PUT /execs/{id}", handlers.UpdateExecHandler)
-	mux.HandleFunc("DELETE /execs/{id}", handlers.DeleteExecHandler)
+	mux.HandleFunc("GET /execs/{id}", mw.Authorize("execs:read", handlers.GetExecByIdHandler))
+	mux.HandleFunc("PUT /execs/{id}", mw.Authorize("execs:update", handlers.UpdateExecHandler))
+	mux.HandleFunc("DELETE /execs/{id}", mw.Authorize("execs:delete", handlers.DeleteExecHandler))
+
+	// API keys
+	mux.HandleFunc("GET /execs/{id}/api-keys", mw.AuthorizeSelfOr("execs:read", handlers.GetAPIKeysHandler))
+	mux.HandleFunc("POST /execs/{id}/api-keys", mw.AuthorizeSelfOr("execs:update", handlers.CreateAPIKeyHandler))
+	mux.HandleFunc("DELETE /execs/{id}/api-keys/{keyId}", mw.AuthorizeSelfOr("execs:update", handlers.RevokeAPIKeyHandler))
 
 	// Password & auth routes
-	mux.HandleFunc("POST /execs/{id}/updatePassword", handlers.UpdatePasswordHandler)
+	mux.HandleFunc("POST /execs/{id}/updatePassword", mw.AuthorizeSelfOr("execs:update", handlers.UpdatePasswordHandler))
+	mux.HandleFunc("POST /execs/{id}/unlock", mw.Authorize("execs:update", handlers.UnlockExecHandler))
 	mux.HandleFunc("POST /execs/login", handlers.LoginHandler)
+	mux.HandleFunc("POST /execs/login/2fa", handlers.LoginTwoFactorHandler)
+	mux.HandleFunc("POST /execs/refresh", handlers.RefreshHandler)
 	mux.HandleFunc("POST /execs/logout", handlers.LogoutHandler)
 	mux.HandleFunc("POST /execs/forgotPassword", handlers.ForgotPasswordHandler)
 	mux.HandleFunc("POST /execs/reset/password/{resetcode}", handlers.ResetPasswordHandler)
@@ -0,0 +1,33 @@
+package router
+
+import (
+	"net/http"
+	"school-api/internal/api/handlers"
+	mw "school-api/internal/api/middlewares"
+)
+
+func RegisterFeesRoutes(mux *http.ServeMux) {
+
+	// Fee structures
+	mux.HandleFunc("GET /fees/structures", mw.Authorize("fees:read", handlers.GetFeeStructuresHandler))
+	mux.HandleFunc("POST /fees/structures", mw.Authorize("fees:create", handlers.AddFeeStructureHandler))
+	mux.HandleFunc("GET /fees/structures/{id}", mw.Authorize("fees:read", handlers.GetFeeStructureByIdHandler))
+	mux.HandleFunc("PUT /fees/structures/{id}", mw.Authorize("fees:update", handlers.UpdateFeeStructureHandler))
+	mux.HandleFunc("DELETE /fees/structures/{id}", mw.Authorize("fees:delete", handlers.DeleteFeeStructureHandler))
+
+	// Discounts and scholarships
+	mux.HandleFunc("GET /students/{id}/discounts", mw.Authorize("fees:read", handlers.GetStudentDiscountsHandler))
+	mux.HandleFunc("POST /students/{id}/discounts", mw.Authorize("fees:create", handlers.AddStudentDiscountHandler))
+	mux.HandleFunc("DELETE /fees/discounts/{id}", mw.Authorize("fees:delete", handlers.DeleteDiscountHandler))
+
+	// Invoices and payments
+	mux.HandleFunc("GET /fees/invoices", mw.Authorize("fees:read", handlers.GetInvoicesHandler))
+	mux.HandleFunc("POST /fees/invoices/generate", mw.Authorize("fees:create", handlers.GenerateInvoicesHandler))
+	mux.HandleFunc("GET /fees/invoices/{id}", mw.Authorize("fees:read", handlers.GetInvoiceByIdHandler))
+	mux.HandleFunc("POST /fees/invoices/{id}/payments", mw.Authorize("fees:create", handlers.RecordPaymentHandler))
+	mux.HandleFunc("POST /fees/invoices/{id}/void", mw.Authorize("fees:delete", handlers.VoidInvoiceHandler))
+
+	// Reports
+	mux.HandleFunc("GET /fees/ledger", mw.Authorize("fees:read", handlers.GetLedgerHandler))
+	mux.HandleFunc("GET /fees/balances", mw.Authorize("fees:read", handlers.GetBalancesHandler))
+}
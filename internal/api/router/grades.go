@@ -0,0 +1,29 @@
+package router
+
+import (
+	"net/http"
+	"school-api/internal/api/handlers"
+	mw "school-api/internal/api/middlewares"
+)
+
+func RegisterGradesRoutes(mux *http.ServeMux) {
+
+	// Assessments
+	mux.HandleFunc("GET /assessments", mw.Authorize("grades:read", handlers.GetAssessmentsHandler))
+	mux.HandleFunc("POST /assessments", mw.Authorize("grades:create", handlers.AddAssessmentHandler))
+	mux.HandleFunc("GET /assessments/{id}", mw.Authorize("grades:read", handlers.GetAssessmentByIdHandler))
+	mux.HandleFunc("PUT /assessments/{id}", mw.Authorize("grades:update", handlers.UpdateAssessmentHandler))
+	mux.HandleFunc("DELETE /assessments/{id}", mw.Authorize("grades:delete", handlers.DeleteAssessmentHandler))
+
+	// Scores
+	mux.HandleFunc("GET /assessments/{id}/scores", mw.Authorize("grades:read", handlers.GetScoresHandler))
+	mux.HandleFunc("POST /assessments/{id}/scores", mw.Authorize("grades:update", handlers.SaveScoresHandler))
+
+	// Weighted averages
+	mux.HandleFunc("GET /students/{id}/grades", mw.Authorize("grades:read", handlers.GetStudentGradesHandler))
+
+	// Report cards and transcripts (JSON, or PDF with Accept: application/pdf)
+	mux.HandleFunc("GET /students/{id}/report-card", mw.Authorize("grades:read", handlers.GetReportCardHandler))
+	mux.HandleFunc("POST /students/{id}/report-card/comments", mw.Authorize("grades:create", handlers.AddReportCommentHandler))
+	mux.HandleFunc("GET /students/{id}/transcript", mw.Authorize("grades:read", handlers.GetTranscriptHandler))
+}
@@ -0,0 +1,27 @@
+package router
+
+import (
+	"net/http"
+	"school-api/internal/api/handlers"
+	mw "school-api/internal/api/middlewares"
+)
+
+func RegisterGuardiansRoutes(mux *http.ServeMux) {
+
+	// Collection routes
+	mux.HandleFunc("GET /guardians", mw.Authorize("guardians:read", handlers.GetGuardiansHandler))
+	mux.HandleFunc("POST /guardians", mw.Authorize("guardians:create", handlers.AddGuardianHandler))
+
+	// Single guardian routes
+	mux.HandleFunc("GET /guardians/{id}", mw.Authorize("guardians:read", handlers.GetGuardianByIdHandler))
+	mux.HandleFunc("PUT /guardians/{id}", mw.Authorize("guardians:update", handlers.UpdateGuardianHandler))
+	mux.HandleFunc("DELETE /guardians/{id}", mw.Authorize("guardians:delete", handlers.DeleteGuardianHandler))
+	mux.HandleFunc("GET /guardians/{id}/students", mw.Authorize("guardians:read", handlers.GetGuardianStudentsHandler))
+
+	// Student links
+	mux.HandleFunc("GET /students/{id}/guardians", mw.Authorize("guardians:read", handlers.GetStudentGuardiansHandler))
+	mux.HandleFunc("POST /students/{id}/guardians", mw.Authorize("guardians:update", handlers.LinkStudentGuardianHandler))
+	mux.HandleFunc("PUT /students/{id}/guardians/{guardianId}", mw.Authorize("guardians:update", handlers.UpdateStudentGuardianHandler))
+	mux.HandleFunc("DELETE /students/{id}/guardians/{guardianId}", mw.Authorize("guardians:update", handlers.UnlinkStudentGuardianHandler))
+	mux.HandleFunc("POST /students/{id}/guardians/notify", mw.Authorize("guardians:update", handlers.NotifyStudentGuardiansHandler))
+}
@@ -0,0 +1,24 @@
+package router
+
+import (
+	"net/http"
+	"school-api/internal/api/handlers"
+	mw "school-api/internal/api/middlewares"
+)
+
+func RegisterHomeworkRoutes(mux *http.ServeMux) {
+
+	// Homework
+	mux.HandleFunc("GET /homework", mw.Authorize("homework:read", handlers.GetHomeworkListHandler))
+	mux.HandleFunc("POST /homework", mw.Authorize("homework:create", handlers.AddHomeworkHandler))
+	mux.HandleFunc("GET /homework/{id}", mw.Authorize("homework:read", handlers.GetHomeworkByIdHandler))
+	mux.HandleFunc("PUT /homework/{id}", mw.Authorize("homework:update", handlers.UpdateHomeworkHandler))
+	mux.HandleFunc("DELETE /homework/{id}", mw.Authorize("homework:delete", handlers.DeleteHomeworkHandler))
+
+	// Submissions
+	mux.HandleFunc("GET /homework/{id}/submissions", mw.Authorize("homework:read", handlers.GetSubmissionsHandler))
+	mux.HandleFunc("GET /homework/{id}/submissions/outstanding", mw.Authorize("homework:read", handlers.GetOutstandingSubmissionsHandler))
+	mux.HandleFunc("POST /homework/{id}/submissions", mw.Authorize("homework:update", handlers.SubmitHomeworkHandler))
+	mux.HandleFunc("PUT /homework/{id}/submissions/{studentId}/grade", mw.Authorize("homework:update", handlers.GradeSubmissionHandler))
+	mux.HandleFunc("POST /homework/{id}/publish-grades", mw.Authorize("grades:create", handlers.PublishHomeworkGradesHandler))
+}
@@ -0,0 +1,23 @@
+package router
+
+import (
+	"net/http"
+	"school-api/internal/api/handlers"
+	mw "school-api/internal/api/middlewares"
+)
+
+func RegisterOAuthRoutes(mux *http.ServeMux) {
+
+	// Authorization server
+	mux.HandleFunc("GET /oauth/authorize", handlers.AuthorizeHandler)
+	mux.HandleFunc("POST /oauth/authorize", handlers.AuthorizeLoginHandler)
+	mux.HandleFunc("POST /oauth/authorize/consent", handlers.ConsentHandler)
+	mux.HandleFunc("POST /oauth/token", handlers.TokenHandler)
+	mux.HandleFunc("GET /oauth/userinfo", handlers.UserinfoHandler)
+	mux.HandleFunc("POST /oauth/userinfo", handlers.UserinfoHandler)
+
+	// Clients
+	mux.HandleFunc("GET /oauth/clients", mw.Authorize("oauth_clients:read", handlers.GetOAuthClientsHandler))
+	mux.HandleFunc("POST /oauth/clients", mw.Authorize("oauth_clients:create", handlers.CreateOAuthClientHandler))
+	mux.HandleFunc("DELETE /oauth/clients/{id}", mw.Authorize("oauth_clients:delete", handlers.RevokeOAuthClientHandler))
+}
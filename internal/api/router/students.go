@@ -3,15 +3,17 @@ package router
 import (
 	"net/http"
 	"school-api/internal/api/handlers"
+	mw "school-api/internal/api/middlewares"
 )
 
 func RegisterStudentsRoutes (mux *http.ServeMux){
 
-	mux.HandleFunc("GET /students", handlers.GetStudentsHandler)
-	mux.HandleFunc("GET /students/{id}", handlers.GetStudentByIdHandler)
-	mux.HandleFunc("POST /students", handlers.AddStudentHandler)
-	mux.HandleFunc("PUT /students/{id}", handlers.UpdateStudentHandler)
-	mux.HandleFunc("DELETE /students/{id}", handlers.DeleteStudentHandler)
-	mux.HandleFunc("GET /students/teachers", handlers.GetStudentOfTeachers)
+	mux.HandleFunc("GET /students", mw.Authorize("students:read", handlers.GetStudentsHandler))
+	mux.HandleFunc("GET /students/{id}", mw.Authorize("students:read", handlers.GetStudentByIdHandler))
+	mux.HandleFunc("POST /students", mw.Authorize("students:create", handlers.AddStudentHandler))
+	mux.HandleFunc("PUT /students/{id}", mw.Authorize("students:update", handlers.UpdateStudentHandler))
+	mux.HandleFunc("DELETE /students/{id}", mw.Authorize("students:delete", handlers.DeleteStudentHandler))
+	mux.HandleFunc("GET /students/teachers", mw.Authorize("students:read", handlers.GetStudentOfTeachers))
+	mux.HandleFunc("GET /students/{id}/attendance", mw.Authorize("attendance:read", handlers.GetStudentAttendanceHandler))
 
 }
\ No newline at end of file
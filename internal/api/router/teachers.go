@@ -3,15 +3,21 @@ package router
 import (
 	"net/http"
 	"school-api/internal/api/handlers"
+	mw "school-api/internal/api/middlewares"
 )
 
 func RegisterTeachersRoutes (mux *http.ServeMux){
 
-	mux.HandleFunc("GET /teachers", handlers.GetTeachersHandler)
-	mux.HandleFunc("GET /teachers/{id}", handlers.GetTeacherByIdHandler)
-	mux.HandleFunc("POST /teachers", handlers.AddTeacherHandler)
-	mux.HandleFunc("PUT /teachers/{id}", handlers.UpdateTeacherHandler)
-	mux.HandleFunc("DELETE /teachers/{id}", handlers.DeleteTeacherHandler)
-	mux.HandleFunc("DELETE /teachers/bulk", handlers.DeleteMupltipleTeachersHandler)
+	mux.HandleFunc("GET /teachers", mw.Authorize("teachers:read", handlers.GetTeachersHandler))
+	mux.HandleFunc("GET /teachers/{id}", mw.Authorize("teachers:read", handlers.GetTeacherByIdHandler))
+	mux.HandleFunc("POST /teachers", mw.Authorize("teachers:create", handlers.AddTeacherHandler))
+	mux.HandleFunc("PUT /teachers/{id}", mw.Authorize("teachers:update", handlers.UpdateTeacherHandler))
+	mux.HandleFunc("DELETE /teachers/{id}", mw.Authorize("teachers:delete", handlers.DeleteTeacherHandler))
+	mux.HandleFunc("DELETE /teachers/bulk", mw.Authorize("teachers:delete", handlers.DeleteMupltipleTeachersHandler))
+
+	// Class and subject assignments
+	mux.HandleFunc("GET /teachers/{id}/assignments", mw.Authorize("teachers:read", handlers.GetTeacherAssignmentsHandler))
+	mux.HandleFunc("POST /teachers/{id}/assignments", mw.Authorize("teachers:update", handlers.AssignTeacherHandler))
+	mux.HandleFunc("DELETE /teachers/{id}/assignments/{assignmentId}", mw.Authorize("teachers:update", handlers.UnassignTeacherHandler))
 
 }
\ No newline at end of file
@@ -0,0 +1,30 @@
+package router
+
+import (
+	"net/http"
+	"school-api/internal/api/handlers"
+	mw "school-api/internal/api/middlewares"
+)
+
+func RegisterTermsRoutes(mux *http.ServeMux) {
+
+	// Academic years
+	mux.HandleFunc("GET /academic-years", mw.Authorize("terms:read", handlers.GetAcademicYearsHandler))
+	mux.HandleFunc("POST /academic-years", mw.Authorize("terms:create", handlers.AddAcademicYearHandler))
+	mux.HandleFunc("GET /academic-years/{id}", mw.Authorize("terms:read", handlers.GetAcademicYearByIdHandler))
+	mux.HandleFunc("PUT /academic-years/{id}", mw.Authorize("terms:update", handlers.UpdateAcademicYearHandler))
+	mux.HandleFunc("DELETE /academic-years/{id}", mw.Authorize("terms:delete", handlers.DeleteAcademicYearHandler))
+
+	// Terms
+	mux.HandleFunc("GET /terms", mw.Authorize("terms:read", handlers.GetTermsHandler))
+	mux.HandleFunc("POST /terms", mw.Authorize("terms:create", handlers.AddTermHandler))
+	mux.HandleFunc("GET /terms/current", mw.Authorize("terms:read", handlers.GetCurrentTermHandler))
+	mux.HandleFunc("GET /terms/{id}", mw.Authorize("terms:read", handlers.GetTermByIdHandler))
+	mux.HandleFunc("PUT /terms/{id}", mw.Authorize("terms:update", handlers.UpdateTermHandler))
+	mux.HandleFunc("DELETE /terms/{id}", mw.Authorize("terms:delete", handlers.DeleteTermHandler))
+	mux.HandleFunc("POST /terms/{id}/current", mw.Authorize("terms:update", handlers.SetCurrentTermHandler))
+	mux.HandleFunc("POST /terms/{id}/rollover", mw.Authorize("terms:update", handlers.RolloverHandler))
+
+	// Enrollment history
+	mux.HandleFunc("GET /students/{id}/enrollments", mw.Authorize("students:read", handlers.GetStudentEnrollmentsHandler))
+}
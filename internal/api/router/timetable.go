@@ -0,0 +1,22 @@
+package router
+
+import (
+	"net/http"
+	"school-api/internal/api/handlers"
+	mw "school-api/internal/api/middlewares"
+)
+
+func RegisterTimetableRoutes(mux *http.ServeMux) {
+
+	// Slots
+	mux.HandleFunc("GET /timetable", mw.Authorize("timetable:read", handlers.GetTimetableHandler))
+	mux.HandleFunc("POST /timetable", mw.Authorize("timetable:create", handlers.AddTimetableSlotHandler))
+	mux.HandleFunc("GET /timetable/{id}", mw.Authorize("timetable:read", handlers.GetTimetableSlotByIdHandler))
+	mux.HandleFunc("PUT /timetable/{id}", mw.Authorize("timetable:update", handlers.UpdateTimetableSlotHandler))
+	mux.HandleFunc("DELETE /timetable/{id}", mw.Authorize("timetable:delete", handlers.DeleteTimetableSlotHandler))
+
+	// Views, as JSON or .ics with ?format=ics or Accept: text/calendar
+	mux.HandleFunc("GET /classes/{id}/timetable", mw.Authorize("timetable:read", handlers.GetClassTimetableHandler))
+	mux.HandleFunc("GET /teachers/{id}/timetable", mw.Authorize("timetable:read", handlers.GetTeacherTimetableHandler))
+	mux.HandleFunc("GET /rooms/{room}/timetable", mw.Authorize("timetable:read", handlers.GetRoomTimetableHandler))
+}
@@ -0,0 +1,11 @@
+package router
+
+import (
+	"net/http"
+	"school-api/internal/api/handlers"
+)
+
+func RegisterWellKnownRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /.well-known/jwks.json", handlers.JWKSHandler)
+	mux.HandleFunc("GET /.well-known/openid-configuration", handlers.DiscoveryHandler)
+}
@@ -0,0 +1,62 @@
+package models
+
+const (
+	ApplicationSubmitted   = "submitted"
+	ApplicationUnderReview = "under_review"
+	ApplicationInterview   = "interview"
+	ApplicationAccepted    = "accepted"
+	ApplicationRejected    = "rejected"
+	ApplicationWaitlisted  = "waitlisted"
+	ApplicationEnrolled    = "enrolled"
+)
+
+// Application is a prospective student. ClassId and StudentId are set once
+// the application is enrolled.
+type Application struct {
+	ID               int                       `json:"id,omitempty"`
+	FirstName        string                    `json:"first_name,omitempty"`
+	LastName         string                    `json:"last_name,omitempty"`
+	Email            string                    `json:"email,omitempty"`
+	DateOfBirth      string                    `json:"date_of_birth,omitempty"`
+	GradeLevel       int                       `json:"grade_level,omitempty"`
+	PreferredClassId int                       `json:"preferred_class_id,omitempty"`
+	TermId           int                       `json:"term_id,omitempty"`
+	GuardianName     string                    `json:"guardian_name,omitempty"`
+	GuardianEmail    string                    `json:"guardian_email,omitempty"`
+	GuardianPhone    string                    `json:"guardian_phone,omitempty"`
+	Status           string                    `json:"status,omitempty"`
+	ClassId          int                       `json:"class_id,omitempty"`
+	StudentId        int                       `json:"student_id,omitempty"`
+	CreatedAt        string                    `json:"created_at,omitempty"`
+	UpdatedAt        string                    `json:"updated_at,omitempty"`
+	History          []ApplicationStatusChange `json:"history,omitempty"`
+	Notes            []ApplicationNote         `json:"notes,omitempty"`
+}
+
+type ApplicationStatusChange struct {
+	FromStatus string `json:"from_status,omitempty"`
+	ToStatus   string `json:"to_status"`
+	Note       string `json:"note,omitempty"`
+	ChangedBy  int    `json:"changed_by,omitempty"`
+	ChangedAt  string `json:"changed_at"`
+}
+
+type ApplicationNote struct {
+	ID        int    `json:"id,omitempty"`
+	Note      string `json:"note"`
+	AuthorId  int    `json:"author_id,omitempty"`
+	CreatedAt string `json:"created_at,omitempty"`
+}
+
+type ApplicationStatusRequest struct {
+	Status string `json:"status"`
+	Note   string `json:"note"`
+}
+
+// EnrollApplicationRequest optionally names the class to place the student
+// in; otherwise the preferred class or the least full class of the grade
+// level is used.
+type EnrollApplicationRequest struct {
+	ClassId int    `json:"class_id"`
+	Note    string `json:"note"`
+}
@@ -0,0 +1,31 @@
+package models
+
+// APIKey is a key as listed. Key holds the secret itself and is only
+// filled in the response that creates it.
+type APIKey struct {
+	ID          int      `json:"id"`
+	ExecId      int      `json:"exec_id"`
+	Name        string   `json:"name"`
+	Prefix      string   `json:"prefix"`
+	Permissions []string `json:"permissions"`
+	ExpiresAt   string   `json:"expires_at"`
+	LastUsedAt  string   `json:"last_used_at,omitempty"`
+	CreatedAt   string   `json:"created_at"`
+	RevokedAt   string   `json:"revoked_at,omitempty"`
+	Key         string   `json:"key,omitempty"`
+}
+
+type APIKeyRequest struct {
+	Name          string   `json:"name"`
+	Permissions   []string `json:"permissions"`
+	ExpiresInDays int      `json:"expires_in_days"`
+}
+
+// APIKeyIdentity is who a valid key authenticates as.
+type APIKeyIdentity struct {
+	KeyId       int
+	ExecId      int
+	Username    string
+	Role        string
+	Permissions []string
+}
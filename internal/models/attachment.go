@@ -0,0 +1,20 @@
+package models
+
+const (
+	OwnerStudent = "student"
+	OwnerTeacher = "teacher"
+	OwnerExec    = "exec"
+)
+
+type Attachment struct {
+	ID          int    `json:"id,omitempty"`
+	OwnerType   string `json:"owner_type"`
+	OwnerId     int    `json:"owner_id"`
+	Category    string `json:"category"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Checksum    string `json:"checksum"`
+	UploadedBy  int    `json:"uploaded_by,omitempty"`
+	CreatedAt   string `json:"created_at,omitempty"`
+}
@@ -0,0 +1,43 @@
+package models
+
+const (
+	AttendancePresent = "present"
+	AttendanceAbsent  = "absent"
+	AttendanceLate    = "late"
+	AttendanceExcused = "excused"
+)
+
+type Attendance struct {
+	ID          int    `json:"id,omitempty"`
+	StudentId   int    `json:"student_id"`
+	StudentName string `json:"student_name,omitempty"`
+	ClassId     int    `json:"class_id"`
+	Date        string `json:"date"`
+	Period      int    `json:"period"`
+	Status      string `json:"status"`
+	Note        string `json:"note,omitempty"`
+	MarkedBy    int    `json:"marked_by,omitempty"`
+}
+
+type AttendanceRecord struct {
+	StudentId int    `json:"student_id"`
+	Status    string `json:"status"`
+	Note      string `json:"note"`
+}
+
+type BulkAttendanceRequest struct {
+	Date    string             `json:"date"`
+	Period  int                `json:"period"`
+	Records []AttendanceRecord `json:"records"`
+}
+
+type AttendanceSummary struct {
+	StudentId   int     `json:"student_id,omitempty"`
+	StudentName string  `json:"student_name,omitempty"`
+	Total       int     `json:"total"`
+	Present     int     `json:"present"`
+	Absent      int     `json:"absent"`
+	Late        int     `json:"late"`
+	Excused     int     `json:"excused"`
+	AbsenceRate float64 `json:"absence_rate"`
+}
@@ -0,0 +1,12 @@
+package models
+
+type Class struct {
+	ID                int      `json:"id,omitempty"`
+	Name              string   `json:"name,omitempty"`
+	GradeLevel        int      `json:"grade_level,omitempty"`
+	Room              string   `json:"room,omitempty"`
+	Capacity          int      `json:"capacity,omitempty"`
+	HomeroomTeacherId int      `json:"homeroom_teacher_id,omitempty"`
+	HomeroomTeacher   *Teacher `json:"homeroom_teacher,omitempty"`
+	StudentCount      int      `json:"student_count,omitempty"`
+}
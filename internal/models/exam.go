@@ -0,0 +1,35 @@
+package models
+
+// Exam is one sitting of a subject exam. ExamDate is YYYY-MM-DD and
+// StartTime is HH:MM.
+type Exam struct {
+	ID              int               `json:"id,omitempty"`
+	TermId          int               `json:"term_id,omitempty"`
+	Name            string            `json:"name,omitempty"`
+	Subject         string            `json:"subject,omitempty"`
+	ExamDate        string            `json:"exam_date,omitempty"`
+	StartTime       string            `json:"start_time,omitempty"`
+	DurationMinutes int               `json:"duration_minutes,omitempty"`
+	ClassIds        []int             `json:"class_ids,omitempty"`
+	Rooms           []ExamRoom        `json:"rooms,omitempty"`
+	Invigilators    []ExamInvigilator `json:"invigilators,omitempty"`
+}
+
+type ExamRoom struct {
+	Room     string `json:"room"`
+	Capacity int    `json:"capacity"`
+}
+
+type ExamInvigilator struct {
+	TeacherId   int    `json:"teacher_id"`
+	TeacherName string `json:"teacher_name,omitempty"`
+	Room        string `json:"room,omitempty"`
+}
+
+type ExamSeat struct {
+	StudentId   int    `json:"student_id"`
+	StudentName string `json:"student_name,omitempty"`
+	ClassId     int    `json:"class_id"`
+	Room        string `json:"room"`
+	SeatNumber  int    `json:"seat_number"`
+}
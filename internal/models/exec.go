@@ -19,6 +19,9 @@ type Exec struct {
 
 	Inactive bool   `json:"inactive"`
 	Role     string `json:"role"`
+
+	// service accounts sign in with API keys only
+	ServiceAccount bool `json:"service_account"`
 }
 
 type UpdatePasswordRequest struct {
@@ -29,4 +32,10 @@ type UpdatePasswordRequest struct {
 type UpdatePasswordResponse struct {
 	Token string `json:"token"`
 	PasswordUpdated bool `json:"password_updated"`
-}
\ No newline at end of file
+}
+type ExecPermissions struct {
+	ID          int      `json:"id"`
+	Username    string   `json:"username"`
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+}
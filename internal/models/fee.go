@@ -0,0 +1,113 @@
+package models
+
+// Money fields are integer minor units (e.g. cents).
+
+const (
+	InvoiceOpen = "open"
+	InvoicePaid = "paid"
+	InvoiceVoid = "void"
+
+	LedgerCharge   = "charge"
+	LedgerDiscount = "discount"
+	LedgerPayment  = "payment"
+	LedgerVoid     = "void"
+
+	DiscountPercent = "percent"
+	DiscountFixed   = "fixed"
+)
+
+type FeeStructure struct {
+	ID      int    `json:"id,omitempty"`
+	TermId  int    `json:"term_id,omitempty"`
+	ClassId int    `json:"class_id,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Amount  int64  `json:"amount,omitempty"`
+}
+
+// Discount is a discount or scholarship. For percent discounts Value is in
+// basis points (2500 = 25%).
+type Discount struct {
+	ID        int    `json:"id,omitempty"`
+	StudentId int    `json:"student_id,omitempty"`
+	TermId    int    `json:"term_id,omitempty"`
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	Value     int64  `json:"value"`
+	CreatedAt string `json:"created_at,omitempty"`
+}
+
+type Invoice struct {
+	ID          int           `json:"id,omitempty"`
+	StudentId   int           `json:"student_id"`
+	StudentName string        `json:"student_name,omitempty"`
+	TermId      int           `json:"term_id"`
+	ClassId     int           `json:"class_id"`
+	Status      string        `json:"status"`
+	DueDate     string        `json:"due_date,omitempty"`
+	IssuedAt    string        `json:"issued_at,omitempty"`
+	Total       int64         `json:"total"`
+	Paid        int64         `json:"paid"`
+	Outstanding int64         `json:"outstanding"`
+	Lines       []InvoiceLine `json:"lines,omitempty"`
+	Payments    []Payment     `json:"payments,omitempty"`
+}
+
+type InvoiceLine struct {
+	ID          int    `json:"id,omitempty"`
+	Description string `json:"description"`
+	Amount      int64  `json:"amount"`
+}
+
+type Payment struct {
+	ID         int    `json:"id,omitempty"`
+	InvoiceId  int    `json:"invoice_id,omitempty"`
+	Amount     int64  `json:"amount"`
+	Method     string `json:"method"`
+	Reference  string `json:"reference,omitempty"`
+	ReceivedAt string `json:"received_at,omitempty"`
+	RecordedBy int    `json:"recorded_by,omitempty"`
+}
+
+type LedgerEntry struct {
+	ID          int    `json:"id"`
+	InvoiceId   int    `json:"invoice_id"`
+	StudentId   int    `json:"student_id"`
+	Type        string `json:"type"`
+	Amount      int64  `json:"amount"`
+	Description string `json:"description"`
+	PaymentId   int    `json:"payment_id,omitempty"`
+	CreatedBy   int    `json:"created_by,omitempty"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// GenerateInvoicesRequest selects the students to invoice for a term: the
+// listed students, else every student of the class, else every active
+// student.
+type GenerateInvoicesRequest struct {
+	TermId     int    `json:"term_id"`
+	ClassId    int    `json:"class_id"`
+	StudentIds []int  `json:"student_ids"`
+	DueDate    string `json:"due_date"`
+}
+
+type GenerateInvoicesResult struct {
+	Invoices []Invoice `json:"invoices"`
+	Skipped  []int     `json:"skipped_student_ids"`
+}
+
+type VoidInvoiceRequest struct {
+	Reason string `json:"reason"`
+}
+
+// BalanceRow is one line of a balance report grouped by student, class or
+// term.
+type BalanceRow struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Invoices    int    `json:"invoices"`
+	Charged     int64  `json:"charged"`
+	Discounts   int64  `json:"discounts"`
+	Paid        int64  `json:"paid"`
+	Voided      int64  `json:"voided"`
+	Outstanding int64  `json:"outstanding"`
+}
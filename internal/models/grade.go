@@ -0,0 +1,37 @@
+package models
+
+type Assessment struct {
+	ID        int     `json:"id,omitempty"`
+	ClassId   int     `json:"class_id,omitempty"`
+	Subject   string  `json:"subject,omitempty"`
+	Title     string  `json:"title,omitempty"`
+	Type      string  `json:"type,omitempty"`
+	MaxScore  float64 `json:"max_score,omitempty"`
+	Weight    float64 `json:"weight,omitempty"`
+	DueDate   string  `json:"due_date,omitempty"`
+	TermId    int     `json:"term_id,omitempty"`
+	CreatedAt string  `json:"created_at,omitempty"`
+}
+
+type Score struct {
+	ID           int     `json:"id,omitempty"`
+	AssessmentId int     `json:"assessment_id,omitempty"`
+	StudentId    int     `json:"student_id"`
+	StudentName  string  `json:"student_name,omitempty"`
+	Score        float64 `json:"score"`
+	Comment      string  `json:"comment,omitempty"`
+	GradedBy     int     `json:"graded_by,omitempty"`
+}
+
+type BulkScoreRequest struct {
+	Scores []Score `json:"scores"`
+}
+
+type SubjectAverage struct {
+	Subject         string  `json:"subject"`
+	TermId          int     `json:"term_id"`
+	Term            string  `json:"term"`
+	Average         float64 `json:"average"`
+	AssessmentCount int     `json:"assessment_count"`
+	TotalWeight     float64 `json:"total_weight"`
+}
@@ -0,0 +1,40 @@
+package models
+
+type Guardian struct {
+	ID        int    `json:"id,omitempty"`
+	FirstName string `json:"first_name,omitempty"`
+	LastName  string `json:"last_name,omitempty"`
+	Email     string `json:"email,omitempty"`
+	Phone     string `json:"phone,omitempty"`
+	AltPhone  string `json:"alt_phone,omitempty"`
+	Address   string `json:"address,omitempty"`
+}
+
+// StudentGuardian is the link between a student and a guardian. When
+// linking, Guardian may carry a new guardian to create instead of
+// GuardianId.
+type StudentGuardian struct {
+	StudentId          int       `json:"student_id,omitempty"`
+	GuardianId         int       `json:"guardian_id,omitempty"`
+	Relationship       string    `json:"relationship"`
+	IsPrimaryContact   bool      `json:"is_primary_contact"`
+	IsEmergencyContact bool      `json:"is_emergency_contact"`
+	CanPickup          bool      `json:"can_pickup"`
+	Guardian           *Guardian `json:"guardian,omitempty"`
+	Student            *Student  `json:"student,omitempty"`
+}
+
+// GuardianNotice is an email to a student's guardians. PrimaryOnly limits
+// it to the primary contacts.
+type GuardianNotice struct {
+	Subject     string `json:"subject"`
+	Message     string `json:"message"`
+	PrimaryOnly bool   `json:"primary_only"`
+}
+
+// GuardianNoticeResult lists who a notice was queued for, and who was
+// skipped for having no email.
+type GuardianNoticeResult struct {
+	Queued  []int `json:"queued"`
+	Skipped []int `json:"skipped"`
+}
@@ -0,0 +1,62 @@
+package models
+
+const (
+	SubmissionNotSubmitted = "not_submitted"
+	SubmissionSubmitted    = "submitted"
+	SubmissionGraded       = "graded"
+
+	// SubmissionLate is not a status but filters on Submission.Late.
+	SubmissionLate = "late"
+)
+
+type Homework struct {
+	ID           int                  `json:"id,omitempty"`
+	TermId       int                  `json:"term_id,omitempty"`
+	ClassId      int                  `json:"class_id,omitempty"`
+	Subject      string               `json:"subject,omitempty"`
+	Title        string               `json:"title,omitempty"`
+	Instructions string               `json:"instructions,omitempty"`
+	DueDate      string               `json:"due_date,omitempty"`
+	MaxScore     float64              `json:"max_score,omitempty"`
+	AssessmentId int                  `json:"assessment_id,omitempty"`
+	CreatedBy    int                  `json:"created_by,omitempty"`
+	CreatedAt    string               `json:"created_at,omitempty"`
+	Attachments  []HomeworkAttachment `json:"attachments,omitempty"`
+}
+
+type HomeworkAttachment struct {
+	ID   int    `json:"id,omitempty"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// Submission is one student's work on a homework. Score is nil until
+// graded.
+type Submission struct {
+	ID          int      `json:"id,omitempty"`
+	HomeworkId  int      `json:"homework_id,omitempty"`
+	StudentId   int      `json:"student_id"`
+	StudentName string   `json:"student_name,omitempty"`
+	Status      string   `json:"status"`
+	Late        bool     `json:"late"`
+	Content     string   `json:"content,omitempty"`
+	FileURL     string   `json:"file_url,omitempty"`
+	SubmittedAt string   `json:"submitted_at,omitempty"`
+	Score       *float64 `json:"score,omitempty"`
+	Feedback    string   `json:"feedback,omitempty"`
+	GradedBy    int      `json:"graded_by,omitempty"`
+	GradedAt    string   `json:"graded_at,omitempty"`
+}
+
+// HomeworkPublishResult reports a publish. Skipped lists students whose
+// graded work was left out because they are no longer in the class.
+type HomeworkPublishResult struct {
+	Assessment *Assessment `json:"assessment"`
+	Published  int         `json:"published"`
+	Skipped    []int       `json:"skipped"`
+}
+
+type GradeSubmissionRequest struct {
+	Score    float64 `json:"score"`
+	Feedback string  `json:"feedback"`
+}
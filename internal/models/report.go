@@ -0,0 +1,37 @@
+package models
+
+type ReportComment struct {
+	ID         int    `json:"id,omitempty"`
+	StudentId  int    `json:"student_id,omitempty"`
+	TermId     int    `json:"term_id"`
+	Subject    string `json:"subject,omitempty"`
+	Comment    string `json:"comment"`
+	AuthorId   int    `json:"author_id,omitempty"`
+	AuthorName string `json:"author_name,omitempty"`
+	CreatedAt  string `json:"created_at,omitempty"`
+}
+
+type ReportCard struct {
+	Student        *Student          `json:"student"`
+	Term           *Term             `json:"term"`
+	Subjects       []SubjectAverage  `json:"subjects"`
+	OverallAverage float64           `json:"overall_average"`
+	Attendance     AttendanceSummary `json:"attendance"`
+	Comments       []ReportComment   `json:"comments"`
+	GeneratedAt    string            `json:"generated_at"`
+}
+
+type TranscriptTerm struct {
+	TermId   int              `json:"term_id"`
+	Term     string           `json:"term"`
+	Subjects []SubjectAverage `json:"subjects"`
+	Average  float64          `json:"average"`
+}
+
+type Transcript struct {
+	Student           *Student          `json:"student"`
+	Terms             []TranscriptTerm  `json:"terms"`
+	CumulativeAverage float64           `json:"cumulative_average"`
+	Attendance        AttendanceSummary `json:"attendance"`
+	GeneratedAt       string            `json:"generated_at"`
+}
@@ -0,0 +1,52 @@
+package models
+
+const (
+	StudentActive         = "active"
+	StudentGraduated      = "graduated"
+	StudentTransferredOut = "transferred_out"
+
+	RolloverPromote     = "promote"
+	RolloverRetain      = "retain"
+	RolloverGraduate    = "graduate"
+	RolloverTransferOut = "transfer_out"
+)
+
+// RolloverRequest moves students into ToTermId. ClassMapping maps a source
+// class to its next class; mapping to 0 graduates the class. Students in
+// unmapped classes are retained.
+type RolloverRequest struct {
+	FromTermId   int                `json:"from_term_id"`
+	ClassMapping map[int]int        `json:"class_mapping"`
+	Overrides    []RolloverOverride `json:"overrides"`
+	DryRun       bool               `json:"dry_run"`
+	SetCurrent   bool               `json:"set_current"`
+}
+
+// RolloverOverride replaces the mapped action for one student. ClassId is
+// only used with the promote action, to send the student somewhere other
+// than the mapped class.
+type RolloverOverride struct {
+	StudentId int    `json:"student_id"`
+	Action    string `json:"action"`
+	ClassId   int    `json:"class_id"`
+}
+
+type RolloverMove struct {
+	StudentId   int    `json:"student_id"`
+	StudentName string `json:"student_name"`
+	FromClassId int    `json:"from_class_id"`
+	ToClassId   int    `json:"to_class_id,omitempty"`
+	Action      string `json:"action"`
+}
+
+type RolloverReport struct {
+	DryRun         bool           `json:"dry_run"`
+	FromTermId     int            `json:"from_term_id"`
+	ToTermId       int            `json:"to_term_id"`
+	Promoted       int            `json:"promoted"`
+	Retained       int            `json:"retained"`
+	Graduated      int            `json:"graduated"`
+	TransferredOut int            `json:"transferred_out"`
+	Moves          []RolloverMove `json:"moves"`
+	Warnings       []string       `json:"warnings,omitempty"`
+}
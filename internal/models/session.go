@@ -0,0 +1,25 @@
+package models
+
+type Session struct {
+	ID         string `json:"id"`
+	ExecId     int    `json:"exec_id,omitempty"`
+	UserAgent  string `json:"user_agent"`
+	IP         string `json:"ip"`
+	CreatedAt  string `json:"created_at"`
+	LastUsedAt string `json:"last_used_at"`
+	ExpiresAt  string `json:"expires_at"`
+	TwoFactor  bool   `json:"two_factor"`
+	Current    bool   `json:"current"`
+}
+
+// AuthTokens is what login and refresh hand back. Browsers get the same
+// tokens as HttpOnly cookies; other clients send the access token in an
+// Authorization: Bearer header.
+type AuthTokens struct {
+	SessionId        string `json:"session_id"`
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	CSRFToken        string `json:"csrf_token"`
+	AccessExpiresAt  string `json:"access_expires_at"`
+	RefreshExpiresAt string `json:"refresh_expires_at"`
+}
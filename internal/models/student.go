@@ -8,11 +8,8 @@ type Student struct {
 	Email     string `json:"email,omitempty"`
 	ClassId   int `json:"class_id,omitempty"`
 	Class     Class  `json:"class,omitempty"`
-}
-
-type Class struct {
-	ID   int    `json:"id,omitempty"`
-	Name string `json:"name,omitempty"`
+	Status    string `json:"status,omitempty"`
+	Guardians []StudentGuardian `json:"guardians,omitempty"`
 }
 
 type PaginationMeta struct {
@@ -1,10 +1,22 @@
 package models
 
 type Teacher struct {
-	ID        int `json:"id"`
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
+	ID          int                 `json:"id"`
+	FirstName   string              `json:"first_name"`
+	LastName    string              `json:"last_name"`
+	Subject     string              `json:"subject"`
+	Email       string              `json:"email"`
+	Class       string              `json:"class"`
+	ExecId      int                 `json:"exec_id,omitempty"`
+	Assignments []TeacherAssignment `json:"assignments,omitempty"`
+}
+
+type TeacherAssignment struct {
+	ID        int    `json:"id"`
+	TeacherId int    `json:"teacher_id"`
+	ClassId   int    `json:"class_id"`
+	ClassName string `json:"class_name,omitempty"`
 	Subject   string `json:"subject"`
-	Email     string `json:"email"`
-	Class     string `json:"class"`
+	TermId    int    `json:"term_id,omitempty"`
+	TermName  string `json:"term_name,omitempty"`
 }
@@ -0,0 +1,29 @@
+package models
+
+type AcademicYear struct {
+	ID        int    `json:"id,omitempty"`
+	Name      string `json:"name,omitempty"`
+	StartDate string `json:"start_date,omitempty"`
+	EndDate   string `json:"end_date,omitempty"`
+	Terms     []Term `json:"terms,omitempty"`
+}
+
+type Term struct {
+	ID             int    `json:"id,omitempty"`
+	AcademicYearId int    `json:"academic_year_id,omitempty"`
+	Name           string `json:"name,omitempty"`
+	StartDate      string `json:"start_date,omitempty"`
+	EndDate        string `json:"end_date,omitempty"`
+	IsCurrent      bool   `json:"is_current"`
+}
+
+type Enrollment struct {
+	ID        int    `json:"id,omitempty"`
+	StudentId int    `json:"student_id"`
+	ClassId   int    `json:"class_id"`
+	ClassName string `json:"class_name,omitempty"`
+	TermId    int    `json:"term_id"`
+	TermName  string `json:"term_name,omitempty"`
+	Status    string `json:"status"`
+	CreatedAt string `json:"created_at,omitempty"`
+}
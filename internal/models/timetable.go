@@ -0,0 +1,18 @@
+package models
+
+// TimetableSlot is one weekly lesson. DayOfWeek runs from 1 (Monday) to 7
+// (Sunday); times are "HH:MM".
+type TimetableSlot struct {
+	ID          int    `json:"id,omitempty"`
+	TermId      int    `json:"term_id,omitempty"`
+	DayOfWeek   int    `json:"day_of_week,omitempty"`
+	Period      int    `json:"period,omitempty"`
+	StartTime   string `json:"start_time,omitempty"`
+	EndTime     string `json:"end_time,omitempty"`
+	ClassId     int    `json:"class_id,omitempty"`
+	ClassName   string `json:"class_name,omitempty"`
+	TeacherId   int    `json:"teacher_id,omitempty"`
+	TeacherName string `json:"teacher_name,omitempty"`
+	Subject     string `json:"subject,omitempty"`
+	Room        string `json:"room,omitempty"`
+}
@@ -0,0 +1,44 @@
+package models
+
+type TwoFactorStatus struct {
+	Enabled           bool `json:"enabled"`
+	Required          bool `json:"required"`
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+}
+
+// TwoFactorEnrollment is shown once when enrollment starts. URI is the
+// otpauth:// link to render as a QR code.
+type TwoFactorEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// TwoFactorRequest carries a second factor: either a code from the
+// authenticator app or a recovery code. Password is asked for when
+// disabling 2FA or replacing recovery codes.
+type TwoFactorRequest struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+	Password     string `json:"password,omitempty"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
+}
+
+// LoginChallenge is returned by login instead of a session when the exec
+// has 2FA enabled. The token is redeemed at /execs/login/2fa.
+type LoginChallenge struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token"`
+	ExpiresAt         string `json:"expires_at"`
+}
+
+// RecoveryCodes are shown once. Token is the new access token when
+// enabling 2FA reissues it.
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+	Token string   `json:"token,omitempty"`
+}
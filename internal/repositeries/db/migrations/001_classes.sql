@@ -0,0 +1,9 @@
+-- Classes become a managed resource with capacity, grade level, room and a
+-- homeroom teacher.
+ALTER TABLE classes
+	ADD COLUMN grade_level INT NOT NULL DEFAULT 0,
+	ADD COLUMN room VARCHAR(50) NOT NULL DEFAULT '',
+	ADD COLUMN capacity INT NOT NULL DEFAULT 0,
+	ADD COLUMN homeroom_teacher_id INT NULL,
+	ADD CONSTRAINT fk_classes_homeroom_teacher
+		FOREIGN KEY (homeroom_teacher_id) REFERENCES teachers(id) ON DELETE SET NULL;
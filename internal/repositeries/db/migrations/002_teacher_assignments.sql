@@ -0,0 +1,16 @@
+-- Teachers can teach several classes and subjects. teachers.class_id and
+-- teachers.subject are kept for older clients but assignments are the source
+-- of truth.
+CREATE TABLE teacher_assignments (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	teacher_id INT NOT NULL,
+	class_id INT NOT NULL,
+	subject VARCHAR(100) NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE KEY uq_teacher_class_subject (teacher_id, class_id, subject),
+	CONSTRAINT fk_ta_teacher FOREIGN KEY (teacher_id) REFERENCES teachers(id) ON DELETE CASCADE,
+	CONSTRAINT fk_ta_class FOREIGN KEY (class_id) REFERENCES classes(id) ON DELETE CASCADE
+);
+
+INSERT INTO teacher_assignments (teacher_id, class_id, subject)
+SELECT id, class_id, subject FROM teachers WHERE class_id IS NOT NULL;
@@ -0,0 +1,23 @@
+-- Links a teacher record to the exec account they log in with, so requests
+-- can be checked against the teacher's assignments.
+ALTER TABLE teachers
+	ADD COLUMN exec_id INT NULL UNIQUE,
+	ADD CONSTRAINT fk_teachers_exec FOREIGN KEY (exec_id) REFERENCES execs(id) ON DELETE SET NULL;
+
+-- period 0 is a whole-day mark.
+CREATE TABLE attendance (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	student_id INT NOT NULL,
+	class_id INT NOT NULL,
+	date DATE NOT NULL,
+	period INT NOT NULL DEFAULT 0,
+	status ENUM('present', 'absent', 'late', 'excused') NOT NULL,
+	note VARCHAR(255) NOT NULL DEFAULT '',
+	marked_by INT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	UNIQUE KEY uq_attendance_student_date_period (student_id, date, period),
+	KEY idx_attendance_class_date (class_id, date),
+	CONSTRAINT fk_attendance_student FOREIGN KEY (student_id) REFERENCES student(id) ON DELETE CASCADE,
+	CONSTRAINT fk_attendance_class FOREIGN KEY (class_id) REFERENCES classes(id) ON DELETE CASCADE
+);
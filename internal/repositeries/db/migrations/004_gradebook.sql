@@ -0,0 +1,27 @@
+CREATE TABLE assessments (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	class_id INT NOT NULL,
+	subject VARCHAR(100) NOT NULL,
+	title VARCHAR(255) NOT NULL,
+	type VARCHAR(32) NOT NULL,
+	max_score DECIMAL(8,2) NOT NULL,
+	weight DECIMAL(6,2) NOT NULL DEFAULT 1,
+	due_date DATE NULL,
+	term VARCHAR(32) NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	KEY idx_assessments_class_subject (class_id, subject),
+	CONSTRAINT fk_assessments_class FOREIGN KEY (class_id) REFERENCES classes(id) ON DELETE CASCADE
+);
+
+CREATE TABLE scores (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	assessment_id INT NOT NULL,
+	student_id INT NOT NULL,
+	score DECIMAL(8,2) NOT NULL,
+	comment VARCHAR(500) NOT NULL DEFAULT '',
+	graded_by INT NULL,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	UNIQUE KEY uq_scores_assessment_student (assessment_id, student_id),
+	CONSTRAINT fk_scores_assessment FOREIGN KEY (assessment_id) REFERENCES assessments(id) ON DELETE CASCADE,
+	CONSTRAINT fk_scores_student FOREIGN KEY (student_id) REFERENCES student(id) ON DELETE CASCADE
+);
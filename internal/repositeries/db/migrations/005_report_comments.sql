@@ -0,0 +1,14 @@
+-- Teacher comments printed on report cards. An empty subject is a general
+-- comment for the term.
+CREATE TABLE report_comments (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	student_id INT NOT NULL,
+	term VARCHAR(32) NOT NULL,
+	subject VARCHAR(100) NOT NULL DEFAULT '',
+	comment TEXT NOT NULL,
+	author_id INT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	KEY idx_report_comments_student_term (student_id, term),
+	CONSTRAINT fk_report_comments_student FOREIGN KEY (student_id) REFERENCES student(id) ON DELETE CASCADE,
+	CONSTRAINT fk_report_comments_author FOREIGN KEY (author_id) REFERENCES execs(id) ON DELETE SET NULL
+);
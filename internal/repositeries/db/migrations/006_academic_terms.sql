@@ -0,0 +1,76 @@
+CREATE TABLE academic_years (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	name VARCHAR(50) NOT NULL UNIQUE,
+	start_date DATE NOT NULL,
+	end_date DATE NOT NULL
+);
+
+CREATE TABLE terms (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	academic_year_id INT NOT NULL,
+	name VARCHAR(50) NOT NULL,
+	start_date DATE NOT NULL,
+	end_date DATE NOT NULL,
+	is_current BOOLEAN NOT NULL DEFAULT FALSE,
+	UNIQUE KEY uq_terms_year_name (academic_year_id, name),
+	CONSTRAINT fk_terms_year FOREIGN KEY (academic_year_id) REFERENCES academic_years(id) ON DELETE CASCADE
+);
+
+-- A student's class placement per term. student.class_id stays the current
+-- placement; enrollments keep the history, so a class cannot be deleted
+-- while any refer to it.
+CREATE TABLE enrollments (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	student_id INT NOT NULL,
+	class_id INT NOT NULL,
+	term_id INT NOT NULL,
+	status VARCHAR(20) NOT NULL DEFAULT 'enrolled',
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE KEY uq_enrollments_student_term (student_id, term_id),
+	KEY idx_enrollments_class_term (class_id, term_id),
+	CONSTRAINT fk_enrollments_student FOREIGN KEY (student_id) REFERENCES student(id) ON DELETE CASCADE,
+	CONSTRAINT fk_enrollments_class FOREIGN KEY (class_id) REFERENCES classes(id) ON DELETE RESTRICT,
+	CONSTRAINT fk_enrollments_term FOREIGN KEY (term_id) REFERENCES terms(id) ON DELETE CASCADE
+);
+
+-- Assignments without a term apply to every term. NULLs never clash in a
+-- unique key, so the key uses term_key, which is 0 for those.
+ALTER TABLE teacher_assignments
+	DROP INDEX uq_teacher_class_subject,
+	ADD COLUMN term_id INT NULL,
+	ADD COLUMN term_key INT AS (COALESCE(term_id, 0)) VIRTUAL,
+	ADD UNIQUE KEY uq_teacher_class_subject_term (teacher_id, class_id, subject, term_key),
+	ADD CONSTRAINT fk_ta_term FOREIGN KEY (term_id) REFERENCES terms(id) ON DELETE CASCADE;
+
+-- Grades and report comments move from free-text terms to term ids. Every
+-- label in use becomes a term of an "Imported" academic year, to be renamed
+-- and given real dates afterwards; rows without a label get no term.
+INSERT INTO academic_years (name, start_date, end_date)
+SELECT 'Imported', CURDATE(), CURDATE() FROM DUAL
+WHERE EXISTS (SELECT 1 FROM assessments WHERE term <> '')
+	OR EXISTS (SELECT 1 FROM report_comments WHERE term <> '');
+
+INSERT INTO terms (academic_year_id, name, start_date, end_date)
+SELECT y.id, labels.term, y.start_date, y.end_date
+FROM (
+	SELECT DISTINCT term FROM assessments WHERE term <> ''
+	UNION
+	SELECT DISTINCT term FROM report_comments WHERE term <> ''
+) labels
+JOIN academic_years y ON y.name = 'Imported';
+
+ALTER TABLE assessments ADD COLUMN term_id INT NULL;
+UPDATE assessments a JOIN terms t ON t.name = a.term SET a.term_id = t.id;
+ALTER TABLE assessments
+	DROP COLUMN term,
+	ADD CONSTRAINT fk_assessments_term FOREIGN KEY (term_id) REFERENCES terms(id);
+
+ALTER TABLE report_comments ADD COLUMN term_id INT NULL;
+UPDATE report_comments rc JOIN terms t ON t.name = rc.term SET rc.term_id = t.id;
+ALTER TABLE report_comments
+	DROP INDEX idx_report_comments_student_term,
+	DROP COLUMN term,
+	ADD KEY idx_report_comments_student_term (student_id, term_id),
+	ADD CONSTRAINT fk_report_comments_term FOREIGN KEY (term_id) REFERENCES terms(id);
+
+-- Students are placed in a term when it is made current; see SetCurrentTerm.
@@ -0,0 +1,3 @@
+-- active, graduated or transferred_out. Graduated and transferred students
+-- keep their last class_id; their history lives in enrollments.
+ALTER TABLE student ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'active';
@@ -0,0 +1,24 @@
+CREATE TABLE guardians (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	first_name VARCHAR(100) NOT NULL,
+	last_name VARCHAR(100) NOT NULL,
+	email VARCHAR(255) NOT NULL DEFAULT '',
+	phone VARCHAR(50) NOT NULL DEFAULT '',
+	alt_phone VARCHAR(50) NOT NULL DEFAULT '',
+	address VARCHAR(500) NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- relationship is per link: the same guardian can be a parent of one
+-- student and a grandparent of another.
+CREATE TABLE student_guardians (
+	student_id INT NOT NULL,
+	guardian_id INT NOT NULL,
+	relationship VARCHAR(50) NOT NULL,
+	is_primary_contact BOOLEAN NOT NULL DEFAULT FALSE,
+	is_emergency_contact BOOLEAN NOT NULL DEFAULT FALSE,
+	can_pickup BOOLEAN NOT NULL DEFAULT FALSE,
+	PRIMARY KEY (student_id, guardian_id),
+	CONSTRAINT fk_sg_student FOREIGN KEY (student_id) REFERENCES student(id) ON DELETE CASCADE,
+	CONSTRAINT fk_sg_guardian FOREIGN KEY (guardian_id) REFERENCES guardians(id) ON DELETE CASCADE
+);
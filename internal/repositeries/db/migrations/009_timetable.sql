@@ -0,0 +1,21 @@
+-- One row per weekly lesson. day_of_week is 1 (Monday) to 7 (Sunday).
+-- The unique keys back up the conflict checks done when saving a slot; an
+-- empty room is stored as NULL so it never conflicts.
+CREATE TABLE timetable_slots (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	term_id INT NOT NULL,
+	day_of_week TINYINT NOT NULL,
+	period INT NOT NULL,
+	start_time TIME NOT NULL,
+	end_time TIME NOT NULL,
+	class_id INT NOT NULL,
+	teacher_id INT NOT NULL,
+	subject VARCHAR(100) NOT NULL,
+	room VARCHAR(50) NULL,
+	UNIQUE KEY uq_timetable_class (term_id, day_of_week, period, class_id),
+	UNIQUE KEY uq_timetable_teacher (term_id, day_of_week, period, teacher_id),
+	UNIQUE KEY uq_timetable_room (term_id, day_of_week, period, room),
+	CONSTRAINT fk_timetable_term FOREIGN KEY (term_id) REFERENCES terms(id) ON DELETE CASCADE,
+	CONSTRAINT fk_timetable_class FOREIGN KEY (class_id) REFERENCES classes(id) ON DELETE CASCADE,
+	CONSTRAINT fk_timetable_teacher FOREIGN KEY (teacher_id) REFERENCES teachers(id) ON DELETE CASCADE
+);
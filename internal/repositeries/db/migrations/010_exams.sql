@@ -0,0 +1,49 @@
+CREATE TABLE exams (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	term_id INT NOT NULL,
+	name VARCHAR(100) NOT NULL,
+	subject VARCHAR(100) NOT NULL,
+	exam_date DATE NOT NULL,
+	start_time TIME NOT NULL,
+	duration_minutes INT NOT NULL,
+	KEY idx_exams_date (exam_date),
+	CONSTRAINT fk_exams_term FOREIGN KEY (term_id) REFERENCES terms(id) ON DELETE CASCADE
+);
+
+-- An exam can be sat by several classes at once, e.g. every grade 10 class.
+CREATE TABLE exam_classes (
+	exam_id INT NOT NULL,
+	class_id INT NOT NULL,
+	PRIMARY KEY (exam_id, class_id),
+	CONSTRAINT fk_exam_classes_exam FOREIGN KEY (exam_id) REFERENCES exams(id) ON DELETE CASCADE,
+	CONSTRAINT fk_exam_classes_class FOREIGN KEY (class_id) REFERENCES classes(id) ON DELETE CASCADE
+);
+
+CREATE TABLE exam_rooms (
+	exam_id INT NOT NULL,
+	room VARCHAR(50) NOT NULL,
+	capacity INT NOT NULL,
+	PRIMARY KEY (exam_id, room),
+	CONSTRAINT fk_exam_rooms_exam FOREIGN KEY (exam_id) REFERENCES exams(id) ON DELETE CASCADE
+);
+
+CREATE TABLE exam_invigilators (
+	exam_id INT NOT NULL,
+	teacher_id INT NOT NULL,
+	room VARCHAR(50) NULL,
+	PRIMARY KEY (exam_id, teacher_id),
+	CONSTRAINT fk_exam_invigilators_exam FOREIGN KEY (exam_id) REFERENCES exams(id) ON DELETE CASCADE,
+	CONSTRAINT fk_exam_invigilators_teacher FOREIGN KEY (teacher_id) REFERENCES teachers(id) ON DELETE CASCADE
+);
+
+CREATE TABLE exam_seats (
+	exam_id INT NOT NULL,
+	student_id INT NOT NULL,
+	class_id INT NOT NULL,
+	room VARCHAR(50) NOT NULL,
+	seat_number INT NOT NULL,
+	PRIMARY KEY (exam_id, student_id),
+	UNIQUE KEY uq_exam_seats_room_seat (exam_id, room, seat_number),
+	CONSTRAINT fk_exam_seats_exam FOREIGN KEY (exam_id) REFERENCES exams(id) ON DELETE CASCADE,
+	CONSTRAINT fk_exam_seats_student FOREIGN KEY (student_id) REFERENCES student(id) ON DELETE CASCADE
+);
@@ -0,0 +1,43 @@
+CREATE TABLE homework (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	term_id INT NULL,
+	class_id INT NOT NULL,
+	subject VARCHAR(100) NOT NULL,
+	title VARCHAR(255) NOT NULL,
+	instructions TEXT NOT NULL,
+	due_date DATE NOT NULL,
+	max_score DECIMAL(8,2) NOT NULL,
+	-- set once graded submissions have been published to the gradebook
+	assessment_id INT NULL,
+	created_by INT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	KEY idx_homework_class_subject (class_id, subject),
+	CONSTRAINT fk_homework_class FOREIGN KEY (class_id) REFERENCES classes(id) ON DELETE CASCADE,
+	CONSTRAINT fk_homework_assessment FOREIGN KEY (assessment_id) REFERENCES assessments(id) ON DELETE SET NULL
+);
+
+CREATE TABLE homework_attachments (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	homework_id INT NOT NULL,
+	name VARCHAR(255) NOT NULL,
+	url VARCHAR(1000) NOT NULL,
+	CONSTRAINT fk_homework_attachments_homework FOREIGN KEY (homework_id) REFERENCES homework(id) ON DELETE CASCADE
+);
+
+-- A student without a row has not submitted.
+CREATE TABLE homework_submissions (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	homework_id INT NOT NULL,
+	student_id INT NOT NULL,
+	status VARCHAR(20) NOT NULL,
+	content TEXT NOT NULL,
+	file_url VARCHAR(1000) NOT NULL DEFAULT '',
+	submitted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	score DECIMAL(8,2) NULL,
+	feedback TEXT NULL,
+	graded_by INT NULL,
+	graded_at TIMESTAMP NULL,
+	UNIQUE KEY uq_homework_submissions (homework_id, student_id),
+	CONSTRAINT fk_homework_submissions_homework FOREIGN KEY (homework_id) REFERENCES homework(id) ON DELETE CASCADE,
+	CONSTRAINT fk_homework_submissions_student FOREIGN KEY (student_id) REFERENCES student(id) ON DELETE CASCADE
+);
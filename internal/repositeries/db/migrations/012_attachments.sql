@@ -0,0 +1,24 @@
+-- Stored content, one row per distinct checksum. Several attachments can
+-- share a file when the same document is uploaded twice.
+CREATE TABLE files (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	checksum CHAR(64) NOT NULL UNIQUE,
+	size BIGINT NOT NULL,
+	content_type VARCHAR(100) NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- owner_type is student, teacher or exec; owner_id is not a foreign key
+-- because it points at different tables.
+CREATE TABLE attachments (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	file_id INT NOT NULL,
+	owner_type VARCHAR(20) NOT NULL,
+	owner_id INT NOT NULL,
+	category VARCHAR(50) NOT NULL,
+	filename VARCHAR(255) NOT NULL,
+	uploaded_by INT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	KEY idx_attachments_owner (owner_type, owner_id),
+	CONSTRAINT fk_attachments_file FOREIGN KEY (file_id) REFERENCES files(id)
+);
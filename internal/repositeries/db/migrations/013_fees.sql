@@ -0,0 +1,87 @@
+-- All money columns are integer minor units (e.g. cents).
+
+-- A fee charged to every student of a class in a term; class_id NULL means
+-- every class.
+CREATE TABLE fee_structures (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	term_id INT NOT NULL,
+	class_id INT NULL,
+	name VARCHAR(100) NOT NULL,
+	amount BIGINT NOT NULL,
+	CONSTRAINT fk_fee_structures_term FOREIGN KEY (term_id) REFERENCES terms(id) ON DELETE CASCADE,
+	CONSTRAINT fk_fee_structures_class FOREIGN KEY (class_id) REFERENCES classes(id) ON DELETE CASCADE
+);
+
+-- Discounts and scholarships applied when invoices are generated. kind is
+-- percent (value in basis points, 2500 = 25%) or fixed (value in minor
+-- units). term_id NULL applies to every term.
+CREATE TABLE student_discounts (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	student_id INT NOT NULL,
+	term_id INT NULL,
+	name VARCHAR(100) NOT NULL,
+	kind VARCHAR(10) NOT NULL,
+	value BIGINT NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	CONSTRAINT fk_student_discounts_student FOREIGN KEY (student_id) REFERENCES student(id) ON DELETE CASCADE,
+	CONSTRAINT fk_student_discounts_term FOREIGN KEY (term_id) REFERENCES terms(id) ON DELETE CASCADE
+);
+
+CREATE TABLE invoices (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	student_id INT NOT NULL,
+	term_id INT NOT NULL,
+	class_id INT NOT NULL,
+	status VARCHAR(10) NOT NULL DEFAULT 'open',
+	due_date DATE NULL,
+	issued_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	KEY idx_invoices_student_term (student_id, term_id),
+	KEY idx_invoices_class_term (class_id, term_id),
+	CONSTRAINT fk_invoices_student FOREIGN KEY (student_id) REFERENCES student(id),
+	CONSTRAINT fk_invoices_term FOREIGN KEY (term_id) REFERENCES terms(id)
+);
+
+-- Discount lines have negative amounts.
+CREATE TABLE invoice_lines (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	invoice_id INT NOT NULL,
+	description VARCHAR(255) NOT NULL,
+	amount BIGINT NOT NULL,
+	CONSTRAINT fk_invoice_lines_invoice FOREIGN KEY (invoice_id) REFERENCES invoices(id)
+);
+
+CREATE TABLE payments (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	invoice_id INT NOT NULL,
+	amount BIGINT NOT NULL,
+	method VARCHAR(30) NOT NULL,
+	reference VARCHAR(100) NOT NULL DEFAULT '',
+	received_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	recorded_by INT NULL,
+	CONSTRAINT fk_payments_invoice FOREIGN KEY (invoice_id) REFERENCES invoices(id)
+);
+
+-- Append-only record of every change to what a student owes. Charges are
+-- positive, discounts, payments and voids negative, so the sum over an
+-- invoice is its outstanding balance.
+CREATE TABLE ledger_entries (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	invoice_id INT NOT NULL,
+	student_id INT NOT NULL,
+	entry_type VARCHAR(10) NOT NULL,
+	amount BIGINT NOT NULL,
+	description VARCHAR(255) NOT NULL,
+	payment_id INT NULL,
+	created_by INT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	KEY idx_ledger_student (student_id),
+	KEY idx_ledger_invoice (invoice_id),
+	CONSTRAINT fk_ledger_invoice FOREIGN KEY (invoice_id) REFERENCES invoices(id),
+	CONSTRAINT fk_ledger_payment FOREIGN KEY (payment_id) REFERENCES payments(id)
+);
+
+CREATE TRIGGER ledger_entries_no_update BEFORE UPDATE ON ledger_entries
+FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'ledger entries are immutable';
+
+CREATE TRIGGER ledger_entries_no_delete BEFORE DELETE ON ledger_entries
+FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'ledger entries are immutable';
@@ -0,0 +1,49 @@
+-- Applications from prospective students. student_id and class_id are set
+-- once the application is enrolled.
+CREATE TABLE applications (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	first_name VARCHAR(100) NOT NULL,
+	last_name VARCHAR(100) NOT NULL,
+	email VARCHAR(255) NOT NULL,
+	date_of_birth DATE NULL,
+	grade_level INT NOT NULL DEFAULT 0,
+	preferred_class_id INT NULL,
+	term_id INT NULL,
+	guardian_name VARCHAR(200) NOT NULL DEFAULT '',
+	guardian_email VARCHAR(255) NOT NULL DEFAULT '',
+	guardian_phone VARCHAR(50) NOT NULL DEFAULT '',
+	status VARCHAR(20) NOT NULL DEFAULT 'submitted',
+	class_id INT NULL,
+	student_id INT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	KEY idx_applications_status (status, created_at),
+	CONSTRAINT fk_applications_preferred_class FOREIGN KEY (preferred_class_id) REFERENCES classes(id) ON DELETE SET NULL,
+	CONSTRAINT fk_applications_term FOREIGN KEY (term_id) REFERENCES terms(id) ON DELETE SET NULL,
+	CONSTRAINT fk_applications_class FOREIGN KEY (class_id) REFERENCES classes(id) ON DELETE SET NULL,
+	CONSTRAINT fk_applications_student FOREIGN KEY (student_id) REFERENCES student(id) ON DELETE SET NULL
+);
+
+-- Every status change, oldest first. from_status is NULL for the initial
+-- submission.
+CREATE TABLE application_status_history (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	application_id INT NOT NULL,
+	from_status VARCHAR(20) NULL,
+	to_status VARCHAR(20) NOT NULL,
+	note TEXT NULL,
+	changed_by INT NULL,
+	changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	KEY idx_application_history (application_id),
+	CONSTRAINT fk_application_history_application FOREIGN KEY (application_id) REFERENCES applications(id) ON DELETE CASCADE
+);
+
+CREATE TABLE application_notes (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	application_id INT NOT NULL,
+	note TEXT NOT NULL,
+	author_id INT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	KEY idx_application_notes (application_id),
+	CONSTRAINT fk_application_notes_application FOREIGN KEY (application_id) REFERENCES applications(id) ON DELETE CASCADE
+);
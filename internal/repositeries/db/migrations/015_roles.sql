@@ -0,0 +1,8 @@
+-- Roles are checked against a fixed set (pkg/rbac). Normalise existing
+-- values and give anything unrecognised read-only access.
+UPDATE execs SET role = LOWER(TRIM(role));
+
+UPDATE execs SET role = 'read_only'
+WHERE role NOT IN ('admin', 'manager', 'teacher', 'staff', 'read_only');
+
+ALTER TABLE execs ALTER COLUMN role SET DEFAULT 'read_only';
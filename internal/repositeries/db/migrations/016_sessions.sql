@@ -0,0 +1,28 @@
+-- One row per login. Access tokens carry the session id (sid claim) and
+-- stop working once the session is revoked or expires.
+CREATE TABLE sessions (
+	id CHAR(32) PRIMARY KEY,
+	exec_id INT NOT NULL,
+	user_agent VARCHAR(255) NOT NULL DEFAULT '',
+	ip VARCHAR(45) NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	last_used_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	expires_at DATETIME NOT NULL,
+	revoked_at DATETIME NULL,
+	revoke_reason VARCHAR(50) NULL,
+	KEY idx_sessions_exec (exec_id),
+	CONSTRAINT fk_sessions_exec FOREIGN KEY (exec_id) REFERENCES execs(id) ON DELETE CASCADE
+);
+
+-- Refresh tokens rotate on every use; a session's tokens form one family.
+-- Presenting a token that was already used revokes the whole session.
+CREATE TABLE refresh_tokens (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	session_id CHAR(32) NOT NULL,
+	token_hash CHAR(64) NOT NULL,
+	parent_id INT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	used_at DATETIME NULL,
+	UNIQUE KEY uq_refresh_tokens_hash (token_hash),
+	CONSTRAINT fk_refresh_tokens_session FOREIGN KEY (session_id) REFERENCES sessions(id) ON DELETE CASCADE
+);
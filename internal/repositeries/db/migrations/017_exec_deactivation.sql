@@ -0,0 +1,6 @@
+-- When an exec is deactivated, so tokens issued before it stay rejected
+-- even if the account is later reactivated.
+ALTER TABLE execs ADD COLUMN deactivated_at DATETIME NULL;
+
+CREATE TRIGGER execs_deactivated_at BEFORE UPDATE ON execs
+FOR EACH ROW SET NEW.deactivated_at = IF(NEW.inactive AND NOT OLD.inactive, NOW(), OLD.deactivated_at);
@@ -0,0 +1,22 @@
+-- TOTP two-factor authentication. totp_secret is set when enrollment starts
+-- and only counts once totp_enabled is true. totp_last_step is the last
+-- time step accepted, so a code cannot be used twice.
+ALTER TABLE execs
+	ADD COLUMN totp_secret VARCHAR(64) NULL,
+	ADD COLUMN totp_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+	ADD COLUMN totp_enabled_at DATETIME NULL,
+	ADD COLUMN totp_last_step BIGINT NOT NULL DEFAULT 0;
+
+-- One-time recovery codes, stored hashed.
+CREATE TABLE exec_recovery_codes (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	exec_id INT NOT NULL,
+	code_hash CHAR(64) NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	used_at DATETIME NULL,
+	UNIQUE KEY uq_recovery_codes_hash (exec_id, code_hash),
+	CONSTRAINT fk_recovery_codes_exec FOREIGN KEY (exec_id) REFERENCES execs(id) ON DELETE CASCADE
+);
+
+-- Whether the session passed a second factor at login or enrollment.
+ALTER TABLE sessions ADD COLUMN two_factor BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,14 @@
+-- Failed attempts at login, password reset and the like, counted per
+-- username/email (kind 'user') and per client address (kind 'ip'). Each
+-- failure pushes blocked_until further out; locked is set once the policy's
+-- limit is reached.
+CREATE TABLE auth_throttle (
+	action VARCHAR(20) NOT NULL,
+	kind VARCHAR(10) NOT NULL,
+	subject VARCHAR(255) NOT NULL,
+	failures INT NOT NULL DEFAULT 0,
+	last_failure_at DATETIME NOT NULL,
+	blocked_until DATETIME NULL,
+	locked BOOLEAN NOT NULL DEFAULT FALSE,
+	PRIMARY KEY (action, kind, subject)
+);
@@ -0,0 +1,21 @@
+-- Service accounts are execs that only authenticate with API keys.
+ALTER TABLE execs ADD COLUMN service_account BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- API keys for machine access, sent as "Authorization: ApiKey <key>". Only
+-- the hash is stored; prefix is kept so a key can be recognised in lists.
+-- permissions is a comma separated subset of the owner's permissions.
+CREATE TABLE api_keys (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	exec_id INT NOT NULL,
+	name VARCHAR(100) NOT NULL,
+	prefix VARCHAR(16) NOT NULL,
+	key_hash CHAR(64) NOT NULL,
+	permissions TEXT NOT NULL,
+	expires_at DATETIME NOT NULL,
+	last_used_at DATETIME NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	revoked_at DATETIME NULL,
+	UNIQUE KEY uq_api_keys_hash (key_hash),
+	KEY idx_api_keys_exec (exec_id),
+	CONSTRAINT fk_api_keys_exec FOREIGN KEY (exec_id) REFERENCES execs(id) ON DELETE CASCADE
+);
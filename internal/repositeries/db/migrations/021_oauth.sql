@@ -0,0 +1,39 @@
+-- OAuth2 / OpenID Connect clients that sign execs in through this API.
+-- Public clients have no secret and rely on PKCE alone. redirect_uris and
+-- scopes are space separated.
+CREATE TABLE oauth_clients (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	client_id VARCHAR(64) NOT NULL,
+	client_secret_hash CHAR(64) NULL,
+	name VARCHAR(100) NOT NULL,
+	redirect_uris TEXT NOT NULL,
+	scopes VARCHAR(255) NOT NULL DEFAULT 'openid profile email',
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	revoked_at DATETIME NULL,
+	UNIQUE KEY uq_oauth_clients_client_id (client_id)
+);
+
+-- Scopes an exec has agreed to share with a client.
+CREATE TABLE oauth_consents (
+	exec_id INT NOT NULL,
+	client_id VARCHAR(64) NOT NULL,
+	scopes VARCHAR(255) NOT NULL,
+	granted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (exec_id, client_id),
+	CONSTRAINT fk_oauth_consents_exec FOREIGN KEY (exec_id) REFERENCES execs(id) ON DELETE CASCADE
+);
+
+-- Authorization codes, single use and short lived. Only the hash is kept.
+CREATE TABLE oauth_codes (
+	code_hash CHAR(64) PRIMARY KEY,
+	client_id VARCHAR(64) NOT NULL,
+	exec_id INT NOT NULL,
+	redirect_uri TEXT NOT NULL,
+	scopes VARCHAR(255) NOT NULL,
+	nonce VARCHAR(255) NOT NULL DEFAULT '',
+	code_challenge VARCHAR(128) NOT NULL,
+	auth_time BIGINT NOT NULL,
+	expires_at DATETIME NOT NULL,
+	used_at DATETIME NULL,
+	CONSTRAINT fk_oauth_codes_exec FOREIGN KEY (exec_id) REFERENCES execs(id) ON DELETE CASCADE
+);
@@ -0,0 +1,19 @@
+-- Outgoing email. Messages are rendered and queued in the same transaction
+-- as the change they report, then delivered by a background worker that
+-- retries failures with backoff until max attempts, when status becomes
+-- 'failed'. Bodies are cleared once sent since they can hold reset links.
+CREATE TABLE mail_outbox (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	template VARCHAR(50) NOT NULL,
+	recipient VARCHAR(255) NOT NULL,
+	subject VARCHAR(255) NOT NULL,
+	text_body MEDIUMTEXT NOT NULL,
+	html_body MEDIUMTEXT NOT NULL,
+	status ENUM('pending', 'sent', 'failed') NOT NULL DEFAULT 'pending',
+	attempts INT NOT NULL DEFAULT 0,
+	last_error TEXT NULL,
+	next_attempt_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	sent_at DATETIME NULL,
+	KEY idx_mail_outbox_due (status, next_attempt_at)
+);
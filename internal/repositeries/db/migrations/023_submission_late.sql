@@ -0,0 +1,9 @@
+-- Lateness moves out of status so grading no longer erases it. Graded
+-- submissions lost the flag, so it is worked out again from the dates.
+ALTER TABLE homework_submissions ADD COLUMN late BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE homework_submissions hs
+JOIN homework h ON hs.homework_id = h.id
+SET hs.late = DATE(hs.submitted_at) > h.due_date;
+
+UPDATE homework_submissions SET status = 'submitted' WHERE status = 'late';
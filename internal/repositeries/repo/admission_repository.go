@@ -0,0 +1,489 @@
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+	"school-api/internal/models"
+)
+
+const applicationColumns = `
+	a.id, a.first_name, a.last_name, a.email,
+	COALESCE(DATE_FORMAT(a.date_of_birth, '%Y-%m-%d'), ''), a.grade_level,
+	a.preferred_class_id, a.term_id, a.guardian_name, a.guardian_email, a.guardian_phone,
+	a.status, a.class_id, a.student_id, a.created_at, a.updated_at
+`
+
+// statuses an application may move to by hand from each status. Enrolling
+// goes through EnrollApplication so the student is created with it.
+var applicationTransitions = map[string][]string{
+	models.ApplicationSubmitted:   {models.ApplicationUnderReview, models.ApplicationRejected},
+	models.ApplicationUnderReview: {models.ApplicationInterview, models.ApplicationAccepted, models.ApplicationRejected, models.ApplicationWaitlisted},
+	models.ApplicationInterview:   {models.ApplicationAccepted, models.ApplicationRejected, models.ApplicationWaitlisted},
+	models.ApplicationAccepted:    {models.ApplicationRejected, models.ApplicationWaitlisted},
+	models.ApplicationWaitlisted:  {models.ApplicationUnderReview, models.ApplicationAccepted, models.ApplicationRejected},
+}
+
+// statuses from which an application can be enrolled
+var enrollableStatuses = map[string]bool{
+	models.ApplicationUnderReview: true,
+	models.ApplicationInterview:   true,
+	models.ApplicationAccepted:    true,
+	models.ApplicationWaitlisted:  true,
+}
+
+func IsValidApplicationStatus(status string) bool {
+	_, ok := applicationTransitions[status]
+	return ok || status == models.ApplicationRejected || status == models.ApplicationEnrolled
+}
+
+func scanApplication(scanner interface{ Scan(...any) error }, a *models.Application) error {
+	var preferredClassId, termId, classId, studentId sql.NullInt64
+
+	err := scanner.Scan(&a.ID, &a.FirstName, &a.LastName, &a.Email, &a.DateOfBirth, &a.GradeLevel,
+		&preferredClassId, &termId, &a.GuardianName, &a.GuardianEmail, &a.GuardianPhone,
+		&a.Status, &classId, &studentId, &a.CreatedAt, &a.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	a.PreferredClassId = int(preferredClassId.Int64)
+	a.TermId = int(termId.Int64)
+	a.ClassId = int(classId.Int64)
+	a.StudentId = int(studentId.Int64)
+	return nil
+}
+
+// FindApplicationByID returns the application with its status history and
+// notes.
+func FindApplicationByID(id int, db *sql.DB) (*models.Application, error) {
+	var a models.Application
+
+	err := scanApplication(db.QueryRow("SELECT "+applicationColumns+" FROM applications a WHERE a.id = ?", id), &a)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT from_status, to_status, note, changed_by, changed_at
+		FROM application_status_history WHERE application_id = ? ORDER BY id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c models.ApplicationStatusChange
+		var from, note sql.NullString
+		var changedBy sql.NullInt64
+		if err := rows.Scan(&from, &c.ToStatus, &note, &changedBy, &c.ChangedAt); err != nil {
+			return nil, err
+		}
+		c.FromStatus = from.String
+		c.Note = note.String
+		c.ChangedBy = int(changedBy.Int64)
+		a.History = append(a.History, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.Query("SELECT id, note, author_id, created_at FROM application_notes WHERE application_id = ? ORDER BY id", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var n models.ApplicationNote
+		var authorId sql.NullInt64
+		if err := rows.Scan(&n.ID, &n.Note, &authorId, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		n.AuthorId = int(authorId.Int64)
+		a.Notes = append(a.Notes, n)
+	}
+
+	return &a, rows.Err()
+}
+
+// FindApplications lists applications, oldest first unless sorted, so a
+// filter on the waitlisted status gives the waitlist in order.
+func FindApplications(db *sql.DB, search string, filters map[string]string, sort string) ([]models.Application, error) {
+	query := "SELECT " + applicationColumns + " FROM applications a WHERE 1=1"
+
+	var args []any
+
+	for key, val := range filters {
+		if val == "" {
+			continue
+		}
+
+		query += " AND a." + key + " = ?"
+		args = append(args, val)
+	}
+
+	if search != "" {
+		query += `
+			AND (
+				a.first_name LIKE ? OR
+				a.last_name  LIKE ? OR
+				a.email      LIKE ? OR
+				a.guardian_name LIKE ?
+			)
+		`
+		pattern := "%" + search + "%"
+		args = append(args, pattern, pattern, pattern, pattern)
+	}
+
+	if sort != "" {
+		query += " ORDER BY a." + sort
+	} else {
+		query += " ORDER BY a.created_at, a.id"
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var applications []models.Application
+	for rows.Next() {
+		var a models.Application
+		if err := scanApplication(rows, &a); err != nil {
+			return nil, err
+		}
+		applications = append(applications, a)
+	}
+
+	return applications, rows.Err()
+}
+
+// AddApplication stores a new application in the submitted status and
+// records the submission in its history.
+func AddApplication(db *sql.DB, a *models.Application, submittedBy int) (sql.Result, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := tx.Exec(`
+		INSERT INTO applications (first_name, last_name, email, date_of_birth, grade_level, preferred_class_id, term_id,
+			guardian_name, guardian_email, guardian_phone, status)
+		VALUES (?,?,?,?,?,?,?,?,?,?,?)
+	`, a.FirstName, a.LastName, a.Email, nullableString(a.DateOfBirth), a.GradeLevel, nullableInt(a.PreferredClassId),
+		nullableInt(a.TermId), a.GuardianName, a.GuardianEmail, a.GuardianPhone, models.ApplicationSubmitted)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = addApplicationHistory(tx, int(id), "", models.ApplicationSubmitted, "", submittedBy)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return res, tx.Commit()
+}
+
+// UpdateApplication edits the applicant's details. The status only changes
+// through ChangeApplicationStatus and EnrollApplication.
+func UpdateApplication(db *sql.DB, existing, update *models.Application, id int) (sql.Result, error) {
+	err := scanApplication(db.QueryRow("SELECT "+applicationColumns+" FROM applications a WHERE a.id = ?", id), existing)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if existing.Status == models.ApplicationEnrolled {
+		return nil, fmt.Errorf("application %d is enrolled, update student %d instead", id, existing.StudentId)
+	}
+
+	update.ID = existing.ID
+	update.Status = existing.Status
+	// Simple conditional updates
+	if update.FirstName == "" {
+		update.FirstName = existing.FirstName
+	}
+	if update.LastName == "" {
+		update.LastName = existing.LastName
+	}
+	if update.Email == "" {
+		update.Email = existing.Email
+	}
+	if update.DateOfBirth == "" {
+		update.DateOfBirth = existing.DateOfBirth
+	}
+	if update.GradeLevel == 0 {
+		update.GradeLevel = existing.GradeLevel
+	}
+	if update.PreferredClassId == 0 {
+		update.PreferredClassId = existing.PreferredClassId
+	}
+	if update.TermId == 0 {
+		update.TermId = existing.TermId
+	}
+	if update.GuardianName == "" {
+		update.GuardianName = existing.GuardianName
+	}
+	if update.GuardianEmail == "" {
+		update.GuardianEmail = existing.GuardianEmail
+	}
+	if update.GuardianPhone == "" {
+		update.GuardianPhone = existing.GuardianPhone
+	}
+
+	return db.Exec(`
+		UPDATE applications SET first_name=?, last_name=?, email=?, date_of_birth=?, grade_level=?, preferred_class_id=?,
+			term_id=?, guardian_name=?, guardian_email=?, guardian_phone=?
+		WHERE id=?
+	`, update.FirstName, update.LastName, update.Email, nullableString(update.DateOfBirth), update.GradeLevel,
+		nullableInt(update.PreferredClassId), nullableInt(update.TermId), update.GuardianName, update.GuardianEmail,
+		update.GuardianPhone, id)
+}
+
+func DeleteApplication(db *sql.DB, id int) error {
+	return deleteByID(db, "applications", id)
+}
+
+func AddApplicationNote(db *sql.DB, applicationId int, note string, authorId int) (sql.Result, error) {
+	return db.Exec("INSERT INTO application_notes (application_id, note, author_id) VALUES (?,?,?)",
+		applicationId, note, nullableInt(authorId))
+}
+
+// ChangeApplicationStatus moves an application to status if the transition
+// is allowed from where it is now, and records the change.
+func ChangeApplicationStatus(db *sql.DB, id int, status, note string, changedBy int) error {
+	if status == models.ApplicationEnrolled {
+		return fmt.Errorf("applications are enrolled through the enroll endpoint")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	current, err := lockApplication(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if !canTransition(current, status) {
+		tx.Rollback()
+		return fmt.Errorf("cannot move an application from %s to %s", current, status)
+	}
+
+	err = setApplicationStatus(tx, id, current, status, note, changedBy)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// EnrollApplication accepts the application if needed, creates the student
+// and places them in a class with room, all in one transaction. When no
+// suitable class has room the application is waitlisted instead and no
+// student is created; enrolled reports which happened.
+func EnrollApplication(db *sql.DB, id int, req *models.EnrollApplicationRequest, changedBy int) (enrolled bool, err error) {
+	currentTermId, err := CurrentTermID(db)
+	if err != nil {
+		return false, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	enrolled, err = enrollApplication(tx, id, req, currentTermId, changedBy)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	return enrolled, tx.Commit()
+}
+
+func enrollApplication(tx *sql.Tx, id int, req *models.EnrollApplicationRequest, currentTermId, changedBy int) (bool, error) {
+	var a models.Application
+	err := scanApplication(tx.QueryRow("SELECT "+applicationColumns+" FROM applications a WHERE a.id = ? FOR UPDATE", id), &a)
+	if err != nil {
+		return false, err
+	}
+
+	if !enrollableStatuses[a.Status] {
+		return false, fmt.Errorf("cannot enroll an application that is %s", a.Status)
+	}
+
+	var tmp int
+	err = tx.QueryRow("SELECT id FROM student WHERE email = ?", a.Email).Scan(&tmp)
+	if err == nil {
+		return false, fmt.Errorf("a student with email %s already exists", a.Email)
+	} else if err != sql.ErrNoRows {
+		return false, err
+	}
+
+	classId, err := placeApplicant(tx, &a, req.ClassId)
+	if err != nil {
+		return false, err
+	}
+
+	// still no room, it keeps its place on the waitlist
+	if classId == 0 && a.Status == models.ApplicationWaitlisted {
+		return false, nil
+	}
+
+	status := a.Status
+	if status != models.ApplicationAccepted {
+		if err := setApplicationStatus(tx, id, status, models.ApplicationAccepted, req.Note, changedBy); err != nil {
+			return false, err
+		}
+		status = models.ApplicationAccepted
+	}
+
+	if classId == 0 {
+		err := setApplicationStatus(tx, id, status, models.ApplicationWaitlisted, "no class with available capacity", changedBy)
+		return false, err
+	}
+
+	res, err := tx.Exec("INSERT INTO student (first_name,last_name,email,class_id) VALUES (?,?,?,?)", a.FirstName, a.LastName, a.Email, classId)
+	if err != nil {
+		return false, err
+	}
+
+	studentId, err := res.LastInsertId()
+	if err != nil {
+		return false, err
+	}
+
+	termId := a.TermId
+	if termId == 0 {
+		termId = currentTermId
+	}
+
+	if termId != 0 {
+		if err := UpsertEnrollment(tx, int(studentId), classId, termId, "enrolled"); err != nil {
+			return false, err
+		}
+	}
+
+	_, err = tx.Exec("UPDATE applications SET class_id = ?, student_id = ? WHERE id = ?", classId, studentId, id)
+	if err != nil {
+		return false, err
+	}
+
+	err = setApplicationStatus(tx, id, status, models.ApplicationEnrolled, fmt.Sprintf("enrolled as student %d in class %d", studentId, classId), changedBy)
+	return err == nil, err
+}
+
+// placeApplicant picks the class for an applicant: the requested class, or
+// else the preferred class, or else the least full class of the applicant's
+// grade level. A capacity of 0 means the class has no limit. It returns 0
+// when no candidate has room. The class rows stay locked until the
+// transaction ends so concurrent enrollments cannot overfill a class.
+func placeApplicant(tx *sql.Tx, a *models.Application, requested int) (int, error) {
+	query := `
+		SELECT c.id, c.capacity,
+			(SELECT COUNT(*) FROM student s WHERE s.class_id = c.id AND s.status = ?)
+		FROM classes c
+	`
+	args := []any{models.StudentActive}
+
+	if requested != 0 {
+		query += " WHERE c.id = ?"
+		args = append(args, requested)
+	} else {
+		query += " WHERE c.id = ? OR c.grade_level = ?"
+		args = append(args, a.PreferredClassId, a.GradeLevel)
+	}
+
+	query += " ORDER BY c.id FOR UPDATE"
+
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	best, bestFill := 0, 0.0
+	found := false
+
+	for rows.Next() {
+		var id, capacity, count int
+		if err := rows.Scan(&id, &capacity, &count); err != nil {
+			return 0, err
+		}
+		found = true
+
+		if capacity > 0 && count >= capacity {
+			continue
+		}
+
+		if id == a.PreferredClassId && requested == 0 {
+			return id, nil
+		}
+
+		// an unlimited class counts as empty
+		fill := 0.0
+		if capacity > 0 {
+			fill = float64(count) / float64(capacity)
+		}
+
+		if best == 0 || fill < bestFill {
+			best, bestFill = id, fill
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if requested != 0 && !found {
+		return 0, fmt.Errorf("class %d not found", requested)
+	}
+
+	return best, nil
+}
+
+func canTransition(from, to string) bool {
+	for _, s := range applicationTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+func lockApplication(tx *sql.Tx, id int) (string, error) {
+	var status string
+	err := tx.QueryRow("SELECT status FROM applications WHERE id = ? FOR UPDATE", id).Scan(&status)
+	return status, err
+}
+
+func setApplicationStatus(tx *sql.Tx, id int, from, to, note string, changedBy int) error {
+	if _, err := tx.Exec("UPDATE applications SET status = ? WHERE id = ?", to, id); err != nil {
+		return err
+	}
+	return addApplicationHistory(tx, id, from, to, note, changedBy)
+}
+
+func addApplicationHistory(db execer, id int, from, to, note string, changedBy int) error {
+	_, err := db.Exec("INSERT INTO application_status_history (application_id, from_status, to_status, note, changed_by) VALUES (?,?,?,?,?)",
+		id, nullableString(from), to, nullableString(note), nullableInt(changedBy))
+	return err
+}
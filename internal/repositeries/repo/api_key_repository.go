@@ -0,0 +1,135 @@
+package repo
+
+import (
+	"database/sql"
+	"school-api/internal/models"
+	"strings"
+)
+
+const apiKeyColumns = `
+	k.id, k.exec_id, k.name, k.prefix, k.permissions, k.expires_at,
+	COALESCE(k.last_used_at, ''), k.created_at, COALESCE(k.revoked_at, '')
+`
+
+func scanAPIKey(scanner interface{ Scan(...any) error }, k *models.APIKey) error {
+	var permissions string
+
+	err := scanner.Scan(&k.ID, &k.ExecId, &k.Name, &k.Prefix, &permissions, &k.ExpiresAt, &k.LastUsedAt, &k.CreatedAt, &k.RevokedAt)
+	if err != nil {
+		return err
+	}
+
+	k.Permissions = splitPermissions(permissions)
+	return nil
+}
+
+func splitPermissions(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, ",")
+}
+
+// CreateAPIKey issues a key for the exec that expires after days. The
+// returned key is the only time its secret is available.
+func CreateAPIKey(db *sql.DB, execId int, name string, permissions []string, days int) (*models.APIKey, error) {
+	secret, err := randomToken(24)
+	if err != nil {
+		return nil, err
+	}
+
+	key := "sk_" + secret
+
+	result, err := db.Exec(`
+		INSERT INTO api_keys (exec_id, name, prefix, key_hash, permissions, expires_at)
+		VALUES (?, ?, ?, ?, ?, NOW() + INTERVAL ? DAY)
+	`, execId, name, key[:11], hashToken(key), strings.Join(permissions, ","), days)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	var k models.APIKey
+	if err := scanAPIKey(db.QueryRow("SELECT "+apiKeyColumns+" FROM api_keys k WHERE k.id = ?", id), &k); err != nil {
+		return nil, err
+	}
+
+	k.Key = key
+	return &k, nil
+}
+
+// FindAPIKeys lists the exec's keys, revoked and expired ones included,
+// newest first.
+func FindAPIKeys(db *sql.DB, execId int) ([]models.APIKey, error) {
+	rows, err := db.Query("SELECT "+apiKeyColumns+" FROM api_keys k WHERE k.exec_id = ? ORDER BY k.created_at DESC, k.id DESC", execId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []models.APIKey
+	for rows.Next() {
+		var k models.APIKey
+		if err := scanAPIKey(rows, &k); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey stops one of the exec's keys from working. It returns
+// sql.ErrNoRows when the exec has no such unrevoked key.
+func RevokeAPIKey(db *sql.DB, execId, keyId int) error {
+	result, err := db.Exec("UPDATE api_keys SET revoked_at = NOW() WHERE id = ? AND exec_id = ? AND revoked_at IS NULL", keyId, execId)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// AuthenticateAPIKey looks up a presented key. It returns nil when the key
+// is unknown, revoked or expired, or its owner is inactive. last_used_at is
+// updated at most once a minute.
+func AuthenticateAPIKey(db *sql.DB, key string) (*models.APIKeyIdentity, error) {
+	var id models.APIKeyIdentity
+	var permissions string
+
+	err := db.QueryRow(`
+		SELECT k.id, k.permissions, e.id, e.username, e.role
+		FROM api_keys k JOIN execs e ON k.exec_id = e.id
+		WHERE k.key_hash = ? AND k.revoked_at IS NULL AND k.expires_at > NOW() AND e.inactive = FALSE
+	`, hashToken(key)).Scan(&id.KeyId, &permissions, &id.ExecId, &id.Username, &id.Role)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`
+		UPDATE api_keys SET last_used_at = NOW()
+		WHERE id = ? AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL 1 MINUTE)
+	`, id.KeyId)
+	if err != nil {
+		return nil, err
+	}
+
+	id.Permissions = splitPermissions(permissions)
+	return &id, nil
+}
@@ -0,0 +1,194 @@
+package repo
+
+import (
+	"database/sql"
+	"school-api/internal/models"
+	"school-api/pkg/storage"
+)
+
+var attachmentCategories = map[string]bool{
+	"birth_certificate": true,
+	"medical_form":      true,
+	"profile_photo":     true,
+	"assignment":        true,
+	"other":             true,
+}
+
+func IsValidAttachmentCategory(c string) bool {
+	return attachmentCategories[c]
+}
+
+const attachmentColumns = `
+	a.id, a.owner_type, a.owner_id, a.category, a.filename,
+	f.content_type, f.size, f.checksum, a.uploaded_by, a.created_at
+`
+
+func scanAttachment(scanner interface{ Scan(...any) error }, a *models.Attachment) error {
+	var uploadedBy sql.NullInt64
+
+	err := scanner.Scan(&a.ID, &a.OwnerType, &a.OwnerId, &a.Category, &a.Filename,
+		&a.ContentType, &a.Size, &a.Checksum, &uploadedBy, &a.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	a.UploadedBy = int(uploadedBy.Int64)
+
+	return nil
+}
+
+func FindAttachmentByID(id int, db *sql.DB) (*models.Attachment, error) {
+	var a models.Attachment
+
+	err := scanAttachment(db.QueryRow(`
+		SELECT `+attachmentColumns+`
+		FROM attachments a
+		JOIN files f ON a.file_id = f.id
+		WHERE a.id = ?
+	`, id), &a)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &a, nil
+}
+
+func FindAttachments(db *sql.DB, ownerType string, ownerId int, category string) ([]models.Attachment, error) {
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM attachments a
+		JOIN files f ON a.file_id = f.id
+		WHERE a.owner_type = ? AND a.owner_id = ?
+	`
+	args := []any{ownerType, ownerId}
+
+	if category != "" {
+		query += " AND a.category = ?"
+		args = append(args, category)
+	}
+
+	query += " ORDER BY a.created_at DESC, a.id DESC"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attachments []models.Attachment
+	for rows.Next() {
+		var a models.Attachment
+		if err := scanAttachment(rows, &a); err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, a)
+	}
+
+	return attachments, rows.Err()
+}
+
+// AddAttachment records an uploaded file, reusing the files row when the
+// same content was stored before. The content is written to store while the
+// files row is locked, so a concurrent DeleteAttachment of the same content
+// cannot remove it in between, and is removed again if the insert fails.
+func AddAttachment(db *sql.DB, a *models.Attachment, store storage.Storage, upload *storage.Upload) (sql.Result, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	// LAST_INSERT_ID(id) makes the existing row's id available on duplicates
+	res, err := tx.Exec(`
+		INSERT INTO files (checksum, size, content_type) VALUES (?,?,?)
+		ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id)
+	`, a.Checksum, a.Size, a.ContentType)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	fileId, err := res.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	created, err := upload.Store(store)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	res, err = tx.Exec(`
+		INSERT INTO attachments (file_id, owner_type, owner_id, category, filename, uploaded_by)
+		VALUES (?,?,?,?,?,?)
+	`, fileId, a.OwnerType, a.OwnerId, a.Category, a.Filename, nullableInt(a.UploadedBy))
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		if created {
+			store.Delete(a.Checksum)
+		}
+		tx.Rollback()
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// DeleteAttachment removes an attachment. When no other attachment uses the
+// same file it also drops the files row and deletes the content from store
+// before committing, while the row is still locked against AddAttachment.
+func DeleteAttachment(db *sql.DB, id int, store storage.Storage) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	var fileId int
+	var checksum string
+	err = tx.QueryRow(`
+		SELECT f.id, f.checksum
+		FROM attachments a
+		JOIN files f ON a.file_id = f.id
+		WHERE a.id = ?
+		FOR UPDATE
+	`, id).Scan(&fileId, &checksum)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM attachments WHERE id = ?", id); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var remaining int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM attachments WHERE file_id = ? FOR UPDATE", fileId).Scan(&remaining); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if remaining > 0 {
+		return tx.Commit()
+	}
+
+	if _, err := tx.Exec("DELETE FROM files WHERE id = ?", fileId); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := store.Delete(checksum); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,198 @@
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+	"school-api/internal/models"
+)
+
+var attendanceStatuses = map[string]bool{
+	models.AttendancePresent: true,
+	models.AttendanceAbsent:  true,
+	models.AttendanceLate:    true,
+	models.AttendanceExcused: true,
+}
+
+func IsValidAttendanceStatus(status string) bool {
+	return attendanceStatuses[status]
+}
+
+// MarkClassAttendance records the status of every student in the request for
+// one class, date and period. Existing marks for the same student, date and
+// period are overwritten. Every student must belong to the class.
+func MarkClassAttendance(db *sql.DB, classId int, req *models.BulkAttendanceRequest, markedBy int) ([]models.Attendance, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	var marked []models.Attendance
+
+	for _, record := range req.Records {
+		var studentClassId int
+		err := tx.QueryRow("SELECT class_id FROM student WHERE id = ?", record.StudentId).Scan(&studentClassId)
+
+		if err == sql.ErrNoRows {
+			tx.Rollback()
+			return nil, fmt.Errorf("student with id %d not found", record.StudentId)
+		} else if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		if studentClassId != classId {
+			tx.Rollback()
+			return nil, fmt.Errorf("student with id %d is not in class %d", record.StudentId, classId)
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO attendance (student_id, class_id, date, period, status, note, marked_by)
+			VALUES (?,?,?,?,?,?,?)
+			ON DUPLICATE KEY UPDATE class_id = VALUES(class_id), status = VALUES(status), note = VALUES(note), marked_by = VALUES(marked_by)
+		`, record.StudentId, classId, req.Date, req.Period, record.Status, record.Note, nullableInt(markedBy))
+
+		if err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("failed to mark student %d: %w", record.StudentId, err)
+		}
+
+		marked = append(marked, models.Attendance{
+			StudentId: record.StudentId,
+			ClassId:   classId,
+			Date:      req.Date,
+			Period:    req.Period,
+			Status:    record.Status,
+			Note:      record.Note,
+			MarkedBy:  markedBy,
+		})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return marked, nil
+}
+
+// FindAttendance lists attendance marks filtered by student and/or class and
+// an optional inclusive date range (YYYY-MM-DD).
+func FindAttendance(db *sql.DB, studentId, classId int, from, to string) ([]models.Attendance, error) {
+	query := `
+		SELECT a.id, a.student_id, CONCAT(s.first_name, ' ', s.last_name), a.class_id,
+			DATE_FORMAT(a.date, '%Y-%m-%d'), a.period, a.status, a.note, a.marked_by
+		FROM attendance a
+		JOIN student s ON a.student_id = s.id
+		WHERE 1=1
+	`
+
+	query, args := attendanceFilters(query, studentId, classId, from, to)
+	query += " ORDER BY a.date, a.period, s.last_name, s.first_name"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []models.Attendance
+	for rows.Next() {
+		var a models.Attendance
+		var markedBy sql.NullInt64
+
+		err := rows.Scan(&a.ID, &a.StudentId, &a.StudentName, &a.ClassId, &a.Date, &a.Period, &a.Status, &a.Note, &markedBy)
+		if err != nil {
+			return nil, err
+		}
+
+		a.MarkedBy = int(markedBy.Int64)
+		records = append(records, a)
+	}
+
+	return records, rows.Err()
+}
+
+// SummarizeAttendance counts marks per student for the same filters as
+// FindAttendance.
+func SummarizeAttendance(db *sql.DB, studentId, classId int, from, to string) ([]models.AttendanceSummary, error) {
+	query := `
+		SELECT a.student_id, CONCAT(s.first_name, ' ', s.last_name),
+			COUNT(*),
+			SUM(a.status = 'present'),
+			SUM(a.status = 'absent'),
+			SUM(a.status = 'late'),
+			SUM(a.status = 'excused')
+		FROM attendance a
+		JOIN student s ON a.student_id = s.id
+		WHERE 1=1
+	`
+
+	query, args := attendanceFilters(query, studentId, classId, from, to)
+	query += " GROUP BY a.student_id, s.first_name, s.last_name ORDER BY s.last_name, s.first_name"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []models.AttendanceSummary
+	for rows.Next() {
+		var sum models.AttendanceSummary
+
+		err := rows.Scan(&sum.StudentId, &sum.StudentName, &sum.Total, &sum.Present, &sum.Absent, &sum.Late, &sum.Excused)
+		if err != nil {
+			return nil, err
+		}
+
+		sum.AbsenceRate = absenceRate(sum.Absent, sum.Total)
+		summaries = append(summaries, sum)
+	}
+
+	return summaries, rows.Err()
+}
+
+// TotalAttendance folds per-student summaries into a single summary.
+func TotalAttendance(summaries []models.AttendanceSummary) models.AttendanceSummary {
+	var total models.AttendanceSummary
+
+	for _, s := range summaries {
+		total.Total += s.Total
+		total.Present += s.Present
+		total.Absent += s.Absent
+		total.Late += s.Late
+		total.Excused += s.Excused
+	}
+
+	total.AbsenceRate = absenceRate(total.Absent, total.Total)
+	return total
+}
+
+func attendanceFilters(query string, studentId, classId int, from, to string) (string, []any) {
+	var args []any
+
+	if studentId != 0 {
+		query += " AND a.student_id = ?"
+		args = append(args, studentId)
+	}
+	if classId != 0 {
+		query += " AND a.class_id = ?"
+		args = append(args, classId)
+	}
+	if from != "" {
+		query += " AND a.date >= ?"
+		args = append(args, from)
+	}
+	if to != "" {
+		query += " AND a.date <= ?"
+		args = append(args, to)
+	}
+
+	return query, args
+}
+
+func absenceRate(absent, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(absent) / float64(total)
+}
@@ -0,0 +1,392 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"school-api/internal/models"
+)
+
+// ErrClassFull is returned when placing students would take a class past
+// its capacity.
+var ErrClassFull = errors.New("class is full")
+
+const classColumns = `
+	c.id,
+	c.name,
+	c.grade_level,
+	c.room,
+	c.capacity,
+	c.homeroom_teacher_id,
+	(SELECT COUNT(*) FROM student s WHERE s.class_id = c.id AND s.status = 'active') AS student_count
+`
+
+func scanClass(scanner interface{ Scan(...any) error }, c *models.Class) error {
+	var homeroomTeacherId sql.NullInt64
+
+	err := scanner.Scan(
+		&c.ID,
+		&c.Name,
+		&c.GradeLevel,
+		&c.Room,
+		&c.Capacity,
+		&homeroomTeacherId,
+		&c.StudentCount,
+	)
+	if err != nil {
+		return err
+	}
+
+	c.HomeroomTeacherId = int(homeroomTeacherId.Int64)
+	return nil
+}
+
+func FindClassByID(id int, db *sql.DB) (*models.Class, error) {
+	var c models.Class
+
+	err := scanClass(db.QueryRow("SELECT "+classColumns+" FROM classes c WHERE c.id = ?", id), &c)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if c.HomeroomTeacherId != 0 {
+		c.HomeroomTeacher, err = FindTeacherByID(context.Background(), c.HomeroomTeacherId, db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &c, nil
+}
+
+func FindClass(ctx context.Context, db *sql.DB, search string, filters map[string]string, sort string) ([]models.Class, error) {
+
+	query := "SELECT " + classColumns + " FROM classes c WHERE 1=1"
+
+	var args []any
+
+	// only the classes the caller may see
+	sc, err := scopeFor(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	scopeFilter, scopeArgs := sc.classes("c.id")
+	query += scopeFilter
+	args = append(args, scopeArgs...)
+
+	for key, val := range filters {
+		if val == "" {
+			continue
+		}
+
+		query += " AND c." + key + " = ?"
+		args = append(args, val)
+	}
+
+	if search != "" {
+		query += `
+			AND (
+				c.name LIKE ? OR
+				c.room LIKE ?
+			)
+		`
+		pattern := "%" + search + "%"
+		args = append(args, pattern, pattern)
+	}
+
+	if sort != "" {
+		query += " ORDER BY c." + sort
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var classes []models.Class
+	for rows.Next() {
+		var c models.Class
+		if err := scanClass(rows, &c); err != nil {
+			return nil, err
+		}
+		classes = append(classes, c)
+	}
+
+	return classes, rows.Err()
+}
+
+func AddClass(db *sql.DB, c *models.Class) (sql.Result, error) {
+
+	stmt, err := db.Prepare("INSERT INTO classes (name,grade_level,room,capacity,homeroom_teacher_id) VALUES (?,?,?,?,?)")
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	return stmt.Exec(c.Name, c.GradeLevel, c.Room, c.Capacity, nullableInt(c.HomeroomTeacherId))
+}
+
+// UpdateClass keeps the existing value of every field left empty. The
+// homeroom teacher is only removed when clearHomeroom is set.
+func UpdateClass(db *sql.DB, existingClass, updateClass *models.Class, id int, clearHomeroom bool) (sql.Result, error) {
+	err := scanClass(db.QueryRow("SELECT "+classColumns+" FROM classes c WHERE c.id = ?", id), existingClass)
+
+	if err != nil {
+		return nil, err
+	}
+
+	updateClass.ID = existingClass.ID
+	// Simple conditional updates
+	if updateClass.Name == "" {
+		updateClass.Name = existingClass.Name
+	}
+	if updateClass.GradeLevel == 0 {
+		updateClass.GradeLevel = existingClass.GradeLevel
+	}
+	if updateClass.Room == "" {
+		updateClass.Room = existingClass.Room
+	}
+	if updateClass.Capacity == 0 {
+		updateClass.Capacity = existingClass.Capacity
+	}
+	if updateClass.HomeroomTeacherId == 0 && !clearHomeroom {
+		updateClass.HomeroomTeacherId = existingClass.HomeroomTeacherId
+	}
+
+	if updateClass.Capacity > 0 && updateClass.Capacity < existingClass.StudentCount {
+		return nil, fmt.Errorf("capacity %d is below the %d students already in the class", updateClass.Capacity, existingClass.StudentCount)
+	}
+	updateClass.StudentCount = existingClass.StudentCount
+
+	return db.Exec("UPDATE classes SET name=?, grade_level=?, room=?, capacity=?, homeroom_teacher_id=? WHERE id=?",
+		updateClass.Name, updateClass.GradeLevel, updateClass.Room, updateClass.Capacity, nullableInt(updateClass.HomeroomTeacherId), id)
+}
+
+// DeleteClass removes a class. A class that still has active students is
+// only deleted when reassignTo names another class to move them into, and
+// their current term enrollments move with them. A class with enrollments
+// in other terms is kept so their history is not lost.
+func DeleteClass(db *sql.DB, id int, reassignTo int) error {
+	termId, err := CurrentTermID(db)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	var studentCount int
+	err = tx.QueryRow("SELECT COUNT(*) FROM student WHERE class_id = ? AND status = 'active'", id).Scan(&studentCount)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if studentCount > 0 {
+		if reassignTo == 0 {
+			tx.Rollback()
+			return fmt.Errorf("class %d still has %d students, provide reassign_to to move them", id, studentCount)
+		}
+		if reassignTo == id {
+			tx.Rollback()
+			return fmt.Errorf("cannot reassign students to the class being deleted")
+		}
+
+		var tmp int
+		err = tx.QueryRow("SELECT id FROM classes WHERE id = ?", reassignTo).Scan(&tmp)
+		if err == sql.ErrNoRows {
+			tx.Rollback()
+			return fmt.Errorf("reassignment class %d not found", reassignTo)
+		} else if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := checkClassRoom(tx, reassignTo, studentCount); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		studentIds, err := classStudentIDs(tx, id)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		_, err = tx.Exec("UPDATE student SET class_id = ? WHERE class_id = ? AND status = 'active'", reassignTo, id)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if termId != 0 {
+			for _, studentId := range studentIds {
+				if err := UpsertEnrollment(tx, studentId, reassignTo, termId, "enrolled"); err != nil {
+					tx.Rollback()
+					return err
+				}
+			}
+		}
+	}
+
+	var history int
+	err = tx.QueryRow("SELECT COUNT(*) FROM enrollments WHERE class_id = ?", id).Scan(&history)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if history > 0 {
+		tx.Rollback()
+		return fmt.Errorf("class %d has %d enrollments on record and cannot be deleted", id, history)
+	}
+
+	result, err := tx.Exec("DELETE FROM classes WHERE id = ?", id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if rowsAffected == 0 {
+		tx.Rollback()
+		return sql.ErrNoRows
+	}
+
+	return tx.Commit()
+}
+
+// checkClassRoom locks the class until the transaction ends and returns
+// ErrClassFull when adding students would take it past its capacity. A
+// capacity of 0 means the class has no limit.
+func checkClassRoom(tx *sql.Tx, classId, adding int) error {
+	var capacity, count int
+	err := tx.QueryRow(`
+		SELECT c.capacity, (SELECT COUNT(*) FROM student s WHERE s.class_id = c.id AND s.status = 'active')
+		FROM classes c WHERE c.id = ? FOR UPDATE
+	`, classId).Scan(&capacity, &count)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("class %d not found", classId)
+	} else if err != nil {
+		return err
+	}
+
+	if capacity > 0 && count+adding > capacity {
+		return fmt.Errorf("%w: class %d has %d of %d places taken", ErrClassFull, classId, count, capacity)
+	}
+	return nil
+}
+
+func classStudentIDs(tx *sql.Tx, classId int) ([]int, error) {
+	rows, err := tx.Query("SELECT id FROM student WHERE class_id = ? AND status = 'active' FOR UPDATE", classId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// FindStudentsByClass lists the active students placed in a class. With a
+// termId the placement comes from that term's enrollments instead of the
+// current student.class_id, so past members are listed too.
+func FindStudentsByClass(db *sql.DB, classId int, termId int) ([]models.Student, error) {
+	query := "SELECT id, class_id, first_name, last_name, email FROM student s WHERE class_id = ? AND s.status = 'active'"
+	args := []any{classId}
+
+	if termId != 0 {
+		query = `
+			SELECT s.id, e.class_id, s.first_name, s.last_name, s.email
+			FROM student s
+			JOIN enrollments e ON e.student_id = s.id
+			WHERE e.class_id = ? AND e.term_id = ?
+		`
+		args = append(args, termId)
+	}
+	query += " ORDER BY s.last_name, s.first_name"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var students []models.Student
+	for rows.Next() {
+		var s models.Student
+		if err := rows.Scan(&s.ID, &s.ClassId, &s.FirstName, &s.LastName, &s.Email); err != nil {
+			return nil, err
+		}
+		students = append(students, s)
+	}
+
+	return students, rows.Err()
+}
+
+func FindTeachersByClass(db *sql.DB, classId int, termId int) ([]models.Teacher, error) {
+	termFilter, termArgs := assignmentTermFilter(termId)
+
+	rows, err := db.Query(`
+		SELECT DISTINCT t.id, t.first_name, t.last_name, t.email, c.name AS class, t.subject
+		FROM teacher_assignments ta
+		JOIN teachers t ON ta.teacher_id = t.id
+		JOIN classes c ON ta.class_id = c.id
+		WHERE ta.class_id = ?`+termFilter+`
+		ORDER BY t.last_name, t.first_name
+	`, append([]any{classId}, termArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var teachers []models.Teacher
+	for rows.Next() {
+		var t models.Teacher
+		if err := rows.Scan(&t.ID, &t.FirstName, &t.LastName, &t.Email, &t.Class, &t.Subject); err != nil {
+			return nil, err
+		}
+		teachers = append(teachers, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range teachers {
+		teachers[i].Assignments, err = FindTeacherAssignments(db, teachers[i].ID, termId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return teachers, nil
+}
+
+func nullableInt(v int) any {
+	if v == 0 {
+		return nil
+	}
+	return v
+}
@@ -0,0 +1,575 @@
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+	"school-api/internal/models"
+	"sort"
+	"strings"
+	"time"
+)
+
+const examColumns = `
+	e.id, e.term_id, e.name, e.subject,
+	DATE_FORMAT(e.exam_date, '%Y-%m-%d'), TIME_FORMAT(e.start_time, '%H:%i'),
+	e.duration_minutes
+`
+
+// matches exams x overlapping the window given by the two trailing args
+const examOverlap = `
+	TIMESTAMP(x.exam_date, x.start_time) < ? AND
+	TIMESTAMP(x.exam_date, x.start_time) + INTERVAL x.duration_minutes MINUTE > ?
+`
+
+type querier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func scanExam(scanner interface{ Scan(...any) error }, e *models.Exam) error {
+	return scanner.Scan(&e.ID, &e.TermId, &e.Name, &e.Subject, &e.ExamDate, &e.StartTime, &e.DurationMinutes)
+}
+
+// FindExamByID returns the exam with its classes, rooms and invigilators.
+func FindExamByID(id int, db *sql.DB) (*models.Exam, error) {
+	return findExam(db, id)
+}
+
+func findExam(q querier, id int) (*models.Exam, error) {
+	var e models.Exam
+
+	err := scanExam(q.QueryRow("SELECT "+examColumns+" FROM exams e WHERE e.id = ?", id), &e)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadExamDetails(q, &e); err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+func FindExams(db *sql.DB, termId int, classId int) ([]models.Exam, error) {
+	query := "SELECT " + examColumns + " FROM exams e WHERE 1=1"
+
+	var args []any
+
+	if termId != 0 {
+		query += " AND e.term_id = ?"
+		args = append(args, termId)
+	}
+
+	if classId != 0 {
+		query += " AND EXISTS (SELECT 1 FROM exam_classes ec WHERE ec.exam_id = e.id AND ec.class_id = ?)"
+		args = append(args, classId)
+	}
+
+	query += " ORDER BY e.exam_date, e.start_time"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var exams []models.Exam
+	for rows.Next() {
+		var e models.Exam
+		if err := scanExam(rows, &e); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		exams = append(exams, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range exams {
+		if err := loadExamDetails(db, &exams[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return exams, nil
+}
+
+func loadExamDetails(q querier, e *models.Exam) error {
+	rows, err := q.Query("SELECT class_id FROM exam_classes WHERE exam_id = ? ORDER BY class_id", e.ID)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var classId int
+		if err := rows.Scan(&classId); err != nil {
+			rows.Close()
+			return err
+		}
+		e.ClassIds = append(e.ClassIds, classId)
+	}
+	rows.Close()
+
+	rows, err = q.Query("SELECT room, capacity FROM exam_rooms WHERE exam_id = ? ORDER BY room", e.ID)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var r models.ExamRoom
+		if err := rows.Scan(&r.Room, &r.Capacity); err != nil {
+			rows.Close()
+			return err
+		}
+		e.Rooms = append(e.Rooms, r)
+	}
+	rows.Close()
+
+	rows, err = q.Query(`
+		SELECT ei.teacher_id, CONCAT(t.first_name, ' ', t.last_name), COALESCE(ei.room, '')
+		FROM exam_invigilators ei
+		JOIN teachers t ON ei.teacher_id = t.id
+		WHERE ei.exam_id = ?
+		ORDER BY ei.room, t.last_name
+	`, e.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var inv models.ExamInvigilator
+		if err := rows.Scan(&inv.TeacherId, &inv.TeacherName, &inv.Room); err != nil {
+			return err
+		}
+		e.Invigilators = append(e.Invigilators, inv)
+	}
+
+	return rows.Err()
+}
+
+// AddExam saves an exam with its classes and rooms. It fails when one of
+// the classes already sits an overlapping exam.
+func AddExam(db *sql.DB, e *models.Exam) (sql.Result, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkExamClassOverlap(tx, e, 0); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	res, err := tx.Exec("INSERT INTO exams (term_id, name, subject, exam_date, start_time, duration_minutes) VALUES (?,?,?,?,?,?)",
+		e.TermId, e.Name, e.Subject, e.ExamDate, e.StartTime, e.DurationMinutes)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := saveExamClassesAndRooms(tx, int(id), e); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return res, tx.Commit()
+}
+
+// UpdateExam merges update into the stored exam. ClassIds and Rooms are
+// replaced only when given. The new time is checked against both the
+// classes and the invigilators already assigned.
+func UpdateExam(db *sql.DB, existing, update *models.Exam, id int) (sql.Result, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	err = scanExam(tx.QueryRow("SELECT "+examColumns+" FROM exams e WHERE e.id = ?", id), existing)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := loadExamDetails(tx, existing); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	update.ID = existing.ID
+	// Simple conditional updates
+	if update.TermId == 0 {
+		update.TermId = existing.TermId
+	}
+	if update.Name == "" {
+		update.Name = existing.Name
+	}
+	if update.Subject == "" {
+		update.Subject = existing.Subject
+	}
+	if update.ExamDate == "" {
+		update.ExamDate = existing.ExamDate
+	}
+	if update.StartTime == "" {
+		update.StartTime = existing.StartTime
+	}
+	if update.DurationMinutes == 0 {
+		update.DurationMinutes = existing.DurationMinutes
+	}
+	if update.ClassIds == nil {
+		update.ClassIds = existing.ClassIds
+	}
+	if update.Rooms == nil {
+		update.Rooms = existing.Rooms
+	}
+	update.Invigilators = existing.Invigilators
+
+	if err := checkExamClassOverlap(tx, update, id); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	for _, inv := range update.Invigilators {
+		if err := checkInvigilatorOverlap(tx, update, inv.TeacherId); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	res, err := tx.Exec("UPDATE exams SET term_id=?, name=?, subject=?, exam_date=?, start_time=?, duration_minutes=? WHERE id=?",
+		update.TermId, update.Name, update.Subject, update.ExamDate, update.StartTime, update.DurationMinutes, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if _, err := tx.Exec("DELETE FROM exam_classes WHERE exam_id = ?", id); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if _, err := tx.Exec("DELETE FROM exam_rooms WHERE exam_id = ?", id); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := saveExamClassesAndRooms(tx, id, update); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return res, tx.Commit()
+}
+
+func DeleteExam(db *sql.DB, id int) error {
+	return deleteByID(db, "exams", id)
+}
+
+func saveExamClassesAndRooms(tx *sql.Tx, examId int, e *models.Exam) error {
+	for _, classId := range e.ClassIds {
+		if _, err := tx.Exec("INSERT INTO exam_classes (exam_id, class_id) VALUES (?,?)", examId, classId); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range e.Rooms {
+		if _, err := tx.Exec("INSERT INTO exam_rooms (exam_id, room, capacity) VALUES (?,?,?)", examId, r.Room, r.Capacity); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AssignInvigilator adds a teacher to an exam, optionally to one of its
+// rooms. A teacher cannot invigilate two overlapping exams.
+func AssignInvigilator(db *sql.DB, examId int, inv *models.ExamInvigilator) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	exam, err := findExam(tx, examId)
+	if err != nil {
+		tx.Rollback()
+		return err
+	} else if exam == nil {
+		tx.Rollback()
+		return sql.ErrNoRows
+	}
+
+	if inv.Room != "" && !examHasRoom(exam, inv.Room) {
+		tx.Rollback()
+		return fmt.Errorf("room %s is not used by this exam", inv.Room)
+	}
+
+	if err := checkInvigilatorOverlap(tx, exam, inv.TeacherId); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO exam_invigilators (exam_id, teacher_id, room) VALUES (?,?,?)
+		ON DUPLICATE KEY UPDATE room = VALUES(room)
+	`, examId, inv.TeacherId, nullableString(inv.Room))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func RemoveInvigilator(db *sql.DB, examId, teacherId int) error {
+	result, err := db.Exec("DELETE FROM exam_invigilators WHERE exam_id = ? AND teacher_id = ?", examId, teacherId)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func examHasRoom(e *models.Exam, room string) bool {
+	for _, r := range e.Rooms {
+		if r.Room == room {
+			return true
+		}
+	}
+	return false
+}
+
+func examWindow(e *models.Exam) (string, string, error) {
+	start, err := time.Parse("2006-01-02 15:04", e.ExamDate+" "+e.StartTime)
+	if err != nil {
+		return "", "", err
+	}
+
+	end := start.Add(time.Duration(e.DurationMinutes) * time.Minute)
+
+	const layout = "2006-01-02 15:04:05"
+	return start.Format(layout), end.Format(layout), nil
+}
+
+// checkExamClassOverlap rejects an exam when one of its classes, and so its
+// students, already sits another exam at an overlapping time.
+func checkExamClassOverlap(tx *sql.Tx, e *models.Exam, excludeId int) error {
+	if len(e.ClassIds) == 0 {
+		return nil
+	}
+
+	start, end, err := examWindow(e)
+	if err != nil {
+		return err
+	}
+
+	args := []any{excludeId}
+	for _, classId := range e.ClassIds {
+		args = append(args, classId)
+	}
+	args = append(args, end, start)
+
+	rows, err := tx.Query(`
+		SELECT ec.class_id, x.id, x.name
+		FROM exams x
+		JOIN exam_classes ec ON ec.exam_id = x.id
+		WHERE x.id <> ? AND ec.class_id IN (?`+strings.Repeat(",?", len(e.ClassIds)-1)+`) AND `+examOverlap,
+		args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var conflicts []string
+	for rows.Next() {
+		var classId, examId int
+		var name string
+		if err := rows.Scan(&classId, &examId, &name); err != nil {
+			return err
+		}
+		conflicts = append(conflicts, fmt.Sprintf("class %d already sits exam %d (%s) at that time", classId, examId, name))
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%s", strings.Join(conflicts, "; "))
+	}
+
+	return nil
+}
+
+func checkInvigilatorOverlap(tx *sql.Tx, e *models.Exam, teacherId int) error {
+	start, end, err := examWindow(e)
+	if err != nil {
+		return err
+	}
+
+	var examId int
+	var name string
+	err = tx.QueryRow(`
+		SELECT x.id, x.name
+		FROM exams x
+		JOIN exam_invigilators ei ON ei.exam_id = x.id
+		WHERE x.id <> ? AND ei.teacher_id = ? AND `+examOverlap+`
+		LIMIT 1
+	`, e.ID, teacherId, end, start).Scan(&examId, &name)
+
+	if err == sql.ErrNoRows {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return fmt.Errorf("teacher %d already invigilates exam %d (%s) at that time", teacherId, examId, name)
+}
+
+// GenerateSeatingPlan seats every student of the exam's classes, replacing
+// any previous plan. Classes are interleaved and each student goes to the
+// room that is least full relative to its capacity, so classmates are
+// spread across rooms and rarely sit next to each other.
+func GenerateSeatingPlan(db *sql.DB, examId int) ([]models.ExamSeat, error) {
+	exam, err := FindExamByID(examId, db)
+	if err != nil {
+		return nil, err
+	} else if exam == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	if len(exam.Rooms) == 0 {
+		return nil, fmt.Errorf("exam has no rooms")
+	}
+
+	var byClass [][]models.Student
+	total := 0
+	for _, classId := range exam.ClassIds {
+		students, err := FindStudentsByClass(db, classId, exam.TermId)
+		if err != nil {
+			return nil, err
+		}
+		byClass = append(byClass, students)
+		total += len(students)
+	}
+
+	capacity := 0
+	for _, r := range exam.Rooms {
+		capacity += r.Capacity
+	}
+
+	if total > capacity {
+		return nil, fmt.Errorf("%d students but the rooms only seat %d", total, capacity)
+	}
+
+	// round-robin over classes
+	var order []models.Student
+	for i := 0; len(order) < total; i++ {
+		for _, students := range byClass {
+			if i < len(students) {
+				order = append(order, students[i])
+			}
+		}
+	}
+
+	rooms := append([]models.ExamRoom(nil), exam.Rooms...)
+	sort.SliceStable(rooms, func(i, j int) bool { return rooms[i].Capacity > rooms[j].Capacity })
+	seated := make([]int, len(rooms))
+
+	seats := []models.ExamSeat{}
+	for _, s := range order {
+		best := -1
+		for i, r := range rooms {
+			if seated[i] >= r.Capacity {
+				continue
+			}
+			if best == -1 || seated[i]*rooms[best].Capacity < seated[best]*r.Capacity {
+				best = i
+			}
+		}
+
+		seated[best]++
+		seats = append(seats, models.ExamSeat{
+			StudentId:   s.ID,
+			StudentName: s.FirstName + " " + s.LastName,
+			ClassId:     s.ClassId,
+			Room:        rooms[best].Room,
+			SeatNumber:  seated[best],
+		})
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec("DELETE FROM exam_seats WHERE exam_id = ?", examId); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	for _, seat := range seats {
+		_, err := tx.Exec("INSERT INTO exam_seats (exam_id, student_id, class_id, room, seat_number) VALUES (?,?,?,?,?)",
+			examId, seat.StudentId, seat.ClassId, seat.Room, seat.SeatNumber)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(seats, func(i, j int) bool {
+		if seats[i].Room != seats[j].Room {
+			return seats[i].Room < seats[j].Room
+		}
+		return seats[i].SeatNumber < seats[j].SeatNumber
+	})
+
+	return seats, nil
+}
+
+func FindSeatingPlan(db *sql.DB, examId int) ([]models.ExamSeat, error) {
+	rows, err := db.Query(`
+		SELECT es.student_id, CONCAT(s.first_name, ' ', s.last_name), es.class_id, es.room, es.seat_number
+		FROM exam_seats es
+		JOIN student s ON es.student_id = s.id
+		WHERE es.exam_id = ?
+		ORDER BY es.room, es.seat_number
+	`, examId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var seats []models.ExamSeat
+	for rows.Next() {
+		var seat models.ExamSeat
+		if err := rows.Scan(&seat.StudentId, &seat.StudentName, &seat.ClassId, &seat.Room, &seat.SeatNumber); err != nil {
+			return nil, err
+		}
+		seats = append(seats, seat)
+	}
+
+	return seats, rows.Err()
+}
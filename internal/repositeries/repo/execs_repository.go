@@ -25,7 +25,8 @@ func FindExecByID(id int, db *sql.DB) (*models.Exec, error) {
 			inactive ,
 			role,
 			user_created_at ,
-			password_changed_at
+			password_changed_at,
+			service_account
 			
 		FROM execs WHERE id = ?
     `, id).Scan(
@@ -38,6 +39,7 @@ func FindExecByID(id int, db *sql.DB) (*models.Exec, error) {
 		&e.Role,
 		&e.UserCreatedAt,
 		&e.PasswordChangedAt,
+		&e.ServiceAccount,
 	)
 
 	if err == sql.ErrNoRows {
@@ -63,7 +65,8 @@ func FindExec(db *sql.DB, search string, filters map[string]string, sort string)
 			inactive ,
 			role,
 			user_created_at ,
-			password_changed_at
+			password_changed_at,
+			service_account
 			
 		FROM execs 
 		WHERE 1=1
@@ -123,6 +126,7 @@ func FindExec(db *sql.DB, search string, filters map[string]string, sort string)
 			&e.Role,
 			&e.UserCreatedAt,
 			&e.PasswordChangedAt,
+			&e.ServiceAccount,
 		)
 		if err != nil {
 			return nil, err
@@ -136,13 +140,13 @@ func FindExec(db *sql.DB, search string, filters map[string]string, sort string)
 
 func AddExec(db *sql.DB, t *models.Exec) (sql.Result, error) {
 
-	stmt, err := db.Prepare("INSERT INTO execs (first_name,last_name,email,username,password,role,inactive) VALUES (?,?,?,?,?,?,?)")
+	stmt, err := db.Prepare("INSERT INTO execs (first_name,last_name,email,username,password,role,inactive,service_account) VALUES (?,?,?,?,?,?,?,?)")
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
-	res, err := stmt.Exec(t.FirstName, t.LastName, t.Email, t.Username, t.Password, t.Role, t.Inactive)
+	res, err := stmt.Exec(t.FirstName, t.LastName, t.Email, t.Username, t.Password, t.Role, t.Inactive, t.ServiceAccount)
 	if err != nil {
 		return nil, err
 	}
@@ -240,6 +244,17 @@ func VerifyPassword(currentPass string, userPass string) (bool, error) {
 	return true, nil
 }
 
+// dummyPasswordHash has the shape of a real hash, so checking a password
+// against it costs the same.
+const dummyPasswordHash = "AAAAAAAAAAAAAAAAAAAAAA==.AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="
+
+// VerifyDummyPassword spends as long as VerifyPassword and never matches.
+// Call it when no account has the given name, so the time taken does not
+// tell which names exist.
+func VerifyDummyPassword(password string) {
+	VerifyPassword(password, dummyPasswordHash)
+}
+
 func EncryptPassword(password string) (string, error) {
 
 	salt := make([]byte, 16)
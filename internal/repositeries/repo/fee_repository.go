@@ -0,0 +1,699 @@
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+	"school-api/internal/models"
+)
+
+func FindFeeStructureByID(id int, db *sql.DB) (*models.FeeStructure, error) {
+	var f models.FeeStructure
+	var classId sql.NullInt64
+
+	err := db.QueryRow("SELECT id, term_id, class_id, name, amount FROM fee_structures WHERE id = ?", id).
+		Scan(&f.ID, &f.TermId, &classId, &f.Name, &f.Amount)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	f.ClassId = int(classId.Int64)
+
+	return &f, nil
+}
+
+// FindFeeStructures lists the fees of a term. With a class it returns the
+// fees that apply to that class, including those for every class.
+func FindFeeStructures(db *sql.DB, termId int, classId int) ([]models.FeeStructure, error) {
+	query := "SELECT id, term_id, class_id, name, amount FROM fee_structures WHERE 1=1"
+
+	var args []any
+
+	if termId != 0 {
+		query += " AND term_id = ?"
+		args = append(args, termId)
+	}
+
+	if classId != 0 {
+		query += " AND (class_id IS NULL OR class_id = ?)"
+		args = append(args, classId)
+	}
+
+	query += " ORDER BY term_id, class_id, name"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fees []models.FeeStructure
+	for rows.Next() {
+		var f models.FeeStructure
+		var classId sql.NullInt64
+		if err := rows.Scan(&f.ID, &f.TermId, &classId, &f.Name, &f.Amount); err != nil {
+			return nil, err
+		}
+		f.ClassId = int(classId.Int64)
+		fees = append(fees, f)
+	}
+
+	return fees, rows.Err()
+}
+
+func AddFeeStructure(db *sql.DB, f *models.FeeStructure) (sql.Result, error) {
+	return db.Exec("INSERT INTO fee_structures (term_id, class_id, name, amount) VALUES (?,?,?,?)",
+		f.TermId, nullableInt(f.ClassId), f.Name, f.Amount)
+}
+
+// UpdateFeeStructure changes a fee for invoices generated from now on;
+// existing invoices keep their lines.
+func UpdateFeeStructure(db *sql.DB, existing, update *models.FeeStructure, id int) (sql.Result, error) {
+	var classId sql.NullInt64
+
+	err := db.QueryRow("SELECT id, term_id, class_id, name, amount FROM fee_structures WHERE id = ?", id).
+		Scan(&existing.ID, &existing.TermId, &classId, &existing.Name, &existing.Amount)
+
+	if err != nil {
+		return nil, err
+	}
+
+	existing.ClassId = int(classId.Int64)
+
+	update.ID = existing.ID
+	update.TermId = existing.TermId
+	// Simple conditional updates
+	if update.ClassId == 0 {
+		update.ClassId = existing.ClassId
+	}
+	if update.Name == "" {
+		update.Name = existing.Name
+	}
+	if update.Amount == 0 {
+		update.Amount = existing.Amount
+	}
+
+	return db.Exec("UPDATE fee_structures SET class_id=?, name=?, amount=? WHERE id=?",
+		nullableInt(update.ClassId), update.Name, update.Amount, id)
+}
+
+func DeleteFeeStructure(db *sql.DB, id int) error {
+	return deleteByID(db, "fee_structures", id)
+}
+
+func FindDiscounts(db *sql.DB, studentId int) ([]models.Discount, error) {
+	rows, err := db.Query(`
+		SELECT id, student_id, term_id, name, kind, value, created_at
+		FROM student_discounts
+		WHERE student_id = ?
+		ORDER BY created_at
+	`, studentId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var discounts []models.Discount
+	for rows.Next() {
+		var d models.Discount
+		var termId sql.NullInt64
+		if err := rows.Scan(&d.ID, &d.StudentId, &termId, &d.Name, &d.Kind, &d.Value, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		d.TermId = int(termId.Int64)
+		discounts = append(discounts, d)
+	}
+
+	return discounts, rows.Err()
+}
+
+func AddDiscount(db *sql.DB, d *models.Discount) (sql.Result, error) {
+	return db.Exec("INSERT INTO student_discounts (student_id, term_id, name, kind, value) VALUES (?,?,?,?,?)",
+		d.StudentId, nullableInt(d.TermId), d.Name, d.Kind, d.Value)
+}
+
+func DeleteDiscount(db *sql.DB, id int) error {
+	return deleteByID(db, "student_discounts", id)
+}
+
+const invoiceColumns = `
+	i.id, i.student_id, CONCAT(s.first_name, ' ', s.last_name), i.term_id, i.class_id, i.status,
+	COALESCE(DATE_FORMAT(i.due_date, '%Y-%m-%d'), ''), i.issued_at,
+	COALESCE(SUM(CASE WHEN l.entry_type IN ('charge', 'discount') THEN l.amount END), 0),
+	-COALESCE(SUM(CASE WHEN l.entry_type = 'payment' THEN l.amount END), 0),
+	COALESCE(SUM(l.amount), 0)
+`
+
+const invoiceFrom = `
+	FROM invoices i
+	JOIN student s ON i.student_id = s.id
+	LEFT JOIN ledger_entries l ON l.invoice_id = i.id
+`
+
+func scanInvoice(scanner interface{ Scan(...any) error }, i *models.Invoice) error {
+	return scanner.Scan(&i.ID, &i.StudentId, &i.StudentName, &i.TermId, &i.ClassId, &i.Status,
+		&i.DueDate, &i.IssuedAt, &i.Total, &i.Paid, &i.Outstanding)
+}
+
+// FindInvoiceByID returns the invoice with its lines and payments. Totals
+// are computed from the ledger.
+func FindInvoiceByID(id int, db *sql.DB) (*models.Invoice, error) {
+	var inv models.Invoice
+
+	err := scanInvoice(db.QueryRow("SELECT "+invoiceColumns+invoiceFrom+" WHERE i.id = ? GROUP BY i.id", id), &inv)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query("SELECT id, description, amount FROM invoice_lines WHERE invoice_id = ? ORDER BY id", id)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var line models.InvoiceLine
+		if err := rows.Scan(&line.ID, &line.Description, &line.Amount); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		inv.Lines = append(inv.Lines, line)
+	}
+	rows.Close()
+
+	rows, err = db.Query(`
+		SELECT id, invoice_id, amount, method, reference, received_at, recorded_by
+		FROM payments WHERE invoice_id = ? ORDER BY received_at, id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p models.Payment
+		var recordedBy sql.NullInt64
+		if err := rows.Scan(&p.ID, &p.InvoiceId, &p.Amount, &p.Method, &p.Reference, &p.ReceivedAt, &recordedBy); err != nil {
+			return nil, err
+		}
+		p.RecordedBy = int(recordedBy.Int64)
+		inv.Payments = append(inv.Payments, p)
+	}
+
+	return &inv, rows.Err()
+}
+
+func FindInvoices(db *sql.DB, filters map[string]string) ([]models.Invoice, error) {
+	query := "SELECT " + invoiceColumns + invoiceFrom + " WHERE 1=1"
+
+	var args []any
+
+	for key, val := range filters {
+		if val == "" {
+			continue
+		}
+
+		query += " AND i." + key + " = ?"
+		args = append(args, val)
+	}
+
+	query += " GROUP BY i.id ORDER BY i.issued_at DESC, i.id DESC"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invoices []models.Invoice
+	for rows.Next() {
+		var inv models.Invoice
+		if err := scanInvoice(rows, &inv); err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, inv)
+	}
+
+	return invoices, rows.Err()
+}
+
+// GenerateInvoices creates one invoice per selected student from the term's
+// fee structures and the student's discounts, and writes the matching
+// ledger entries. Students that already have a live invoice for the term,
+// or no applicable fees, are skipped.
+func GenerateInvoices(db *sql.DB, req *models.GenerateInvoicesRequest, createdBy int) (*models.GenerateInvoicesResult, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := generateInvoices(tx, req, createdBy)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return result, tx.Commit()
+}
+
+func generateInvoices(tx *sql.Tx, req *models.GenerateInvoicesRequest, createdBy int) (*models.GenerateInvoicesResult, error) {
+	type student struct {
+		id      int
+		name    string
+		classId int
+	}
+
+	wanted := map[int]bool{}
+	for _, id := range req.StudentIds {
+		wanted[id] = true
+	}
+
+	// each student's class in the term, falling back to their current class
+	rows, err := tx.Query(`
+		SELECT s.id, CONCAT(s.first_name, ' ', s.last_name),
+			COALESCE((SELECT e.class_id FROM enrollments e WHERE e.student_id = s.id AND e.term_id = ?), s.class_id)
+		FROM student s
+		WHERE s.status = ?
+		ORDER BY s.id
+	`, req.TermId, models.StudentActive)
+	if err != nil {
+		return nil, err
+	}
+
+	var students []student
+	for rows.Next() {
+		var s student
+		if err := rows.Scan(&s.id, &s.name, &s.classId); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if len(wanted) > 0 && !wanted[s.id] {
+			continue
+		}
+		if len(wanted) == 0 && req.ClassId != 0 && s.classId != req.ClassId {
+			continue
+		}
+		students = append(students, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(wanted) > 0 && len(students) != len(wanted) {
+		return nil, fmt.Errorf("some student_ids are unknown or not active")
+	}
+
+	rows, err = tx.Query("SELECT class_id, name, amount FROM fee_structures WHERE term_id = ? ORDER BY id", req.TermId)
+	if err != nil {
+		return nil, err
+	}
+
+	type fee struct {
+		classId int
+		name    string
+		amount  int64
+	}
+
+	var fees []fee
+	for rows.Next() {
+		var f fee
+		var classId sql.NullInt64
+		if err := rows.Scan(&classId, &f.name, &f.amount); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		f.classId = int(classId.Int64)
+		fees = append(fees, f)
+	}
+	rows.Close()
+
+	result := &models.GenerateInvoicesResult{Invoices: []models.Invoice{}, Skipped: []int{}}
+
+	for _, s := range students {
+		var live int
+		err := tx.QueryRow("SELECT COUNT(*) FROM invoices WHERE student_id = ? AND term_id = ? AND status <> ?",
+			s.id, req.TermId, models.InvoiceVoid).Scan(&live)
+		if err != nil {
+			return nil, err
+		}
+
+		inv := models.Invoice{
+			StudentId:   s.id,
+			StudentName: s.name,
+			TermId:      req.TermId,
+			ClassId:     s.classId,
+			Status:      models.InvoiceOpen,
+			DueDate:     req.DueDate,
+		}
+
+		var subtotal int64
+		for _, f := range fees {
+			if f.classId == 0 || f.classId == s.classId {
+				inv.Lines = append(inv.Lines, models.InvoiceLine{Description: f.name, Amount: f.amount})
+				subtotal += f.amount
+			}
+		}
+
+		if live > 0 || len(inv.Lines) == 0 {
+			result.Skipped = append(result.Skipped, s.id)
+			continue
+		}
+
+		discounts, err := studentDiscounts(tx, s.id, req.TermId)
+		if err != nil {
+			return nil, err
+		}
+
+		total := subtotal
+		for _, d := range discounts {
+			amount := d.Value
+			if d.Kind == models.DiscountPercent {
+				// round half up
+				amount = (subtotal*d.Value + 5000) / 10000
+			}
+			// a discount never takes the invoice below zero
+			if amount > total {
+				amount = total
+			}
+			if amount <= 0 {
+				continue
+			}
+
+			inv.Lines = append(inv.Lines, models.InvoiceLine{Description: d.Name, Amount: -amount})
+			total -= amount
+		}
+
+		inv.Total = total
+		inv.Outstanding = total
+		if total == 0 {
+			inv.Status = models.InvoicePaid
+		}
+
+		res, err := tx.Exec("INSERT INTO invoices (student_id, term_id, class_id, status, due_date) VALUES (?,?,?,?,?)",
+			inv.StudentId, inv.TermId, inv.ClassId, inv.Status, nullableString(inv.DueDate))
+		if err != nil {
+			return nil, err
+		}
+
+		id, err := res.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+		inv.ID = int(id)
+
+		for i, line := range inv.Lines {
+			res, err := tx.Exec("INSERT INTO invoice_lines (invoice_id, description, amount) VALUES (?,?,?)", inv.ID, line.Description, line.Amount)
+			if err != nil {
+				return nil, err
+			}
+
+			lineId, err := res.LastInsertId()
+			if err != nil {
+				return nil, err
+			}
+			inv.Lines[i].ID = int(lineId)
+
+			entryType := models.LedgerCharge
+			if line.Amount < 0 {
+				entryType = models.LedgerDiscount
+			}
+
+			if err := addLedgerEntry(tx, inv.ID, inv.StudentId, entryType, line.Amount, line.Description, 0, createdBy); err != nil {
+				return nil, err
+			}
+		}
+
+		result.Invoices = append(result.Invoices, inv)
+	}
+
+	return result, nil
+}
+
+func studentDiscounts(tx *sql.Tx, studentId, termId int) ([]models.Discount, error) {
+	rows, err := tx.Query(`
+		SELECT id, name, kind, value FROM student_discounts
+		WHERE student_id = ? AND (term_id IS NULL OR term_id = ?)
+		ORDER BY id
+	`, studentId, termId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var discounts []models.Discount
+	for rows.Next() {
+		var d models.Discount
+		if err := rows.Scan(&d.ID, &d.Name, &d.Kind, &d.Value); err != nil {
+			return nil, err
+		}
+		discounts = append(discounts, d)
+	}
+
+	return discounts, rows.Err()
+}
+
+func addLedgerEntry(tx *sql.Tx, invoiceId, studentId int, entryType string, amount int64, description string, paymentId, createdBy int) error {
+	_, err := tx.Exec(`
+		INSERT INTO ledger_entries (invoice_id, student_id, entry_type, amount, description, payment_id, created_by)
+		VALUES (?,?,?,?,?,?,?)
+	`, invoiceId, studentId, entryType, amount, description, nullableInt(paymentId), nullableInt(createdBy))
+	return err
+}
+
+// lockInvoice locks the invoice row and returns its student, status and
+// outstanding balance.
+func lockInvoice(tx *sql.Tx, id int) (int, string, int64, error) {
+	var studentId int
+	var status string
+
+	err := tx.QueryRow("SELECT student_id, status FROM invoices WHERE id = ? FOR UPDATE", id).Scan(&studentId, &status)
+	if err != nil {
+		return 0, "", 0, err
+	}
+
+	var outstanding int64
+	err = tx.QueryRow("SELECT COALESCE(SUM(amount), 0) FROM ledger_entries WHERE invoice_id = ?", id).Scan(&outstanding)
+
+	return studentId, status, outstanding, err
+}
+
+// RecordPayment records a full or partial payment against an invoice. It
+// returns sql.ErrNoRows when the invoice does not exist.
+func RecordPayment(db *sql.DB, invoiceId int, p *models.Payment, recordedBy int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	studentId, status, outstanding, err := lockInvoice(tx, invoiceId)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if status == models.InvoiceVoid {
+		tx.Rollback()
+		return fmt.Errorf("invoice is void")
+	}
+
+	if p.Amount > outstanding {
+		tx.Rollback()
+		return fmt.Errorf("payment of %d exceeds the outstanding balance of %d", p.Amount, outstanding)
+	}
+
+	res, err := tx.Exec("INSERT INTO payments (invoice_id, amount, method, reference, recorded_by) VALUES (?,?,?,?,?)",
+		invoiceId, p.Amount, p.Method, p.Reference, nullableInt(recordedBy))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	p.ID = int(id)
+	p.InvoiceId = invoiceId
+	p.RecordedBy = recordedBy
+
+	description := "Payment by " + p.Method
+	if p.Reference != "" {
+		description += " (" + p.Reference + ")"
+	}
+
+	if err := addLedgerEntry(tx, invoiceId, studentId, models.LedgerPayment, -p.Amount, description, p.ID, recordedBy); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if p.Amount == outstanding {
+		if _, err := tx.Exec("UPDATE invoices SET status = ? WHERE id = ?", models.InvoicePaid, invoiceId); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// VoidInvoice cancels an unpaid invoice by writing off its outstanding
+// balance in the ledger. Invoices with payments cannot be voided.
+func VoidInvoice(db *sql.DB, id int, reason string, voidedBy int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	studentId, status, outstanding, err := lockInvoice(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if status == models.InvoiceVoid {
+		tx.Rollback()
+		return fmt.Errorf("invoice is already void")
+	}
+
+	var payments int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM payments WHERE invoice_id = ?", id).Scan(&payments); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if payments > 0 {
+		tx.Rollback()
+		return fmt.Errorf("invoice has payments and cannot be voided")
+	}
+
+	description := "Voided"
+	if reason != "" {
+		description += ": " + reason
+	}
+
+	if err := addLedgerEntry(tx, id, studentId, models.LedgerVoid, -outstanding, description, 0, voidedBy); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec("UPDATE invoices SET status = ? WHERE id = ?", models.InvoiceVoid, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func FindLedger(db *sql.DB, studentId int, invoiceId int) ([]models.LedgerEntry, error) {
+	query := `
+		SELECT id, invoice_id, student_id, entry_type, amount, description, payment_id, created_by, created_at
+		FROM ledger_entries WHERE 1=1
+	`
+
+	var args []any
+
+	if studentId != 0 {
+		query += " AND student_id = ?"
+		args = append(args, studentId)
+	}
+
+	if invoiceId != 0 {
+		query += " AND invoice_id = ?"
+		args = append(args, invoiceId)
+	}
+
+	query += " ORDER BY id"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.LedgerEntry
+	for rows.Next() {
+		var e models.LedgerEntry
+		var paymentId, createdBy sql.NullInt64
+		err := rows.Scan(&e.ID, &e.InvoiceId, &e.StudentId, &e.Type, &e.Amount, &e.Description, &paymentId, &createdBy, &e.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		e.PaymentId = int(paymentId.Int64)
+		e.CreatedBy = int(createdBy.Int64)
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+var balanceGroups = map[string]string{
+	"student": "i.student_id, CONCAT(s.first_name, ' ', s.last_name)",
+	"class":   "i.class_id, c.name",
+	"term":    "i.term_id, t.name",
+}
+
+func IsValidBalanceGroup(group string) bool {
+	_, ok := balanceGroups[group]
+	return ok
+}
+
+// FindBalances totals the ledger per student, class or term, optionally
+// limited to one term, class or student.
+func FindBalances(db *sql.DB, group string, filters map[string]string) ([]models.BalanceRow, error) {
+	columns := balanceGroups[group]
+
+	query := `
+		SELECT ` + columns + `,
+			COUNT(DISTINCT i.id),
+			COALESCE(SUM(CASE WHEN l.entry_type = 'charge' THEN l.amount END), 0),
+			-COALESCE(SUM(CASE WHEN l.entry_type = 'discount' THEN l.amount END), 0),
+			-COALESCE(SUM(CASE WHEN l.entry_type = 'payment' THEN l.amount END), 0),
+			-COALESCE(SUM(CASE WHEN l.entry_type = 'void' THEN l.amount END), 0),
+			COALESCE(SUM(l.amount), 0)
+		FROM invoices i
+		JOIN student s ON i.student_id = s.id
+		JOIN classes c ON i.class_id = c.id
+		JOIN terms t ON i.term_id = t.id
+		LEFT JOIN ledger_entries l ON l.invoice_id = i.id
+		WHERE 1=1
+	`
+
+	var args []any
+
+	for key, val := range filters {
+		if val == "" {
+			continue
+		}
+
+		query += " AND i." + key + " = ?"
+		args = append(args, val)
+	}
+
+	query += " GROUP BY " + columns + " ORDER BY 2"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var balances []models.BalanceRow
+	for rows.Next() {
+		var b models.BalanceRow
+		if err := rows.Scan(&b.ID, &b.Name, &b.Invoices, &b.Charged, &b.Discounts, &b.Paid, &b.Voided, &b.Outstanding); err != nil {
+			return nil, err
+		}
+		balances = append(balances, b)
+	}
+
+	return balances, rows.Err()
+}
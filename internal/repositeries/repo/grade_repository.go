@@ -0,0 +1,359 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"school-api/internal/models"
+)
+
+var assessmentTypes = map[string]bool{
+	"quiz":     true,
+	"test":     true,
+	"exam":     true,
+	"homework": true,
+	"project":  true,
+	"other":    true,
+}
+
+func IsValidAssessmentType(t string) bool {
+	return assessmentTypes[t]
+}
+
+const assessmentColumns = `
+	a.id,
+	a.class_id,
+	a.subject,
+	a.title,
+	a.type,
+	a.max_score,
+	a.weight,
+	COALESCE(DATE_FORMAT(a.due_date, '%Y-%m-%d'), ''),
+	COALESCE(a.term_id, 0),
+	a.created_at
+`
+
+func scanAssessment(scanner interface{ Scan(...any) error }, a *models.Assessment) error {
+	return scanner.Scan(
+		&a.ID,
+		&a.ClassId,
+		&a.Subject,
+		&a.Title,
+		&a.Type,
+		&a.MaxScore,
+		&a.Weight,
+		&a.DueDate,
+		&a.TermId,
+		&a.CreatedAt,
+	)
+}
+
+func FindAssessmentByID(id int, db *sql.DB) (*models.Assessment, error) {
+	var a models.Assessment
+
+	err := scanAssessment(db.QueryRow("SELECT "+assessmentColumns+" FROM assessments a WHERE a.id = ?", id), &a)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &a, nil
+}
+
+func FindAssessment(
+	ctx context.Context,
+	db *sql.DB,
+	filters map[string]string,
+	sort string,
+	limit int,
+	page int,
+) ([]models.Assessment, models.PaginationMeta, error) {
+
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	baseQuery := " FROM assessments a WHERE 1=1"
+
+	var args []any
+
+	// only the classes the caller may see
+	sc, err := scopeFor(ctx, db)
+	if err != nil {
+		return nil, models.PaginationMeta{}, err
+	}
+	scopeFilter, scopeArgs := sc.classes("a.class_id")
+	baseQuery += scopeFilter
+	args = append(args, scopeArgs...)
+
+	for key, val := range filters {
+		if val == "" {
+			continue
+		}
+
+		baseQuery += " AND a." + key + " = ?"
+		args = append(args, val)
+	}
+
+	var totalRecords int
+	err = db.QueryRow("SELECT COUNT(*)"+baseQuery, args...).Scan(&totalRecords)
+	if err != nil {
+		return nil, models.PaginationMeta{}, err
+	}
+
+	dataQuery := "SELECT " + assessmentColumns + baseQuery
+
+	if sort != "" {
+		dataQuery += " ORDER BY a." + sort
+	}
+
+	offset := (page - 1) * limit
+	dataQuery += " LIMIT ? OFFSET ?"
+
+	rows, err := db.Query(dataQuery, append(args, limit, offset)...)
+	if err != nil {
+		return nil, models.PaginationMeta{}, err
+	}
+	defer rows.Close()
+
+	var assessments []models.Assessment
+	for rows.Next() {
+		var a models.Assessment
+		if err := scanAssessment(rows, &a); err != nil {
+			return nil, models.PaginationMeta{}, err
+		}
+		assessments = append(assessments, a)
+	}
+
+	totalPages := int(math.Ceil(float64(totalRecords) / float64(limit)))
+
+	meta := models.PaginationMeta{
+		TotalRecords: totalRecords,
+		TotalPages:   totalPages,
+		Page:         page,
+		Limit:        limit,
+		HasNext:      page < totalPages,
+		HasPrev:      page > 1,
+	}
+
+	return assessments, meta, rows.Err()
+}
+
+func AddAssessment(db execer, a *models.Assessment) (sql.Result, error) {
+	return db.Exec("INSERT INTO assessments (class_id,subject,title,type,max_score,weight,due_date,term_id) VALUES (?,?,?,?,?,?,?,?)", a.ClassId, a.Subject, a.Title, a.Type, a.MaxScore, a.Weight, nullableString(a.DueDate), nullableInt(a.TermId))
+}
+
+func UpdateAssessment(db *sql.DB, existing, update *models.Assessment, id int) (sql.Result, error) {
+	err := scanAssessment(db.QueryRow("SELECT "+assessmentColumns+" FROM assessments a WHERE a.id = ?", id), existing)
+
+	if err != nil {
+		return nil, err
+	}
+
+	update.ID = existing.ID
+	update.ClassId = existing.ClassId
+	update.CreatedAt = existing.CreatedAt
+	// Simple conditional updates
+	if update.Subject == "" {
+		update.Subject = existing.Subject
+	}
+	if update.Title == "" {
+		update.Title = existing.Title
+	}
+	if update.Type == "" {
+		update.Type = existing.Type
+	}
+	if update.MaxScore == 0 {
+		update.MaxScore = existing.MaxScore
+	}
+	if update.Weight == 0 {
+		update.Weight = existing.Weight
+	}
+	if update.DueDate == "" {
+		update.DueDate = existing.DueDate
+	}
+	if update.TermId == 0 {
+		update.TermId = existing.TermId
+	}
+
+	if update.MaxScore < existing.MaxScore {
+		var highest sql.NullFloat64
+		err = db.QueryRow("SELECT MAX(score) FROM scores WHERE assessment_id = ?", id).Scan(&highest)
+		if err != nil {
+			return nil, err
+		}
+		if highest.Valid && highest.Float64 > update.MaxScore {
+			return nil, fmt.Errorf("max_score %.2f is below an existing score of %.2f", update.MaxScore, highest.Float64)
+		}
+	}
+
+	return db.Exec("UPDATE assessments SET subject=?, title=?, type=?, max_score=?, weight=?, due_date=?, term_id=? WHERE id=?",
+		update.Subject, update.Title, update.Type, update.MaxScore, update.Weight, nullableString(update.DueDate), nullableInt(update.TermId), id)
+}
+
+func DeleteAssessment(db *sql.DB, id int) error {
+	result, err := db.Exec("DELETE FROM assessments WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// SaveScores records scores for an assessment in one transaction. Existing
+// scores for the same student are replaced.
+func SaveScores(db *sql.DB, assessment *models.Assessment, scores []models.Score, gradedBy int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := saveScores(tx, assessment, scores, gradedBy); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// saveScores is SaveScores inside the caller's transaction.
+func saveScores(tx *sql.Tx, assessment *models.Assessment, scores []models.Score, gradedBy int) error {
+	for _, s := range scores {
+		if s.Score < 0 || s.Score > assessment.MaxScore {
+			return fmt.Errorf("score %.2f for student %d must be between 0 and %.2f", s.Score, s.StudentId, assessment.MaxScore)
+		}
+
+		// the student's class in the assessment's term, falling back to
+		// their current class
+		var classId int
+		err := tx.QueryRow(`
+			SELECT COALESCE(
+				(SELECT e.class_id FROM enrollments e WHERE e.student_id = s.id AND e.term_id = ?),
+				s.class_id
+			)
+			FROM student s WHERE s.id = ?
+		`, assessment.TermId, s.StudentId).Scan(&classId)
+
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("student with id %d not found", s.StudentId)
+		} else if err != nil {
+			return err
+		}
+
+		if classId != assessment.ClassId {
+			return fmt.Errorf("student with id %d is not in class %d", s.StudentId, assessment.ClassId)
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO scores (assessment_id, student_id, score, comment, graded_by)
+			VALUES (?,?,?,?,?)
+			ON DUPLICATE KEY UPDATE score = VALUES(score), comment = VALUES(comment), graded_by = VALUES(graded_by)
+		`, assessment.ID, s.StudentId, s.Score, s.Comment, nullableInt(gradedBy))
+
+		if err != nil {
+			return fmt.Errorf("failed to save score for student %d: %w", s.StudentId, err)
+		}
+	}
+
+	return nil
+}
+
+func FindScores(db *sql.DB, assessmentId int) ([]models.Score, error) {
+	rows, err := db.Query(`
+		SELECT sc.id, sc.assessment_id, sc.student_id, CONCAT(s.first_name, ' ', s.last_name), sc.score, sc.comment, sc.graded_by
+		FROM scores sc
+		JOIN student s ON sc.student_id = s.id
+		WHERE sc.assessment_id = ?
+		ORDER BY s.last_name, s.first_name
+	`, assessmentId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scores []models.Score
+	for rows.Next() {
+		var s models.Score
+		var gradedBy sql.NullInt64
+
+		if err := rows.Scan(&s.ID, &s.AssessmentId, &s.StudentId, &s.StudentName, &s.Score, &s.Comment, &gradedBy); err != nil {
+			return nil, err
+		}
+
+		s.GradedBy = int(gradedBy.Int64)
+		scores = append(scores, s)
+	}
+
+	return scores, rows.Err()
+}
+
+// FindSubjectAverages computes a student's weighted average per subject and
+// term as a percentage. Each scored assessment contributes
+// score/max_score scaled by its weight; unscored assessments are ignored.
+func FindSubjectAverages(db *sql.DB, studentId int, termId int, subject string) ([]models.SubjectAverage, error) {
+	query := `
+		SELECT a.subject, COALESCE(a.term_id, 0), COALESCE(MIN(t.name), ''),
+			SUM(sc.score / a.max_score * a.weight) / SUM(a.weight) * 100,
+			COUNT(*),
+			SUM(a.weight)
+		FROM scores sc
+		JOIN assessments a ON sc.assessment_id = a.id
+		LEFT JOIN terms t ON a.term_id = t.id
+		WHERE sc.student_id = ? AND a.max_score > 0
+	`
+	args := []any{studentId}
+
+	if termId != 0 {
+		query += " AND a.term_id = ?"
+		args = append(args, termId)
+	}
+	if subject != "" {
+		query += " AND a.subject = ?"
+		args = append(args, subject)
+	}
+
+	query += " GROUP BY a.subject, a.term_id HAVING SUM(a.weight) > 0 ORDER BY MIN(t.start_date), a.term_id, a.subject"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var averages []models.SubjectAverage
+	for rows.Next() {
+		var avg models.SubjectAverage
+		if err := rows.Scan(&avg.Subject, &avg.TermId, &avg.Term, &avg.Average, &avg.AssessmentCount, &avg.TotalWeight); err != nil {
+			return nil, err
+		}
+
+		avg.Average = math.Round(avg.Average*100) / 100
+		averages = append(averages, avg)
+	}
+
+	return averages, rows.Err()
+}
+
+func nullableString(v string) any {
+	if v == "" {
+		return nil
+	}
+	return v
+}
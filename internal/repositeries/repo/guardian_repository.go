@@ -0,0 +1,284 @@
+package repo
+
+import (
+	"database/sql"
+	"school-api/internal/models"
+)
+
+const guardianColumns = "g.id, g.first_name, g.last_name, g.email, g.phone, g.alt_phone, g.address"
+
+func scanGuardian(scanner interface{ Scan(...any) error }, g *models.Guardian) error {
+	return scanner.Scan(&g.ID, &g.FirstName, &g.LastName, &g.Email, &g.Phone, &g.AltPhone, &g.Address)
+}
+
+func FindGuardianByID(id int, db *sql.DB) (*models.Guardian, error) {
+	var g models.Guardian
+
+	err := scanGuardian(db.QueryRow("SELECT "+guardianColumns+" FROM guardians g WHERE g.id = ?", id), &g)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &g, nil
+}
+
+func FindGuardian(db *sql.DB, search string, filters map[string]string, sort string) ([]models.Guardian, error) {
+	query := "SELECT " + guardianColumns + " FROM guardians g WHERE 1=1"
+
+	var args []any
+
+	for key, val := range filters {
+		if val == "" {
+			continue
+		}
+
+		query += " AND g." + key + " = ?"
+		args = append(args, val)
+	}
+
+	if search != "" {
+		query += `
+			AND (
+				g.first_name LIKE ? OR
+				g.last_name  LIKE ? OR
+				g.email      LIKE ? OR
+				g.phone      LIKE ?
+			)
+		`
+		pattern := "%" + search + "%"
+		args = append(args, pattern, pattern, pattern, pattern)
+	}
+
+	if sort != "" {
+		query += " ORDER BY g." + sort
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var guardians []models.Guardian
+	for rows.Next() {
+		var g models.Guardian
+		if err := scanGuardian(rows, &g); err != nil {
+			return nil, err
+		}
+		guardians = append(guardians, g)
+	}
+
+	return guardians, rows.Err()
+}
+
+func AddGuardian(db execer, g *models.Guardian) (sql.Result, error) {
+	return db.Exec("INSERT INTO guardians (first_name,last_name,email,phone,alt_phone,address) VALUES (?,?,?,?,?,?)",
+		g.FirstName, g.LastName, g.Email, g.Phone, g.AltPhone, g.Address)
+}
+
+func UpdateGuardian(db *sql.DB, existing, update *models.Guardian, id int) (sql.Result, error) {
+	err := scanGuardian(db.QueryRow("SELECT "+guardianColumns+" FROM guardians g WHERE g.id = ?", id), existing)
+
+	if err != nil {
+		return nil, err
+	}
+
+	update.ID = existing.ID
+	// Simple conditional updates
+	if update.FirstName == "" {
+		update.FirstName = existing.FirstName
+	}
+	if update.LastName == "" {
+		update.LastName = existing.LastName
+	}
+	if update.Email == "" {
+		update.Email = existing.Email
+	}
+	if update.Phone == "" {
+		update.Phone = existing.Phone
+	}
+	if update.AltPhone == "" {
+		update.AltPhone = existing.AltPhone
+	}
+	if update.Address == "" {
+		update.Address = existing.Address
+	}
+
+	return db.Exec("UPDATE guardians SET first_name=?, last_name=?, email=?, phone=?, alt_phone=?, address=? WHERE id=?",
+		update.FirstName, update.LastName, update.Email, update.Phone, update.AltPhone, update.Address, id)
+}
+
+func DeleteGuardian(db *sql.DB, id int) error {
+	return deleteByID(db, "guardians", id)
+}
+
+// FindStudentGuardians lists the guardians linked to a student, primary
+// contact first.
+func FindStudentGuardians(db *sql.DB, studentId int) ([]models.StudentGuardian, error) {
+	rows, err := db.Query(`
+		SELECT sg.student_id, sg.guardian_id, sg.relationship, sg.is_primary_contact, sg.is_emergency_contact, sg.can_pickup,
+			`+guardianColumns+`
+		FROM student_guardians sg
+		JOIN guardians g ON sg.guardian_id = g.id
+		WHERE sg.student_id = ?
+		ORDER BY sg.is_primary_contact DESC, g.last_name, g.first_name
+	`, studentId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []models.StudentGuardian
+	for rows.Next() {
+		var sg models.StudentGuardian
+		var g models.Guardian
+
+		err := rows.Scan(&sg.StudentId, &sg.GuardianId, &sg.Relationship, &sg.IsPrimaryContact, &sg.IsEmergencyContact, &sg.CanPickup,
+			&g.ID, &g.FirstName, &g.LastName, &g.Email, &g.Phone, &g.AltPhone, &g.Address)
+		if err != nil {
+			return nil, err
+		}
+
+		sg.Guardian = &g
+		links = append(links, sg)
+	}
+
+	return links, rows.Err()
+}
+
+func FindGuardianStudents(db *sql.DB, guardianId int) ([]models.StudentGuardian, error) {
+	rows, err := db.Query(`
+		SELECT sg.student_id, sg.guardian_id, sg.relationship, sg.is_primary_contact, sg.is_emergency_contact, sg.can_pickup,
+			s.id, s.first_name, s.last_name, s.email, s.class_id
+		FROM student_guardians sg
+		JOIN student s ON sg.student_id = s.id
+		WHERE sg.guardian_id = ?
+		ORDER BY s.last_name, s.first_name
+	`, guardianId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []models.StudentGuardian
+	for rows.Next() {
+		var sg models.StudentGuardian
+		var s models.Student
+
+		err := rows.Scan(&sg.StudentId, &sg.GuardianId, &sg.Relationship, &sg.IsPrimaryContact, &sg.IsEmergencyContact, &sg.CanPickup,
+			&s.ID, &s.FirstName, &s.LastName, &s.Email, &s.ClassId)
+		if err != nil {
+			return nil, err
+		}
+
+		sg.Student = &s
+		links = append(links, sg)
+	}
+
+	return links, rows.Err()
+}
+
+// LinkGuardian links a guardian to a student, creating the guardian first
+// when link.GuardianId is 0. A new primary contact replaces the old one.
+func LinkGuardian(db *sql.DB, link *models.StudentGuardian) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if link.GuardianId == 0 {
+		res, err := AddGuardian(tx, link.Guardian)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		id, err := res.LastInsertId()
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		link.GuardianId = int(id)
+		link.Guardian.ID = int(id)
+	}
+
+	if link.IsPrimaryContact {
+		if _, err := tx.Exec("UPDATE student_guardians SET is_primary_contact = FALSE WHERE student_id = ?", link.StudentId); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO student_guardians (student_id, guardian_id, relationship, is_primary_contact, is_emergency_contact, can_pickup)
+		VALUES (?,?,?,?,?,?)
+	`, link.StudentId, link.GuardianId, link.Relationship, link.IsPrimaryContact, link.IsEmergencyContact, link.CanPickup)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpdateGuardianLink replaces the relationship and flags of an existing
+// link. It returns sql.ErrNoRows when the link does not exist.
+func UpdateGuardianLink(db *sql.DB, link *models.StudentGuardian) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	var existing string
+	err = tx.QueryRow("SELECT relationship FROM student_guardians WHERE student_id = ? AND guardian_id = ?", link.StudentId, link.GuardianId).Scan(&existing)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if link.Relationship == "" {
+		link.Relationship = existing
+	}
+
+	if link.IsPrimaryContact {
+		if _, err := tx.Exec("UPDATE student_guardians SET is_primary_contact = FALSE WHERE student_id = ?", link.StudentId); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	_, err = tx.Exec(`
+		UPDATE student_guardians SET relationship = ?, is_primary_contact = ?, is_emergency_contact = ?, can_pickup = ?
+		WHERE student_id = ? AND guardian_id = ?
+	`, link.Relationship, link.IsPrimaryContact, link.IsEmergencyContact, link.CanPickup, link.StudentId, link.GuardianId)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func UnlinkGuardian(db *sql.DB, studentId, guardianId int) error {
+	result, err := db.Exec("DELETE FROM student_guardians WHERE student_id = ? AND guardian_id = ?", studentId, guardianId)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,525 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"school-api/internal/models"
+	"time"
+)
+
+const homeworkColumns = `
+	h.id, h.term_id, h.class_id, h.subject, h.title, h.instructions,
+	DATE_FORMAT(h.due_date, '%Y-%m-%d'), h.max_score, h.assessment_id, h.created_by, h.created_at
+`
+
+const submissionColumns = `
+	hs.id, hs.homework_id, hs.student_id, CONCAT(s.first_name, ' ', s.last_name), hs.status, hs.late, hs.content, hs.file_url,
+	hs.submitted_at, hs.score, COALESCE(hs.feedback, ''), hs.graded_by, COALESCE(hs.graded_at, '')
+`
+
+func scanHomework(scanner interface{ Scan(...any) error }, h *models.Homework) error {
+	var termId, assessmentId, createdBy sql.NullInt64
+
+	err := scanner.Scan(&h.ID, &termId, &h.ClassId, &h.Subject, &h.Title, &h.Instructions,
+		&h.DueDate, &h.MaxScore, &assessmentId, &createdBy, &h.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	h.TermId = int(termId.Int64)
+	h.AssessmentId = int(assessmentId.Int64)
+	h.CreatedBy = int(createdBy.Int64)
+
+	return nil
+}
+
+func scanSubmission(scanner interface{ Scan(...any) error }, sub *models.Submission) error {
+	var score sql.NullFloat64
+	var gradedBy sql.NullInt64
+
+	err := scanner.Scan(&sub.ID, &sub.HomeworkId, &sub.StudentId, &sub.StudentName, &sub.Status, &sub.Late, &sub.Content, &sub.FileURL,
+		&sub.SubmittedAt, &score, &sub.Feedback, &gradedBy, &sub.GradedAt)
+	if err != nil {
+		return err
+	}
+
+	if score.Valid {
+		sub.Score = &score.Float64
+	}
+	sub.GradedBy = int(gradedBy.Int64)
+
+	return nil
+}
+
+func FindHomeworkByID(id int, db *sql.DB) (*models.Homework, error) {
+	var h models.Homework
+
+	err := scanHomework(db.QueryRow("SELECT "+homeworkColumns+" FROM homework h WHERE h.id = ?", id), &h)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	h.Attachments, err = findHomeworkAttachments(db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &h, nil
+}
+
+func FindHomework(ctx context.Context, db *sql.DB, filters map[string]string, termId int) ([]models.Homework, error) {
+	query := "SELECT " + homeworkColumns + " FROM homework h WHERE 1=1"
+
+	var args []any
+
+	// only the classes the caller may see
+	sc, err := scopeFor(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	scopeFilter, scopeArgs := sc.classes("h.class_id")
+	query += scopeFilter
+	args = append(args, scopeArgs...)
+
+	if termId != 0 {
+		query += " AND h.term_id = ?"
+		args = append(args, termId)
+	}
+
+	for key, val := range filters {
+		if val == "" {
+			continue
+		}
+
+		query += " AND h." + key + " = ?"
+		args = append(args, val)
+	}
+
+	query += " ORDER BY h.due_date DESC, h.id DESC"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var homework []models.Homework
+	for rows.Next() {
+		var h models.Homework
+		if err := scanHomework(rows, &h); err != nil {
+			return nil, err
+		}
+		homework = append(homework, h)
+	}
+
+	return homework, rows.Err()
+}
+
+func findHomeworkAttachments(db *sql.DB, homeworkId int) ([]models.HomeworkAttachment, error) {
+	rows, err := db.Query("SELECT id, name, url FROM homework_attachments WHERE homework_id = ? ORDER BY id", homeworkId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attachments []models.HomeworkAttachment
+	for rows.Next() {
+		var a models.HomeworkAttachment
+		if err := rows.Scan(&a.ID, &a.Name, &a.URL); err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, a)
+	}
+
+	return attachments, rows.Err()
+}
+
+func saveHomeworkAttachments(tx *sql.Tx, homeworkId int, attachments []models.HomeworkAttachment) error {
+	for i, a := range attachments {
+		res, err := tx.Exec("INSERT INTO homework_attachments (homework_id, name, url) VALUES (?,?,?)", homeworkId, a.Name, a.URL)
+		if err != nil {
+			return err
+		}
+
+		id, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		attachments[i].ID = int(id)
+	}
+
+	return nil
+}
+
+func AddHomework(db *sql.DB, h *models.Homework) (sql.Result, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := tx.Exec(`
+		INSERT INTO homework (term_id, class_id, subject, title, instructions, due_date, max_score, created_by)
+		VALUES (?,?,?,?,?,?,?,?)
+	`, nullableInt(h.TermId), h.ClassId, h.Subject, h.Title, h.Instructions, h.DueDate, h.MaxScore, nullableInt(h.CreatedBy))
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := saveHomeworkAttachments(tx, int(id), h.Attachments); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return res, tx.Commit()
+}
+
+// UpdateHomework merges update into the stored homework. Attachments are
+// replaced only when given.
+func UpdateHomework(db *sql.DB, existing, update *models.Homework, id int) (sql.Result, error) {
+	err := scanHomework(db.QueryRow("SELECT "+homeworkColumns+" FROM homework h WHERE h.id = ?", id), existing)
+
+	if err != nil {
+		return nil, err
+	}
+
+	update.ID = existing.ID
+	update.ClassId = existing.ClassId
+	update.AssessmentId = existing.AssessmentId
+	update.CreatedBy = existing.CreatedBy
+	update.CreatedAt = existing.CreatedAt
+	// Simple conditional updates
+	if update.TermId == 0 {
+		update.TermId = existing.TermId
+	}
+	if update.Subject == "" {
+		update.Subject = existing.Subject
+	}
+	if update.Title == "" {
+		update.Title = existing.Title
+	}
+	if update.Instructions == "" {
+		update.Instructions = existing.Instructions
+	}
+	if update.DueDate == "" {
+		update.DueDate = existing.DueDate
+	}
+	if update.MaxScore == 0 {
+		update.MaxScore = existing.MaxScore
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := tx.Exec(`
+		UPDATE homework SET term_id=?, subject=?, title=?, instructions=?, due_date=?, max_score=? WHERE id=?
+	`, nullableInt(update.TermId), update.Subject, update.Title, update.Instructions, update.DueDate, update.MaxScore, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if update.Attachments != nil {
+		if _, err := tx.Exec("DELETE FROM homework_attachments WHERE homework_id = ?", id); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		if err := saveHomeworkAttachments(tx, id, update.Attachments); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	return res, tx.Commit()
+}
+
+func DeleteHomework(db *sql.DB, id int) error {
+	return deleteByID(db, "homework", id)
+}
+
+// SubmitHomework records or replaces a student's submission. Work handed in
+// after the due date is flagged late, and stays flagged once graded; graded
+// work cannot be resubmitted.
+func SubmitHomework(db *sql.DB, h *models.Homework, sub *models.Submission) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	// the student's class in the homework's term, falling back to their
+	// current class
+	var classId int
+	err = tx.QueryRow(`
+		SELECT COALESCE(
+			(SELECT e.class_id FROM enrollments e WHERE e.student_id = s.id AND e.term_id = ?),
+			s.class_id
+		)
+		FROM student s WHERE s.id = ?
+	`, h.TermId, sub.StudentId).Scan(&classId)
+
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return fmt.Errorf("student with id %d not found", sub.StudentId)
+	} else if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if classId != h.ClassId {
+		tx.Rollback()
+		return fmt.Errorf("student with id %d is not in class %d", sub.StudentId, h.ClassId)
+	}
+
+	var status string
+	err = tx.QueryRow("SELECT status FROM homework_submissions WHERE homework_id = ? AND student_id = ?", h.ID, sub.StudentId).Scan(&status)
+
+	if err != nil && err != sql.ErrNoRows {
+		tx.Rollback()
+		return err
+	}
+
+	if status == models.SubmissionGraded {
+		tx.Rollback()
+		return fmt.Errorf("submission has already been graded")
+	}
+
+	sub.HomeworkId = h.ID
+	sub.Status = models.SubmissionSubmitted
+	sub.Late = time.Now().Format(time.DateOnly) > h.DueDate
+
+	_, err = tx.Exec(`
+		INSERT INTO homework_submissions (homework_id, student_id, status, late, content, file_url)
+		VALUES (?,?,?,?,?,?)
+		ON DUPLICATE KEY UPDATE status = VALUES(status), late = VALUES(late), content = VALUES(content), file_url = VALUES(file_url), submitted_at = CURRENT_TIMESTAMP
+	`, h.ID, sub.StudentId, sub.Status, sub.Late, sub.Content, sub.FileURL)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GradeSubmission scores a submission. It returns sql.ErrNoRows when the
+// student has not submitted.
+func GradeSubmission(db *sql.DB, h *models.Homework, studentId int, req *models.GradeSubmissionRequest, gradedBy int) error {
+	if req.Score < 0 || req.Score > h.MaxScore {
+		return fmt.Errorf("score %.2f must be between 0 and %.2f", req.Score, h.MaxScore)
+	}
+
+	result, err := db.Exec(`
+		UPDATE homework_submissions
+		SET status = ?, score = ?, feedback = ?, graded_by = ?, graded_at = CURRENT_TIMESTAMP
+		WHERE homework_id = ? AND student_id = ?
+	`, models.SubmissionGraded, req.Score, req.Feedback, nullableInt(gradedBy), h.ID, studentId)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// FindSubmissions lists one entry per student in the homework's class,
+// including those who have not submitted, filtered by status when given.
+func FindSubmissions(db *sql.DB, h *models.Homework, status string) ([]models.Submission, error) {
+	rows, err := db.Query(`
+		SELECT `+submissionColumns+`
+		FROM homework_submissions hs
+		JOIN student s ON hs.student_id = s.id
+		WHERE hs.homework_id = ?
+	`, h.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	submitted := map[int]models.Submission{}
+	for rows.Next() {
+		var sub models.Submission
+		if err := scanSubmission(rows, &sub); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		submitted[sub.StudentId] = sub
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	students, err := FindStudentsByClass(db, h.ClassId, h.TermId)
+	if err != nil {
+		return nil, err
+	}
+
+	var submissions []models.Submission
+	seen := map[int]bool{}
+
+	add := func(sub models.Submission) {
+		if status == "" || sub.Status == status || status == models.SubmissionLate && sub.Late {
+			submissions = append(submissions, sub)
+		}
+	}
+
+	for _, s := range students {
+		seen[s.ID] = true
+
+		sub, ok := submitted[s.ID]
+		if !ok {
+			sub = models.Submission{
+				HomeworkId:  h.ID,
+				StudentId:   s.ID,
+				StudentName: s.FirstName + " " + s.LastName,
+				Status:      models.SubmissionNotSubmitted,
+			}
+		}
+		add(sub)
+	}
+
+	// students who submitted but have since left the class
+	for id, sub := range submitted {
+		if !seen[id] {
+			add(sub)
+		}
+	}
+
+	return submissions, nil
+}
+
+// PublishHomeworkGrades copies graded submissions into the gradebook as a
+// homework assessment, creating the assessment on first publish. Work by
+// students who have since left the class is skipped and reported. It all
+// happens in one transaction, so a failed publish leaves nothing behind.
+func PublishHomeworkGrades(db *sql.DB, h *models.Homework, publishedBy int) (*models.HomeworkPublishResult, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	// the lock keeps concurrent publishes from creating two assessments
+	var assessmentId sql.NullInt64
+	if err := tx.QueryRow("SELECT assessment_id FROM homework WHERE id = ? FOR UPDATE", h.ID).Scan(&assessmentId); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var assessment models.Assessment
+
+	err = sql.ErrNoRows
+	if assessmentId.Valid {
+		err = scanAssessment(tx.QueryRow("SELECT "+assessmentColumns+" FROM assessments a WHERE a.id = ?", assessmentId.Int64), &assessment)
+	}
+
+	if err == sql.ErrNoRows {
+		assessment = models.Assessment{
+			ClassId:  h.ClassId,
+			Subject:  h.Subject,
+			Title:    h.Title,
+			Type:     "homework",
+			MaxScore: h.MaxScore,
+			Weight:   1,
+			DueDate:  h.DueDate,
+			TermId:   h.TermId,
+		}
+
+		res, err := AddAssessment(tx, &assessment)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		id, err := res.LastInsertId()
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		assessment.ID = int(id)
+
+		if _, err := tx.Exec("UPDATE homework SET assessment_id = ? WHERE id = ?", assessment.ID, h.ID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	} else if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// graded work with the student's class in the homework's term, falling
+	// back to their current class
+	rows, err := tx.Query(`
+		SELECT hs.student_id, hs.score, COALESCE(hs.feedback, ''),
+			COALESCE(
+				(SELECT e.class_id FROM enrollments e WHERE e.student_id = s.id AND e.term_id = ?),
+				s.class_id
+			)
+		FROM homework_submissions hs
+		JOIN student s ON hs.student_id = s.id
+		WHERE hs.homework_id = ? AND hs.status = ?
+	`, h.TermId, h.ID, models.SubmissionGraded)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	result := &models.HomeworkPublishResult{Skipped: []int{}}
+	var scores []models.Score
+
+	for rows.Next() {
+		var sc models.Score
+		var classId int
+		if err := rows.Scan(&sc.StudentId, &sc.Score, &sc.Comment, &classId); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return nil, err
+		}
+
+		if classId != h.ClassId {
+			result.Skipped = append(result.Skipped, sc.StudentId)
+			continue
+		}
+		scores = append(scores, sc)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := saveScores(tx, &assessment, scores, publishedBy); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	h.AssessmentId = assessment.ID
+	result.Assessment = &assessment
+	result.Published = len(scores)
+	return result, nil
+}
@@ -0,0 +1,106 @@
+package repo
+
+import (
+	"database/sql"
+	"school-api/pkg/mailer"
+	"strings"
+	"time"
+)
+
+// QueueMail renders a template and adds it to the outbox. Pass the
+// transaction that makes the change the mail is about, so the mail is
+// queued if and only if the change commits.
+func QueueMail(db execer, to, template string, data any) error {
+	msg, err := mailer.Render(to, template, data)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec("INSERT INTO mail_outbox (template, recipient, subject, text_body, html_body) VALUES (?,?,?,?,?)",
+		template, msg.To, msg.Subject, msg.Text, msg.HTML)
+	return err
+}
+
+// ClaimMail takes up to limit due messages and hides them from other
+// workers for lease. A message whose worker dies before reporting back
+// becomes due again when the lease runs out.
+func ClaimMail(db *sql.DB, limit int, lease time.Duration) ([]mailer.Queued, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(`
+		SELECT id, template, attempts, recipient, subject, text_body, html_body
+		FROM mail_outbox
+		WHERE status = 'pending' AND next_attempt_at <= NOW()
+		ORDER BY next_attempt_at, id
+		LIMIT ?
+		FOR UPDATE SKIP LOCKED
+	`, limit)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var claimed []mailer.Queued
+	var ids []any
+	for rows.Next() {
+		var m mailer.Queued
+		if err := rows.Scan(&m.ID, &m.Template, &m.Attempts, &m.Message.To, &m.Message.Subject, &m.Message.Text, &m.Message.HTML); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return nil, err
+		}
+		claimed = append(claimed, m)
+		ids = append(ids, m.ID)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if len(claimed) == 0 {
+		return nil, tx.Commit()
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	_, err = tx.Exec("UPDATE mail_outbox SET next_attempt_at = NOW() + INTERVAL ? SECOND WHERE id IN ("+placeholders+")",
+		append([]any{int(lease.Seconds())}, ids...)...)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return claimed, tx.Commit()
+}
+
+// MarkMailSent records a delivery. The bodies are dropped since they can
+// hold links that act as credentials.
+func MarkMailSent(db *sql.DB, id int) error {
+	_, err := db.Exec(`
+		UPDATE mail_outbox
+		SET status = 'sent', sent_at = NOW(), attempts = attempts + 1, last_error = NULL, text_body = '', html_body = ''
+		WHERE id = ?
+	`, id)
+	return err
+}
+
+// MarkMailFailed records a failed delivery. The message is tried again
+// after retryIn, or given up on when giveUp is set, which drops the bodies
+// as MarkMailSent does.
+func MarkMailFailed(db *sql.DB, id int, sendErr error, retryIn time.Duration, giveUp bool) error {
+	status, bodies := "pending", ""
+	if giveUp {
+		status, bodies = "failed", ", text_body = '', html_body = ''"
+	}
+
+	_, err := db.Exec(`
+		UPDATE mail_outbox
+		SET status = ?, attempts = attempts + 1, last_error = ?, next_attempt_at = NOW() + INTERVAL ? SECOND`+bodies+`
+		WHERE id = ?
+	`, status, sendErr.Error(), int(retryIn.Seconds()), id)
+	return err
+}
@@ -0,0 +1,244 @@
+package repo
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"school-api/internal/models"
+	"slices"
+	"strings"
+	"time"
+)
+
+var ErrInvalidGrant = errors.New("authorization code is invalid, expired or already used")
+
+const oauthClientColumns = `
+	c.id, c.client_id, c.name, c.redirect_uris, c.scopes, c.client_secret_hash IS NOT NULL, c.created_at
+`
+
+func scanOAuthClient(scanner interface{ Scan(...any) error }, c *models.OAuthClient) error {
+	var redirectURIs, scopes string
+
+	err := scanner.Scan(&c.ID, &c.ClientId, &c.Name, &redirectURIs, &scopes, &c.Confidential, &c.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	c.RedirectURIs = strings.Fields(redirectURIs)
+	c.Scopes = strings.Fields(scopes)
+	return nil
+}
+
+// CreateOAuthClient registers a client. Confidential clients get a secret,
+// returned in ClientSecret this once.
+func CreateOAuthClient(db *sql.DB, req models.OAuthClientRequest) (*models.OAuthClient, error) {
+	clientId, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	var secret string
+	var secretHash any
+	if req.Confidential {
+		if secret, err = randomToken(32); err != nil {
+			return nil, err
+		}
+		secretHash = hashToken(secret)
+	}
+
+	result, err := db.Exec("INSERT INTO oauth_clients (client_id, client_secret_hash, name, redirect_uris, scopes) VALUES (?,?,?,?,?)",
+		clientId, secretHash, req.Name, strings.Join(req.RedirectURIs, " "), strings.Join(req.Scopes, " "))
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	var c models.OAuthClient
+	if err := scanOAuthClient(db.QueryRow("SELECT "+oauthClientColumns+" FROM oauth_clients c WHERE c.id = ?", id), &c); err != nil {
+		return nil, err
+	}
+
+	c.ClientSecret = secret
+	return &c, nil
+}
+
+func FindOAuthClients(db *sql.DB) ([]models.OAuthClient, error) {
+	rows, err := db.Query("SELECT " + oauthClientColumns + " FROM oauth_clients c WHERE c.revoked_at IS NULL ORDER BY c.name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var clients []models.OAuthClient
+	for rows.Next() {
+		var c models.OAuthClient
+		if err := scanOAuthClient(rows, &c); err != nil {
+			return nil, err
+		}
+		clients = append(clients, c)
+	}
+
+	return clients, rows.Err()
+}
+
+// FindOAuthClientByClientID returns nil when there is no such client or it
+// has been revoked.
+func FindOAuthClientByClientID(db *sql.DB, clientId string) (*models.OAuthClient, error) {
+	var c models.OAuthClient
+
+	err := scanOAuthClient(db.QueryRow("SELECT "+oauthClientColumns+" FROM oauth_clients c WHERE c.client_id = ? AND c.revoked_at IS NULL", clientId), &c)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// VerifyOAuthClientSecret checks a confidential client's secret.
+func VerifyOAuthClientSecret(db *sql.DB, clientId, secret string) (bool, error) {
+	var hash sql.NullString
+
+	err := db.QueryRow("SELECT client_secret_hash FROM oauth_clients WHERE client_id = ? AND revoked_at IS NULL", clientId).Scan(&hash)
+	if err == sql.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return hash.Valid && subtle.ConstantTimeCompare([]byte(hash.String), []byte(hashToken(secret))) == 1, nil
+}
+
+// RevokeOAuthClient stops a client from signing anyone in. It returns
+// sql.ErrNoRows when there is no such active client.
+func RevokeOAuthClient(db *sql.DB, id int) error {
+	result, err := db.Exec("UPDATE oauth_clients SET revoked_at = NOW() WHERE id = ? AND revoked_at IS NULL", id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// HasConsent reports whether the exec has already agreed to share scopes
+// with the client.
+func HasConsent(db *sql.DB, execId int, clientId string, scopes []string) (bool, error) {
+	var granted string
+
+	err := db.QueryRow("SELECT scopes FROM oauth_consents WHERE exec_id = ? AND client_id = ?", execId, clientId).Scan(&granted)
+	if err == sql.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	have := strings.Fields(granted)
+	for _, s := range scopes {
+		if !slices.Contains(have, s) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// SaveConsent adds scopes to what the exec shares with the client.
+func SaveConsent(db *sql.DB, execId int, clientId string, scopes []string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	var granted string
+	err = tx.QueryRow("SELECT scopes FROM oauth_consents WHERE exec_id = ? AND client_id = ? FOR UPDATE", execId, clientId).Scan(&granted)
+	if err != nil && err != sql.ErrNoRows {
+		tx.Rollback()
+		return err
+	}
+
+	all := strings.Fields(granted)
+	for _, s := range scopes {
+		if !slices.Contains(all, s) {
+			all = append(all, s)
+		}
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO oauth_consents (exec_id, client_id, scopes) VALUES (?,?,?)
+		ON DUPLICATE KEY UPDATE scopes = VALUES(scopes), granted_at = NOW()
+	`, execId, clientId, strings.Join(all, " "))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// CreateAuthorizationCode stores a code for the grant and returns it. The
+// code is good for ttl and can be redeemed once.
+func CreateAuthorizationCode(db *sql.DB, grant models.AuthorizationCode, ttl time.Duration) (string, error) {
+	code, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO oauth_codes (code_hash, client_id, exec_id, redirect_uri, scopes, nonce, code_challenge, auth_time, expires_at)
+		VALUES (?,?,?,?,?,?,?,?, NOW() + INTERVAL ? SECOND)
+	`, hashToken(code), grant.ClientId, grant.ExecId, grant.RedirectURI, strings.Join(grant.Scopes, " "), grant.Nonce,
+		grant.CodeChallenge, grant.AuthTime, int(ttl.Seconds()))
+	if err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+// RedeemAuthorizationCode spends a code and returns its grant, or
+// ErrInvalidGrant.
+func RedeemAuthorizationCode(db *sql.DB, code string) (*models.AuthorizationCode, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	var g models.AuthorizationCode
+	var scopes string
+	var usable bool
+
+	err = tx.QueryRow(`
+		SELECT client_id, exec_id, redirect_uri, scopes, nonce, code_challenge, auth_time,
+			used_at IS NULL AND expires_at > NOW()
+		FROM oauth_codes WHERE code_hash = ? FOR UPDATE
+	`, hashToken(code)).Scan(&g.ClientId, &g.ExecId, &g.RedirectURI, &scopes, &g.Nonce, &g.CodeChallenge, &g.AuthTime, &usable)
+
+	if err == sql.ErrNoRows || err == nil && !usable {
+		tx.Rollback()
+		return nil, ErrInvalidGrant
+	} else if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if _, err := tx.Exec("UPDATE oauth_codes SET used_at = NOW() WHERE code_hash = ?", hashToken(code)); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	g.Scopes = strings.Fields(scopes)
+	return &g, tx.Commit()
+}
@@ -0,0 +1,160 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"school-api/internal/models"
+	"time"
+)
+
+func AddReportComment(db *sql.DB, c *models.ReportComment) (sql.Result, error) {
+
+	stmt, err := db.Prepare("INSERT INTO report_comments (student_id,term_id,subject,comment,author_id) VALUES (?,?,?,?,?)")
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	return stmt.Exec(c.StudentId, c.TermId, c.Subject, c.Comment, nullableInt(c.AuthorId))
+}
+
+func FindReportComments(db *sql.DB, studentId int, termId int) ([]models.ReportComment, error) {
+	rows, err := db.Query(`
+		SELECT rc.id, rc.student_id, rc.term_id, rc.subject, rc.comment, rc.author_id,
+			COALESCE(CONCAT(e.first_name, ' ', e.last_name), ''), rc.created_at
+		FROM report_comments rc
+		LEFT JOIN execs e ON rc.author_id = e.id
+		WHERE rc.student_id = ? AND rc.term_id = ?
+		ORDER BY rc.subject, rc.created_at
+	`, studentId, termId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []models.ReportComment
+	for rows.Next() {
+		var c models.ReportComment
+		var authorId sql.NullInt64
+
+		err := rows.Scan(&c.ID, &c.StudentId, &c.TermId, &c.Subject, &c.Comment, &authorId, &c.AuthorName, &c.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		c.AuthorId = int(authorId.Int64)
+		comments = append(comments, c)
+	}
+
+	return comments, rows.Err()
+}
+
+// BuildReportCard gathers a student's subject averages, attendance and
+// teacher comments for one term. Attendance covers the term's dates unless
+// from/to are given.
+func BuildReportCard(ctx context.Context, db *sql.DB, studentId int, term *models.Term, from, to string) (*models.ReportCard, error) {
+	student, err := FindStudentByID(ctx, studentId, db)
+	if err != nil || student == nil {
+		return nil, err
+	}
+
+	if from == "" {
+		from = term.StartDate
+	}
+	if to == "" {
+		to = term.EndDate
+	}
+
+	subjects, err := FindSubjectAverages(db, studentId, term.ID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	attendance, err := SummarizeAttendance(db, studentId, 0, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := FindReportComments(db, studentId, term.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if subjects == nil {
+		subjects = []models.SubjectAverage{}
+	}
+	if comments == nil {
+		comments = []models.ReportComment{}
+	}
+
+	total := TotalAttendance(attendance)
+	total.StudentId = studentId
+
+	return &models.ReportCard{
+		Student:        student,
+		Term:           term,
+		Subjects:       subjects,
+		OverallAverage: meanAverage(subjects),
+		Attendance:     total,
+		Comments:       comments,
+		GeneratedAt:    time.Now().Format(time.RFC3339),
+	}, nil
+}
+
+// BuildTranscript gathers every term a student has grades for. It returns
+// nil when the student does not exist.
+func BuildTranscript(ctx context.Context, db *sql.DB, studentId int) (*models.Transcript, error) {
+	student, err := FindStudentByID(ctx, studentId, db)
+	if err != nil || student == nil {
+		return nil, err
+	}
+
+	averages, err := FindSubjectAverages(db, studentId, 0, "")
+	if err != nil {
+		return nil, err
+	}
+
+	attendance, err := SummarizeAttendance(db, studentId, 0, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	// averages are ordered by term, so consecutive rows share a term
+	terms := []models.TranscriptTerm{}
+	for _, avg := range averages {
+		if len(terms) == 0 || terms[len(terms)-1].TermId != avg.TermId {
+			terms = append(terms, models.TranscriptTerm{TermId: avg.TermId, Term: avg.Term})
+		}
+		last := &terms[len(terms)-1]
+		last.Subjects = append(last.Subjects, avg)
+	}
+
+	for i := range terms {
+		terms[i].Average = meanAverage(terms[i].Subjects)
+	}
+
+	total := TotalAttendance(attendance)
+	total.StudentId = studentId
+
+	return &models.Transcript{
+		Student:           student,
+		Terms:             terms,
+		CumulativeAverage: meanAverage(averages),
+		Attendance:        total,
+		GeneratedAt:       time.Now().Format(time.RFC3339),
+	}, nil
+}
+
+func meanAverage(subjects []models.SubjectAverage) float64 {
+	if len(subjects) == 0 {
+		return 0
+	}
+
+	var sum float64
+	for _, s := range subjects {
+		sum += s.Average
+	}
+
+	return math.Round(sum/float64(len(subjects))*100) / 100
+}
@@ -0,0 +1,214 @@
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+	"school-api/internal/models"
+)
+
+var rolloverActions = map[string]bool{
+	models.RolloverPromote:     true,
+	models.RolloverRetain:      true,
+	models.RolloverGraduate:    true,
+	models.RolloverTransferOut: true,
+}
+
+// status recorded on the source term's enrollment for each action
+var rolloverHistoryStatus = map[string]string{
+	models.RolloverPromote:     "promoted",
+	models.RolloverRetain:      "retained",
+	models.RolloverGraduate:    "graduated",
+	models.RolloverTransferOut: "transferred_out",
+}
+
+func IsValidRolloverAction(action string) bool {
+	return rolloverActions[action]
+}
+
+// RunRollover moves every active student into toTermId following the class
+// mapping and per-student overrides. All changes happen in one transaction;
+// a dry run builds the same report and rolls it back.
+func RunRollover(db *sql.DB, toTermId int, req *models.RolloverRequest) (*models.RolloverReport, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := rollover(tx, toTermId, req)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if req.DryRun {
+		tx.Rollback()
+		return report, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func rollover(tx *sql.Tx, toTermId int, req *models.RolloverRequest) (*models.RolloverReport, error) {
+	report := &models.RolloverReport{
+		DryRun:     req.DryRun,
+		FromTermId: req.FromTermId,
+		ToTermId:   toTermId,
+		Moves:      []models.RolloverMove{},
+	}
+
+	capacities := map[int]int{}
+	rows, err := tx.Query("SELECT id, capacity FROM classes")
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var id, capacity int
+		if err := rows.Scan(&id, &capacity); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		capacities[id] = capacity
+	}
+	rows.Close()
+
+	for from, to := range req.ClassMapping {
+		if _, ok := capacities[from]; !ok {
+			return nil, fmt.Errorf("class %d in class_mapping not found", from)
+		}
+		if _, ok := capacities[to]; to != 0 && !ok {
+			return nil, fmt.Errorf("class %d in class_mapping not found", to)
+		}
+	}
+
+	overrides := map[int]models.RolloverOverride{}
+	for _, o := range req.Overrides {
+		if !IsValidRolloverAction(o.Action) {
+			return nil, fmt.Errorf("invalid action %q for student %d", o.Action, o.StudentId)
+		}
+		if _, ok := capacities[o.ClassId]; o.ClassId != 0 && !ok {
+			return nil, fmt.Errorf("class %d for student %d not found", o.ClassId, o.StudentId)
+		}
+		overrides[o.StudentId] = o
+	}
+
+	rows, err = tx.Query(`
+		SELECT id, CONCAT(first_name, ' ', last_name), class_id
+		FROM student WHERE status = ?
+		ORDER BY class_id, last_name, first_name
+	`, models.StudentActive)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var m models.RolloverMove
+		if err := rows.Scan(&m.StudentId, &m.StudentName, &m.FromClassId); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		report.Moves = append(report.Moves, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	seen := map[int]bool{}
+	load := map[int]int{}
+
+	for i := range report.Moves {
+		m := &report.Moves[i]
+		seen[m.StudentId] = true
+
+		mapped, isMapped := req.ClassMapping[m.FromClassId]
+
+		switch {
+		case overrides[m.StudentId].Action != "":
+			o := overrides[m.StudentId]
+			m.Action = o.Action
+			if o.Action == models.RolloverPromote {
+				m.ToClassId = o.ClassId
+				if m.ToClassId == 0 {
+					m.ToClassId = mapped
+				}
+				if m.ToClassId == 0 {
+					return nil, fmt.Errorf("no target class to promote student %d into", m.StudentId)
+				}
+			}
+		case isMapped && mapped == 0:
+			m.Action = models.RolloverGraduate
+		case isMapped:
+			m.Action = models.RolloverPromote
+			m.ToClassId = mapped
+		default:
+			m.Action = models.RolloverRetain
+		}
+
+		if m.Action == models.RolloverRetain {
+			m.ToClassId = m.FromClassId
+		}
+
+		switch m.Action {
+		case models.RolloverPromote:
+			report.Promoted++
+		case models.RolloverRetain:
+			report.Retained++
+		case models.RolloverGraduate:
+			report.Graduated++
+		case models.RolloverTransferOut:
+			report.TransferredOut++
+		}
+
+		if m.ToClassId != 0 {
+			load[m.ToClassId]++
+		}
+	}
+
+	for id := range overrides {
+		if !seen[id] {
+			return nil, fmt.Errorf("student %d in overrides is not an active student", id)
+		}
+	}
+
+	for classId, count := range load {
+		if capacity := capacities[classId]; capacity > 0 && count > capacity {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("class %d would have %d students but its capacity is %d", classId, count, capacity))
+		}
+	}
+
+	for _, m := range report.Moves {
+		if req.FromTermId != 0 {
+			err := UpsertEnrollment(tx, m.StudentId, m.FromClassId, req.FromTermId, rolloverHistoryStatus[m.Action])
+			if err != nil {
+				return nil, fmt.Errorf("failed to record history for student %d: %w", m.StudentId, err)
+			}
+		}
+
+		switch m.Action {
+		case models.RolloverPromote, models.RolloverRetain:
+			err = UpsertEnrollment(tx, m.StudentId, m.ToClassId, toTermId, "enrolled")
+			if err == nil {
+				_, err = tx.Exec("UPDATE student SET class_id = ? WHERE id = ?", m.ToClassId, m.StudentId)
+			}
+		case models.RolloverGraduate:
+			_, err = tx.Exec("UPDATE student SET status = ? WHERE id = ?", models.StudentGraduated, m.StudentId)
+		case models.RolloverTransferOut:
+			_, err = tx.Exec("UPDATE student SET status = ? WHERE id = ?", models.StudentTransferredOut, m.StudentId)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to move student %d: %w", m.StudentId, err)
+		}
+	}
+
+	if req.SetCurrent {
+		if _, err := tx.Exec("UPDATE terms SET is_current = (id = ?)", toTermId); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
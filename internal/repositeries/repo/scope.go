@@ -0,0 +1,74 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"school-api/pkg/rbac"
+	"school-api/pkg/utils"
+)
+
+// scope limits the rows a request may see, based on the user JWTMiddleware
+// put on the context. Teachers only see students in the classes they are
+// assigned to this term and their own teacher record; everyone else, and a
+// context without a user, is not limited.
+type scope struct {
+	restricted bool
+	teacherId  int
+	termId     int
+}
+
+func scopeFor(ctx context.Context, db *sql.DB) (scope, error) {
+	execId, role := utils.UserFromContext(ctx)
+	if role != rbac.RoleTeacher {
+		return scope{}, nil
+	}
+
+	teacherId, err := FindTeacherIDByExec(db, execId)
+	if err != nil {
+		return scope{}, err
+	}
+
+	termId, err := CurrentTermID(db)
+	if err != nil {
+		return scope{}, err
+	}
+
+	// a teacher login without a teacher record matches nothing
+	return scope{restricted: true, teacherId: teacherId, termId: termId}, nil
+}
+
+// classes filters on a class id column, e.g. "c.id".
+func (sc scope) classes(column string) (string, []any) {
+	if !sc.restricted {
+		return "", nil
+	}
+
+	termFilter, termArgs := assignmentTermFilter(sc.termId)
+	return " AND " + column + " IN (SELECT ta.class_id FROM teacher_assignments ta WHERE ta.teacher_id = ?" + termFilter + ")",
+		append([]any{sc.teacherId}, termArgs...)
+}
+
+// teachers filters on a teacher id column, e.g. "t.id".
+func (sc scope) teachers(column string) (string, []any) {
+	if !sc.restricted {
+		return "", nil
+	}
+	return " AND " + column + " = ?", []any{sc.teacherId}
+}
+
+// ClassInScope reports whether the class exists and the request may see
+// it.
+func ClassInScope(ctx context.Context, db *sql.DB, classId int) (bool, error) {
+	sc, err := scopeFor(ctx, db)
+	if err != nil {
+		return false, err
+	}
+	scopeFilter, scopeArgs := sc.classes("c.id")
+
+	var tmp int
+	err = db.QueryRow("SELECT 1 FROM classes c WHERE c.id = ?"+scopeFilter, append([]any{classId}, scopeArgs...)...).Scan(&tmp)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
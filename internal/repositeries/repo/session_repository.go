@@ -0,0 +1,265 @@
+package repo
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"school-api/internal/models"
+	"time"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("refresh token is invalid or expired")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used, the session has been revoked")
+)
+
+const sessionColumns = `
+	s.id, s.exec_id, s.user_agent, s.ip, s.created_at, s.last_used_at, s.expires_at, s.two_factor
+`
+
+// randomToken returns n random bytes, hex encoded.
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// only the hash of a refresh token is stored
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateSession starts a session for a login and returns its id and first
+// refresh token. The session expires after ttl unless refreshed.
+// twoFactor records whether the login passed a second factor.
+func CreateSession(db *sql.DB, execId int, userAgent, ip string, twoFactor bool, ttl time.Duration) (string, string, error) {
+	sid, err := randomToken(16)
+	if err != nil {
+		return "", "", err
+	}
+
+	refresh, err := randomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return "", "", err
+	}
+
+	_, err = tx.Exec("INSERT INTO sessions (id, exec_id, user_agent, ip, two_factor, expires_at) VALUES (?,?,?,?,?, NOW() + INTERVAL ? SECOND)",
+		sid, execId, userAgent, ip, twoFactor, int(ttl.Seconds()))
+	if err != nil {
+		tx.Rollback()
+		return "", "", err
+	}
+
+	_, err = tx.Exec("INSERT INTO refresh_tokens (session_id, token_hash) VALUES (?,?)", sid, hashToken(refresh))
+	if err != nil {
+		tx.Rollback()
+		return "", "", err
+	}
+
+	return sid, refresh, tx.Commit()
+}
+
+// RotateRefreshToken spends a refresh token and returns its session and
+// the token that replaces it. Presenting a token that was already spent
+// means it leaked, so the whole session is revoked and
+// ErrRefreshTokenReused returned.
+func RotateRefreshToken(db *sql.DB, token string, ttl time.Duration) (*models.Session, string, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, "", err
+	}
+
+	var tokenId int
+	var used, live bool
+	var s models.Session
+
+	err = tx.QueryRow(`
+		SELECT rt.id, rt.used_at IS NOT NULL, s.revoked_at IS NULL AND s.expires_at > NOW(), `+sessionColumns+`
+		FROM refresh_tokens rt JOIN sessions s ON rt.session_id = s.id
+		WHERE rt.token_hash = ? FOR UPDATE
+	`, hashToken(token)).Scan(&tokenId, &used, &live, &s.ID, &s.ExecId, &s.UserAgent, &s.IP, &s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt, &s.TwoFactor)
+
+	if err == sql.ErrNoRows || err == nil && !live {
+		tx.Rollback()
+		return nil, "", ErrInvalidRefreshToken
+	} else if err != nil {
+		tx.Rollback()
+		return nil, "", err
+	}
+
+	if used {
+		if err := revokeSessions(tx, "id = ?", s.ID, "refresh_reuse"); err != nil {
+			tx.Rollback()
+			return nil, "", err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, "", err
+		}
+		return nil, "", ErrRefreshTokenReused
+	}
+
+	next, err := randomToken(32)
+	if err != nil {
+		tx.Rollback()
+		return nil, "", err
+	}
+
+	if _, err := tx.Exec("UPDATE refresh_tokens SET used_at = NOW() WHERE id = ?", tokenId); err != nil {
+		tx.Rollback()
+		return nil, "", err
+	}
+
+	_, err = tx.Exec("INSERT INTO refresh_tokens (session_id, token_hash, parent_id) VALUES (?,?,?)", s.ID, hashToken(next), tokenId)
+	if err != nil {
+		tx.Rollback()
+		return nil, "", err
+	}
+
+	_, err = tx.Exec("UPDATE sessions SET last_used_at = NOW(), expires_at = NOW() + INTERVAL ? SECOND WHERE id = ?", int(ttl.Seconds()), s.ID)
+	if err != nil {
+		tx.Rollback()
+		return nil, "", err
+	}
+
+	err = tx.QueryRow("SELECT last_used_at, expires_at FROM sessions WHERE id = ?", s.ID).Scan(&s.LastUsedAt, &s.ExpiresAt)
+	if err != nil {
+		tx.Rollback()
+		return nil, "", err
+	}
+
+	return &s, next, tx.Commit()
+}
+
+// IsTokenValid reports whether an access token issued at issuedAt for the
+// session is still good: the session is live and belongs to the exec, the
+// exec is active, and the password has not changed nor the account been
+// deactivated since the token was issued.
+func IsTokenValid(db *sql.DB, sid string, execId int, issuedAt time.Time) (bool, error) {
+	var inactive, changedSince, deactivatedSince bool
+
+	// iat has second precision, as do both columns
+	err := db.QueryRow(`
+		SELECT e.inactive,
+			COALESCE(e.password_changed_at > FROM_UNIXTIME(?), FALSE),
+			COALESCE(e.deactivated_at > FROM_UNIXTIME(?), FALSE)
+		FROM sessions s JOIN execs e ON s.exec_id = e.id
+		WHERE s.id = ? AND s.exec_id = ? AND s.revoked_at IS NULL AND s.expires_at > NOW()
+	`, issuedAt.Unix(), issuedAt.Unix(), sid, execId).Scan(&inactive, &changedSince, &deactivatedSince)
+
+	if err == sql.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return !inactive && !changedSince && !deactivatedSince, nil
+}
+
+// FindActiveSessions lists an exec's live sessions, most recently used
+// first.
+func FindActiveSessions(db *sql.DB, execId int) ([]models.Session, error) {
+	rows, err := db.Query(`
+		SELECT `+sessionColumns+` FROM sessions s
+		WHERE s.exec_id = ? AND s.revoked_at IS NULL AND s.expires_at > NOW()
+		ORDER BY s.last_used_at DESC
+	`, execId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []models.Session
+	for rows.Next() {
+		var s models.Session
+		if err := rows.Scan(&s.ID, &s.ExecId, &s.UserAgent, &s.IP, &s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt, &s.TwoFactor); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+
+	return sessions, rows.Err()
+}
+
+// MarkSessionTwoFactor records that the session has passed a second factor,
+// as happens when 2FA is enrolled from it.
+func MarkSessionTwoFactor(db *sql.DB, sid string) error {
+	_, err := db.Exec("UPDATE sessions SET two_factor = TRUE WHERE id = ?", sid)
+	return err
+}
+
+// RevokeSession ends one of the exec's sessions. It returns sql.ErrNoRows
+// when the exec has no such live session.
+func RevokeSession(db *sql.DB, execId int, sid, reason string) error {
+	result, err := db.Exec("UPDATE sessions SET revoked_at = NOW(), revoke_reason = ? WHERE id = ? AND exec_id = ? AND revoked_at IS NULL",
+		reason, sid, execId)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// RevokeSessionByRefreshToken ends the session a refresh token belongs to.
+// It returns sql.ErrNoRows when the token is unknown or its session has
+// already ended.
+func RevokeSessionByRefreshToken(db *sql.DB, token, reason string) error {
+	result, err := db.Exec(`
+		UPDATE sessions s JOIN refresh_tokens rt ON rt.session_id = s.id
+		SET s.revoked_at = NOW(), s.revoke_reason = ?
+		WHERE rt.token_hash = ? AND s.revoked_at IS NULL
+	`, reason, hashToken(token))
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// RevokeAllSessions ends every live session of the exec.
+func RevokeAllSessions(db *sql.DB, execId int, reason string) error {
+	return revokeSessions(db, "exec_id = ?", execId, reason)
+}
+
+// RevokeOtherSessions ends every live session of the exec except keep.
+func RevokeOtherSessions(db *sql.DB, execId int, keep, reason string) error {
+	_, err := db.Exec("UPDATE sessions SET revoked_at = NOW(), revoke_reason = ? WHERE revoked_at IS NULL AND exec_id = ? AND id <> ?",
+		reason, execId, keep)
+	return err
+}
+
+func revokeSessions(db execer, where string, arg any, reason string) error {
+	_, err := db.Exec("UPDATE sessions SET revoked_at = NOW(), revoke_reason = ? WHERE revoked_at IS NULL AND "+where, reason, arg)
+	return err
+}
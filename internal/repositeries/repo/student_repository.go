@@ -1,26 +1,36 @@
 package repo
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"math"
 	"school-api/internal/models"
 )
 
-func FindStudentByID(id int, db *sql.DB) (*models.Student, error) {
+// FindStudentByID returns nil for students outside the caller's scope, the
+// same as for a missing student.
+func FindStudentByID(ctx context.Context, id int, db *sql.DB) (*models.Student, error) {
 	var s models.Student
 	var className string
 
-	err := db.QueryRow(`
-        SELECT s.id, s.first_name, s.last_name, s.email, c.id AS class_id, c.name AS class_name
-        FROM student s JOIN classes c ON s.class_id=c.id WHERE s.id = ?
-    `, id).Scan(
+	sc, err := scopeFor(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	scopeFilter, scopeArgs := sc.classes("c.id")
+
+	err = db.QueryRow(`
+        SELECT s.id, s.first_name, s.last_name, s.email, c.id AS class_id, c.name AS class_name, s.status
+        FROM student s JOIN classes c ON s.class_id=c.id WHERE s.id = ?`+scopeFilter+`
+    `, append([]any{id}, scopeArgs...)...).Scan(
 		&s.ID,
 		&s.FirstName,
 		&s.LastName,
 		&s.Email,
 		&s.ClassId,
 		&className,
+		&s.Status,
 	)
 
 	s.Class = models.Class{
@@ -40,12 +50,14 @@ func FindStudentByID(id int, db *sql.DB) (*models.Student, error) {
 }
 
 func FindStudent(
+	ctx context.Context,
 	db *sql.DB,
 	search string,
 	filters map[string]string,
 	sort string,
 	limit int,
 	page int,
+	termId int,
 ) ([]models.Student, models.PaginationMeta, error) {
 
 	// sane defaults
@@ -65,6 +77,26 @@ func FindStudent(
 
 	var args []any
 
+	// scoped to a term, the class comes from that term's enrollment
+	if termId != 0 {
+		baseQuery = `
+			FROM student s
+			JOIN enrollments e ON e.student_id = s.id AND e.term_id = ?
+			JOIN classes c ON e.class_id = c.id
+			WHERE 1=1
+		`
+		args = append(args, termId)
+	}
+
+	// only the classes the caller may see
+	sc, err := scopeFor(ctx, db)
+	if err != nil {
+		return nil, models.PaginationMeta{}, err
+	}
+	scopeFilter, scopeArgs := sc.classes("c.id")
+	baseQuery += scopeFilter
+	args = append(args, scopeArgs...)
+
 	// filters
 	for key, val := range filters {
 		if val == "" {
@@ -98,7 +130,7 @@ func FindStudent(
 	countQuery := "SELECT COUNT(*) " + baseQuery
 
 	var totalRecords int
-	err := db.QueryRow(countQuery, args...).Scan(&totalRecords)
+	err = db.QueryRow(countQuery, args...).Scan(&totalRecords)
 	if err != nil {
 		return nil, models.PaginationMeta{}, err
 	}
@@ -174,29 +206,75 @@ func FindStudent(
 
 func AddStudent(db *sql.DB, t *models.Student) (sql.Result, error) {
 
-	stmt, err := db.Prepare("INSERT INTO student (first_name,last_name,email,class_id) VALUES (?,?,?,?)")
+	termId, err := CurrentTermID(db)
 	if err != nil {
 		return nil, err
 	}
-	defer stmt.Close()
 
-	res, err := stmt.Exec(t.FirstName, t.LastName, t.Email, t.ClassId)
+	tx, err := db.Begin()
 	if err != nil {
 		return nil, err
 	}
 
-	return res, nil
+	if t.ClassId != 0 {
+		if err := checkClassRoom(tx, t.ClassId, 1); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	res, err := tx.Exec("INSERT INTO student (first_name,last_name,email,class_id) VALUES (?,?,?,?)", t.FirstName, t.LastName, t.Email, t.ClassId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if termId != 0 {
+		studentId, err := res.LastInsertId()
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		err = UpsertEnrollment(tx, int(studentId), t.ClassId, termId, "enrolled")
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	return res, tx.Commit()
 
 }
 
-func UpdateStudent(db *sql.DB, existingStudent, updateStudent *models.Student, id int) (sql.Result, error) {
-	err := db.QueryRow("SELECT id,first_name,last_name,email,class_id FROM student WHERE id= ?", id).
+// UpdateStudent returns sql.ErrNoRows for students outside the caller's
+// scope. A scoped caller may only move a student into another class they
+// can see.
+func UpdateStudent(ctx context.Context, db *sql.DB, existingStudent, updateStudent *models.Student, id int) (sql.Result, error) {
+	sc, err := scopeFor(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	scopeFilter, scopeArgs := sc.classes("class_id")
+
+	err = db.QueryRow("SELECT id,first_name,last_name,email,class_id FROM student WHERE id= ?"+scopeFilter, append([]any{id}, scopeArgs...)...).
 		Scan(&existingStudent.ID, &existingStudent.FirstName, &existingStudent.LastName, &existingStudent.Email, &existingStudent.ClassId)
 
 	if err != nil {
 		return nil, err
 	}
 
+	if updateStudent.ClassId != 0 && updateStudent.ClassId != existingStudent.ClassId && sc.restricted {
+		var tmp int
+		scopeFilter, scopeArgs = sc.classes("id")
+		err = db.QueryRow("SELECT id FROM classes WHERE id = ?"+scopeFilter, append([]any{updateStudent.ClassId}, scopeArgs...)...).Scan(&tmp)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("class %d not found", updateStudent.ClassId)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
 	updateStudent.ID = existingStudent.ID
 	// Simple conditional updates
 	if updateStudent.FirstName == "" {
@@ -213,15 +291,43 @@ func UpdateStudent(db *sql.DB, existingStudent, updateStudent *models.Student, i
 		updateStudent.ClassId = existingStudent.ClassId
 	}
 
-	_, err = db.Exec("UPDATE student SET first_name=?, last_name=?, email=?, class_id=? WHERE id=?",
-		updateStudent.FirstName, updateStudent.LastName, updateStudent.Email, updateStudent.ClassId, id)
+	moved := updateStudent.ClassId != existingStudent.ClassId
 
+	termId, err := CurrentTermID(db)
 	if err != nil {
+		return nil, err
+	}
 
+	tx, err := db.Begin()
+	if err != nil {
 		return nil, err
 	}
 
-	return nil, nil
+	if moved {
+		if err := checkClassRoom(tx, updateStudent.ClassId, 1); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	_, err = tx.Exec("UPDATE student SET first_name=?, last_name=?, email=?, class_id=? WHERE id=?",
+		updateStudent.FirstName, updateStudent.LastName, updateStudent.Email, updateStudent.ClassId, id)
+
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// keep the current term's placement in step with the class change
+	if moved && termId != 0 {
+		err = UpsertEnrollment(tx, id, updateStudent.ClassId, termId, "enrolled")
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	return nil, tx.Commit()
 
 }
 
@@ -0,0 +1,141 @@
+package repo
+
+import (
+	"database/sql"
+	"school-api/internal/models"
+)
+
+// assignmentTermFilter limits teacher_assignments (aliased ta) to those that
+// apply in a term. Assignments without a term apply to every term, and a
+// termId of 0 applies no filter.
+func assignmentTermFilter(termId int) (string, []any) {
+	if termId == 0 {
+		return "", nil
+	}
+	return " AND (ta.term_id IS NULL OR ta.term_id = ?)", []any{termId}
+}
+
+func FindTeacherAssignments(db *sql.DB, teacherId int, termId int) ([]models.TeacherAssignment, error) {
+	termFilter, termArgs := assignmentTermFilter(termId)
+
+	rows, err := db.Query(`
+		SELECT ta.id, ta.teacher_id, ta.class_id, c.name, ta.subject, COALESCE(ta.term_id, 0), COALESCE(t.name, '')
+		FROM teacher_assignments ta
+		JOIN classes c ON ta.class_id = c.id
+		LEFT JOIN terms t ON ta.term_id = t.id
+		WHERE ta.teacher_id = ?`+termFilter+`
+		ORDER BY c.name, ta.subject
+	`, append([]any{teacherId}, termArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var assignments []models.TeacherAssignment
+	for rows.Next() {
+		var a models.TeacherAssignment
+		if err := rows.Scan(&a.ID, &a.TeacherId, &a.ClassId, &a.ClassName, &a.Subject, &a.TermId, &a.TermName); err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, a)
+	}
+
+	return assignments, rows.Err()
+}
+
+func AddTeacherAssignment(db *sql.DB, a *models.TeacherAssignment) (sql.Result, error) {
+
+	stmt, err := db.Prepare("INSERT INTO teacher_assignments (teacher_id,class_id,subject,term_id) VALUES (?,?,?,?)")
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	return stmt.Exec(a.TeacherId, a.ClassId, a.Subject, nullableInt(a.TermId))
+}
+
+func DeleteTeacherAssignment(db *sql.DB, teacherId, assignmentId int) error {
+	result, err := db.Exec("DELETE FROM teacher_assignments WHERE id = ? AND teacher_id = ?", assignmentId, teacherId)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func CheckTeacherAssignmentExists(db *sql.DB, teacherId, classId int, subject string, termId int) (bool, error) {
+	var tmp int
+	err := db.QueryRow("SELECT id FROM teacher_assignments WHERE teacher_id=? AND class_id=? AND subject=? AND term_id <=> ?",
+		teacherId, classId, subject, nullableInt(termId)).Scan(&tmp)
+
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// TeacherTeachesClass reports whether the teacher is assigned to the class in
+// the current term.
+func TeacherTeachesClass(db *sql.DB, teacherId, classId int) (bool, error) {
+	termId, err := CurrentTermID(db)
+	if err != nil {
+		return false, err
+	}
+	termFilter, termArgs := assignmentTermFilter(termId)
+
+	var tmp int
+	err = db.QueryRow("SELECT 1 FROM teacher_assignments ta WHERE ta.teacher_id=? AND ta.class_id=?"+termFilter+" LIMIT 1",
+		append([]any{teacherId, classId}, termArgs...)...).Scan(&tmp)
+
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// FindStudentsByTeacher returns every student in any class the teacher is
+// assigned to in the current term, without duplicates.
+func FindStudentsByTeacher(db *sql.DB, teacherId int) ([]models.Student, error) {
+	termId, err := CurrentTermID(db)
+	if err != nil {
+		return nil, err
+	}
+	termFilter, termArgs := assignmentTermFilter(termId)
+
+	rows, err := db.Query(`
+		SELECT s.id, s.class_id, s.first_name, s.last_name, s.email, c.name
+		FROM student s
+		JOIN classes c ON s.class_id = c.id
+		WHERE s.class_id IN (
+			SELECT ta.class_id FROM teacher_assignments ta WHERE ta.teacher_id = ?`+termFilter+`
+		)
+		ORDER BY c.name, s.last_name, s.first_name
+	`, append([]any{teacherId}, termArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var students []models.Student
+	for rows.Next() {
+		var s models.Student
+		if err := rows.Scan(&s.ID, &s.ClassId, &s.FirstName, &s.LastName, &s.Email, &s.Class.Name); err != nil {
+			return nil, err
+		}
+		s.Class.ID = s.ClassId
+		students = append(students, s)
+	}
+
+	return students, rows.Err()
+}
@@ -1,18 +1,27 @@
 package repo
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"school-api/internal/models"
 )
 
-func FindTeacherByID(id int, db *sql.DB) (*models.Teacher, error) {
+// FindTeacherByID returns nil for a teacher outside the caller's scope, the
+// same as for a missing teacher.
+func FindTeacherByID(ctx context.Context, id int, db *sql.DB) (*models.Teacher, error) {
 	var t models.Teacher
 
-	err := db.QueryRow(`
-        SELECT t.id, t.first_name, t.last_name, t.email, c.name AS class, t.subject 
-        FROM teachers t JOIN classes c ON t.class_id=c.id WHERE t.id = ?
-    `, id).Scan(
+	sc, err := scopeFor(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	scopeFilter, scopeArgs := sc.teachers("t.id")
+
+	err = db.QueryRow(`
+        SELECT t.id, t.first_name, t.last_name, t.email, COALESCE(c.name, '') AS class, t.subject 
+        FROM teachers t LEFT JOIN classes c ON t.class_id=c.id WHERE t.id = ?`+scopeFilter+`
+    `, append([]any{id}, scopeArgs...)...).Scan(
 		&t.ID,
 		&t.FirstName,
 		&t.LastName,
@@ -29,10 +38,20 @@ func FindTeacherByID(id int, db *sql.DB) (*models.Teacher, error) {
 		return nil, err
 	}
 
+	t.Assignments, err = FindTeacherAssignments(db, t.ID, 0)
+	if err != nil {
+		return nil, err
+	}
+
 	return &t, nil
 }
 
-func FindTeacher(db *sql.DB, search string, filters map[string]string, sort string) ([]models.Teacher, error) {
+func FindTeacher(ctx context.Context, db *sql.DB, search string, filters map[string]string, sort string) ([]models.Teacher, error) {
+	sc, err := scopeFor(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	scopeFilter, scopeArgs := sc.teachers("t.id")
 
 	query := `
 		SELECT 
@@ -40,14 +59,14 @@ func FindTeacher(db *sql.DB, search string, filters map[string]string, sort stri
 			t.first_name, 
 			t.last_name, 
 			t.email, 
-			c.name AS class,
+			COALESCE(c.name, '') AS class,
 			t.subject
 		FROM teachers t
-		JOIN classes c ON t.class_id = c.id
+		LEFT JOIN classes c ON t.class_id = c.id
 		WHERE 1=1
-	`
+	` + scopeFilter
 
-	var args []any
+	args := scopeArgs
 
 	for key, val := range filters {
 		if val == "" {
@@ -55,8 +74,18 @@ func FindTeacher(db *sql.DB, search string, filters map[string]string, sort stri
 		}
 
 		if key == "class" {
-			query += " AND c.name = ?"
-			args = append(args, val)
+			query += ` AND (c.name = ? OR EXISTS (
+				SELECT 1 FROM teacher_assignments ta
+				JOIN classes ac ON ta.class_id = ac.id
+				WHERE ta.teacher_id = t.id AND ac.name = ?
+			))`
+			args = append(args, val, val)
+		} else if key == "subject" {
+			query += ` AND (t.subject = ? OR EXISTS (
+				SELECT 1 FROM teacher_assignments ta
+				WHERE ta.teacher_id = t.id AND ta.subject = ?
+			))`
+			args = append(args, val, val)
 		} else {
 			query += " AND t." + key + " = ?"
 			args = append(args, val)
@@ -110,18 +139,25 @@ func FindTeacher(db *sql.DB, search string, filters map[string]string, sort stri
 		teachers = append(teachers, t)
 	}
 
+	for i := range teachers {
+		teachers[i].Assignments, err = FindTeacherAssignments(db, teachers[i].ID, 0)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return teachers, nil
 }
 
 func AddTeacher(db *sql.DB, t *models.Teacher) (sql.Result, error) {
 
-	stmt, err := db.Prepare("INSERT INTO teachers (first_name,last_name,email,class,subject) VALUES (?,?,?,?,?)")
+	stmt, err := db.Prepare("INSERT INTO teachers (first_name,last_name,email,class,subject,exec_id) VALUES (?,?,?,?,?,?)")
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
-	res, err := stmt.Exec(t.FirstName, t.LastName, t.Email, t.Class, t.Subject)
+	res, err := stmt.Exec(t.FirstName, t.LastName, t.Email, t.Class, t.Subject, nullableInt(t.ExecId))
 	if err != nil {
 		return nil, err
 	}
@@ -130,14 +166,24 @@ func AddTeacher(db *sql.DB, t *models.Teacher) (sql.Result, error) {
 
 }
 
-func UpdateTeacher(db *sql.DB, existingTeacher, updateTeacher *models.Teacher, id int) (sql.Result, error) {
-	err := db.QueryRow("SELECT id,first_name,last_name,email,subject,class FROM teachers WHERE id= ?", id).
-		Scan(&existingTeacher.ID, &existingTeacher.FirstName, &existingTeacher.LastName, &existingTeacher.Email, &existingTeacher.Subject, &existingTeacher.Class)
+// UpdateTeacher returns sql.ErrNoRows for a teacher outside the caller's
+// scope.
+func UpdateTeacher(ctx context.Context, db *sql.DB, existingTeacher, updateTeacher *models.Teacher, id int) (sql.Result, error) {
+	sc, err := scopeFor(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	scopeFilter, scopeArgs := sc.teachers("id")
+
+	var execId sql.NullInt64
+	err = db.QueryRow("SELECT id,first_name,last_name,email,subject,class,exec_id FROM teachers WHERE id= ?"+scopeFilter, append([]any{id}, scopeArgs...)...).
+		Scan(&existingTeacher.ID, &existingTeacher.FirstName, &existingTeacher.LastName, &existingTeacher.Email, &existingTeacher.Subject, &existingTeacher.Class, &execId)
 
 	if err != nil {
 
 		return nil, err
 	}
+	existingTeacher.ExecId = int(execId.Int64)
 
 	updateTeacher.ID = existingTeacher.ID
 	// Simple conditional updates
@@ -156,9 +202,12 @@ func UpdateTeacher(db *sql.DB, existingTeacher, updateTeacher *models.Teacher, i
 	if updateTeacher.Class == "" {
 		updateTeacher.Class = existingTeacher.Class
 	}
+	if updateTeacher.ExecId == 0 {
+		updateTeacher.ExecId = existingTeacher.ExecId
+	}
 
-	_, err = db.Exec("UPDATE teachers SET first_name=?, last_name=?, email=?, subject=?, class=? WHERE id=?",
-		updateTeacher.FirstName, updateTeacher.LastName, updateTeacher.Email, updateTeacher.Subject, updateTeacher.Class, id)
+	_, err = db.Exec("UPDATE teachers SET first_name=?, last_name=?, email=?, subject=?, class=?, exec_id=? WHERE id=?",
+		updateTeacher.FirstName, updateTeacher.LastName, updateTeacher.Email, updateTeacher.Subject, updateTeacher.Class, nullableInt(updateTeacher.ExecId), id)
 
 	if err != nil {
 
@@ -259,3 +308,16 @@ func PatchMultipleTeachers(db *sql.DB, ids []int, updates []map[string]any) erro
 
 	return tx.Commit()
 }
+
+// FindTeacherIDByExec returns the teacher record linked to an exec account,
+// or 0 when the account is not linked to a teacher.
+func FindTeacherIDByExec(db *sql.DB, execId int) (int, error) {
+	var teacherId int
+	err := db.QueryRow("SELECT id FROM teachers WHERE exec_id = ?", execId).Scan(&teacherId)
+
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+
+	return teacherId, err
+}
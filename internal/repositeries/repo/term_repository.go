@@ -0,0 +1,338 @@
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+	"school-api/internal/models"
+)
+
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+const termColumns = `
+	t.id,
+	t.academic_year_id,
+	t.name,
+	DATE_FORMAT(t.start_date, '%Y-%m-%d'),
+	DATE_FORMAT(t.end_date, '%Y-%m-%d'),
+	t.is_current
+`
+
+func scanTerm(scanner interface{ Scan(...any) error }, t *models.Term) error {
+	return scanner.Scan(&t.ID, &t.AcademicYearId, &t.Name, &t.StartDate, &t.EndDate, &t.IsCurrent)
+}
+
+func FindAcademicYears(db *sql.DB) ([]models.AcademicYear, error) {
+	rows, err := db.Query(`
+		SELECT id, name, DATE_FORMAT(start_date, '%Y-%m-%d'), DATE_FORMAT(end_date, '%Y-%m-%d')
+		FROM academic_years ORDER BY start_date DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var years []models.AcademicYear
+	for rows.Next() {
+		var y models.AcademicYear
+		if err := rows.Scan(&y.ID, &y.Name, &y.StartDate, &y.EndDate); err != nil {
+			return nil, err
+		}
+		years = append(years, y)
+	}
+
+	return years, rows.Err()
+}
+
+func FindAcademicYearByID(id int, db *sql.DB) (*models.AcademicYear, error) {
+	var y models.AcademicYear
+
+	err := db.QueryRow(`
+		SELECT id, name, DATE_FORMAT(start_date, '%Y-%m-%d'), DATE_FORMAT(end_date, '%Y-%m-%d')
+		FROM academic_years WHERE id = ?
+	`, id).Scan(&y.ID, &y.Name, &y.StartDate, &y.EndDate)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	y.Terms, err = FindTerms(db, y.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &y, nil
+}
+
+func AddAcademicYear(db *sql.DB, y *models.AcademicYear) (sql.Result, error) {
+	return db.Exec("INSERT INTO academic_years (name,start_date,end_date) VALUES (?,?,?)", y.Name, y.StartDate, y.EndDate)
+}
+
+func UpdateAcademicYear(db *sql.DB, existing, update *models.AcademicYear, id int) (sql.Result, error) {
+	err := db.QueryRow(`
+		SELECT id, name, DATE_FORMAT(start_date, '%Y-%m-%d'), DATE_FORMAT(end_date, '%Y-%m-%d')
+		FROM academic_years WHERE id = ?
+	`, id).Scan(&existing.ID, &existing.Name, &existing.StartDate, &existing.EndDate)
+
+	if err != nil {
+		return nil, err
+	}
+
+	update.ID = existing.ID
+	// Simple conditional updates
+	if update.Name == "" {
+		update.Name = existing.Name
+	}
+	if update.StartDate == "" {
+		update.StartDate = existing.StartDate
+	}
+	if update.EndDate == "" {
+		update.EndDate = existing.EndDate
+	}
+
+	if update.StartDate >= update.EndDate {
+		return nil, fmt.Errorf("start_date must be before end_date")
+	}
+
+	return db.Exec("UPDATE academic_years SET name=?, start_date=?, end_date=? WHERE id=?", update.Name, update.StartDate, update.EndDate, id)
+}
+
+func DeleteAcademicYear(db *sql.DB, id int) error {
+	return deleteByID(db, "academic_years", id)
+}
+
+func FindTerms(db *sql.DB, academicYearId int) ([]models.Term, error) {
+	query := "SELECT " + termColumns + " FROM terms t"
+	var args []any
+
+	if academicYearId != 0 {
+		query += " WHERE t.academic_year_id = ?"
+		args = append(args, academicYearId)
+	}
+	query += " ORDER BY t.start_date"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var terms []models.Term
+	for rows.Next() {
+		var t models.Term
+		if err := scanTerm(rows, &t); err != nil {
+			return nil, err
+		}
+		terms = append(terms, t)
+	}
+
+	return terms, rows.Err()
+}
+
+func FindTermByID(id int, db *sql.DB) (*models.Term, error) {
+	var t models.Term
+
+	err := scanTerm(db.QueryRow("SELECT "+termColumns+" FROM terms t WHERE t.id = ?", id), &t)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// CurrentTerm returns the term marked current, or nil when none is.
+func CurrentTerm(db *sql.DB) (*models.Term, error) {
+	var t models.Term
+
+	err := scanTerm(db.QueryRow("SELECT "+termColumns+" FROM terms t WHERE t.is_current LIMIT 1"), &t)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// CurrentTermID returns the id of the current term, or 0 when none is set.
+func CurrentTermID(db *sql.DB) (int, error) {
+	t, err := CurrentTerm(db)
+	if err != nil || t == nil {
+		return 0, err
+	}
+	return t.ID, nil
+}
+
+func AddTerm(db *sql.DB, t *models.Term) (sql.Result, error) {
+	if err := checkTermWithinYear(db, t); err != nil {
+		return nil, err
+	}
+
+	return db.Exec("INSERT INTO terms (academic_year_id,name,start_date,end_date) VALUES (?,?,?,?)",
+		t.AcademicYearId, t.Name, t.StartDate, t.EndDate)
+}
+
+func UpdateTerm(db *sql.DB, existing, update *models.Term, id int) (sql.Result, error) {
+	err := scanTerm(db.QueryRow("SELECT "+termColumns+" FROM terms t WHERE t.id = ?", id), existing)
+
+	if err != nil {
+		return nil, err
+	}
+
+	update.ID = existing.ID
+	update.AcademicYearId = existing.AcademicYearId
+	update.IsCurrent = existing.IsCurrent
+	// Simple conditional updates
+	if update.Name == "" {
+		update.Name = existing.Name
+	}
+	if update.StartDate == "" {
+		update.StartDate = existing.StartDate
+	}
+	if update.EndDate == "" {
+		update.EndDate = existing.EndDate
+	}
+
+	if err := checkTermWithinYear(db, update); err != nil {
+		return nil, err
+	}
+
+	return db.Exec("UPDATE terms SET name=?, start_date=?, end_date=? WHERE id=?", update.Name, update.StartDate, update.EndDate, id)
+}
+
+func DeleteTerm(db *sql.DB, id int) error {
+	return deleteByID(db, "terms", id)
+}
+
+// SetCurrentTerm makes id the only current term and places every active
+// student not yet enrolled in it in their current class.
+func SetCurrentTerm(db *sql.DB, id int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	var tmp int
+	err = tx.QueryRow("SELECT id FROM terms WHERE id = ?", id).Scan(&tmp)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE terms SET is_current = FALSE WHERE is_current")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE terms SET is_current = TRUE WHERE id = ?", id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// students not yet placed in the term keep their current class, so
+	// they still show up in term-scoped lists
+	_, err = tx.Exec(`
+		INSERT INTO enrollments (student_id, class_id, term_id, status)
+		SELECT s.id, s.class_id, ?, 'enrolled'
+		FROM student s
+		WHERE s.status = 'active'
+			AND NOT EXISTS (SELECT 1 FROM enrollments e WHERE e.student_id = s.id AND e.term_id = ?)
+	`, id, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func checkTermWithinYear(db *sql.DB, t *models.Term) error {
+	if t.StartDate >= t.EndDate {
+		return fmt.Errorf("start_date must be before end_date")
+	}
+
+	year, err := FindAcademicYearByID(t.AcademicYearId, db)
+	if err != nil {
+		return err
+	}
+	if year == nil {
+		return fmt.Errorf("academic year %d not found", t.AcademicYearId)
+	}
+
+	if t.StartDate < year.StartDate || t.EndDate > year.EndDate {
+		return fmt.Errorf("term must fall within academic year %s (%s to %s)", year.Name, year.StartDate, year.EndDate)
+	}
+
+	return nil
+}
+
+// UpsertEnrollment places a student in a class for a term, replacing any
+// existing placement for that term.
+func UpsertEnrollment(db execer, studentId, classId, termId int, status string) error {
+	_, err := db.Exec(`
+		INSERT INTO enrollments (student_id, class_id, term_id, status)
+		VALUES (?,?,?,?)
+		ON DUPLICATE KEY UPDATE class_id = VALUES(class_id), status = VALUES(status)
+	`, studentId, classId, termId, status)
+
+	return err
+}
+
+func FindEnrollments(db *sql.DB, studentId int) ([]models.Enrollment, error) {
+	rows, err := db.Query(`
+		SELECT e.id, e.student_id, e.class_id, c.name, e.term_id, t.name, e.status, e.created_at
+		FROM enrollments e
+		JOIN classes c ON e.class_id = c.id
+		JOIN terms t ON e.term_id = t.id
+		WHERE e.student_id = ?
+		ORDER BY t.start_date
+	`, studentId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var enrollments []models.Enrollment
+	for rows.Next() {
+		var e models.Enrollment
+		if err := rows.Scan(&e.ID, &e.StudentId, &e.ClassId, &e.ClassName, &e.TermId, &e.TermName, &e.Status, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		enrollments = append(enrollments, e)
+	}
+
+	return enrollments, rows.Err()
+}
+
+func deleteByID(db *sql.DB, table string, id int) error {
+	result, err := db.Exec("DELETE FROM "+table+" WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
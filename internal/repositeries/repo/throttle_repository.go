@@ -0,0 +1,143 @@
+package repo
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	ThrottleUser = "user"
+	ThrottleIP   = "ip"
+)
+
+// ThrottleKey is what failed attempts are counted against: a username or
+// email (ThrottleUser) or a client address (ThrottleIP) for one action.
+type ThrottleKey struct {
+	Action  string
+	Kind    string
+	Subject string
+}
+
+// ThrottlePolicy says how failures turn into waiting. After a failure the
+// next attempt has to wait Delay, doubling with every further failure up to
+// MaxDelay. At MaxFailures the key is locked out for Lockout. Failures are
+// forgotten once none has happened for Window.
+type ThrottlePolicy struct {
+	MaxFailures int
+	Delay       time.Duration
+	MaxDelay    time.Duration
+	Lockout     time.Duration
+	Window      time.Duration
+}
+
+// wait is how long the key is blocked after its nth failure, and whether
+// that is a lockout.
+func (p ThrottlePolicy) wait(failures int) (time.Duration, bool) {
+	if failures >= p.MaxFailures {
+		return p.Lockout, true
+	}
+
+	// the first failure is free
+	if failures < 2 {
+		return 0, false
+	}
+
+	d := p.Delay
+	for i := 2; i < failures && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	return min(d, p.MaxDelay), false
+}
+
+// ThrottleWait returns how long the caller has to wait before trying again,
+// the longest over keys. It is zero when none of them is blocked.
+func ThrottleWait(db *sql.DB, keys ...ThrottleKey) (time.Duration, error) {
+	var longest time.Duration
+
+	for _, k := range keys {
+		var seconds int
+
+		err := db.QueryRow(`
+			SELECT TIMESTAMPDIFF(SECOND, NOW(), blocked_until) FROM auth_throttle
+			WHERE action = ? AND kind = ? AND subject = ? AND blocked_until > NOW()
+		`, k.Action, k.Kind, k.Subject).Scan(&seconds)
+
+		if err == sql.ErrNoRows {
+			continue
+		} else if err != nil {
+			return 0, err
+		}
+
+		// round up so a block never reads as zero while it lasts
+		if d := time.Duration(seconds+1) * time.Second; d > longest {
+			longest = d
+		}
+	}
+
+	return longest, nil
+}
+
+// RecordFailedAttempt counts a failure against key. It reports true when
+// this failure locked the key out, so the owner can be told once.
+func RecordFailedAttempt(db *sql.DB, key ThrottleKey, policy ThrottlePolicy) (bool, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	var failures int
+	var stale, wasLocked bool
+
+	err = tx.QueryRow(`
+		SELECT failures, locked,
+			last_failure_at < NOW() - INTERVAL ? SECOND AND COALESCE(blocked_until <= NOW(), TRUE)
+		FROM auth_throttle WHERE action = ? AND kind = ? AND subject = ? FOR UPDATE
+	`, int(policy.Window.Seconds()), key.Action, key.Kind, key.Subject).Scan(&failures, &wasLocked, &stale)
+
+	if err != nil && err != sql.ErrNoRows {
+		tx.Rollback()
+		return false, err
+	}
+
+	if stale {
+		failures, wasLocked = 0, false
+	}
+
+	failures++
+	wait, locked := policy.wait(failures)
+
+	_, err = tx.Exec(`
+		INSERT INTO auth_throttle (action, kind, subject, failures, last_failure_at, blocked_until, locked)
+		VALUES (?, ?, ?, ?, NOW(), NOW() + INTERVAL ? SECOND, ?)
+		ON DUPLICATE KEY UPDATE failures = VALUES(failures), last_failure_at = VALUES(last_failure_at),
+			blocked_until = VALUES(blocked_until), locked = VALUES(locked)
+	`, key.Action, key.Kind, key.Subject, failures, int(wait.Seconds()), locked)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	return locked && !wasLocked, tx.Commit()
+}
+
+// ClearAttempts forgets the failures counted against key, as after a
+// successful login.
+func ClearAttempts(db *sql.DB, key ThrottleKey) error {
+	_, err := db.Exec("DELETE FROM auth_throttle WHERE action = ? AND kind = ? AND subject = ?", key.Action, key.Kind, key.Subject)
+	return err
+}
+
+// UnlockExec lifts every block on the exec's username and email. Blocks on
+// client addresses are left alone. It returns sql.ErrNoRows when the exec
+// does not exist.
+func UnlockExec(db *sql.DB, execId int) error {
+	var username, email string
+
+	err := db.QueryRow("SELECT username, email FROM execs WHERE id = ?", execId).Scan(&username, &email)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec("DELETE FROM auth_throttle WHERE kind = ? AND subject IN (?, ?)", ThrottleUser, username, email)
+	return err
+}
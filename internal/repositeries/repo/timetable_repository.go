@@ -0,0 +1,221 @@
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+	"school-api/internal/models"
+	"strings"
+	"time"
+)
+
+const timetableColumns = `
+	t.id, t.term_id, t.day_of_week, t.period,
+	TIME_FORMAT(t.start_time, '%H:%i'), TIME_FORMAT(t.end_time, '%H:%i'),
+	t.class_id, c.name, t.teacher_id, CONCAT(te.first_name, ' ', te.last_name),
+	t.subject, COALESCE(t.room, '')
+`
+
+const timetableFrom = `
+	FROM timetable_slots t
+	JOIN classes c ON t.class_id = c.id
+	JOIN teachers te ON t.teacher_id = te.id
+`
+
+func scanTimetableSlot(scanner interface{ Scan(...any) error }, s *models.TimetableSlot) error {
+	return scanner.Scan(&s.ID, &s.TermId, &s.DayOfWeek, &s.Period, &s.StartTime, &s.EndTime,
+		&s.ClassId, &s.ClassName, &s.TeacherId, &s.TeacherName, &s.Subject, &s.Room)
+}
+
+func FindTimetableSlotByID(id int, db *sql.DB) (*models.TimetableSlot, error) {
+	var s models.TimetableSlot
+
+	err := scanTimetableSlot(db.QueryRow("SELECT "+timetableColumns+timetableFrom+" WHERE t.id = ?", id), &s)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// FindTimetable lists the slots of one term matching the filters, ordered
+// through the week.
+func FindTimetable(db *sql.DB, filters map[string]string, termId int) ([]models.TimetableSlot, error) {
+	query := "SELECT " + timetableColumns + timetableFrom + " WHERE t.term_id = ?"
+
+	args := []any{termId}
+
+	for key, val := range filters {
+		if val == "" {
+			continue
+		}
+
+		query += " AND t." + key + " = ?"
+		args = append(args, val)
+	}
+
+	query += " ORDER BY t.day_of_week, t.period, c.name"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var slots []models.TimetableSlot
+	for rows.Next() {
+		var s models.TimetableSlot
+		if err := scanTimetableSlot(rows, &s); err != nil {
+			return nil, err
+		}
+		slots = append(slots, s)
+	}
+
+	return slots, rows.Err()
+}
+
+func AddTimetableSlot(db *sql.DB, s *models.TimetableSlot) (sql.Result, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkTimetableConflicts(tx, s, 0); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	res, err := tx.Exec(`
+		INSERT INTO timetable_slots (term_id, day_of_week, period, start_time, end_time, class_id, teacher_id, subject, room)
+		VALUES (?,?,?,?,?,?,?,?,?)
+	`, s.TermId, s.DayOfWeek, s.Period, s.StartTime, s.EndTime, s.ClassId, s.TeacherId, s.Subject, nullableString(s.Room))
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return res, tx.Commit()
+}
+
+func UpdateTimetableSlot(db *sql.DB, existing, update *models.TimetableSlot, id int) (sql.Result, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	err = scanTimetableSlot(tx.QueryRow("SELECT "+timetableColumns+timetableFrom+" WHERE t.id = ?", id), existing)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	update.ID = existing.ID
+	// Simple conditional updates
+	if update.TermId == 0 {
+		update.TermId = existing.TermId
+	}
+	if update.DayOfWeek == 0 {
+		update.DayOfWeek = existing.DayOfWeek
+	}
+	if update.Period == 0 {
+		update.Period = existing.Period
+	}
+	if update.StartTime == "" {
+		update.StartTime = existing.StartTime
+	}
+	if update.EndTime == "" {
+		update.EndTime = existing.EndTime
+	}
+	if update.ClassId == 0 {
+		update.ClassId = existing.ClassId
+	}
+	if update.TeacherId == 0 {
+		update.TeacherId = existing.TeacherId
+	}
+	if update.Subject == "" {
+		update.Subject = existing.Subject
+	}
+	if update.Room == "" {
+		update.Room = existing.Room
+	}
+
+	if update.StartTime >= update.EndTime {
+		tx.Rollback()
+		return nil, fmt.Errorf("start_time must be before end_time")
+	}
+
+	if err := checkTimetableConflicts(tx, update, id); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	res, err := tx.Exec(`
+		UPDATE timetable_slots SET term_id=?, day_of_week=?, period=?, start_time=?, end_time=?, class_id=?, teacher_id=?, subject=?, room=?
+		WHERE id=?
+	`, update.TermId, update.DayOfWeek, update.Period, update.StartTime, update.EndTime, update.ClassId, update.TeacherId, update.Subject, nullableString(update.Room), id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return res, tx.Commit()
+}
+
+func DeleteTimetableSlot(db *sql.DB, id int) error {
+	return deleteByID(db, "timetable_slots", id)
+}
+
+// checkTimetableConflicts rejects a slot whose class, teacher or room is
+// already booked in the same term, day and period. excludeId skips the slot
+// being updated.
+func checkTimetableConflicts(tx *sql.Tx, s *models.TimetableSlot, excludeId int) error {
+	rows, err := tx.Query(`
+		SELECT class_id, teacher_id, COALESCE(room, '')
+		FROM timetable_slots
+		WHERE term_id = ? AND day_of_week = ? AND period = ? AND id <> ?
+			AND (class_id = ? OR teacher_id = ? OR room = ?)
+	`, s.TermId, s.DayOfWeek, s.Period, excludeId, s.ClassId, s.TeacherId, nullableString(s.Room))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	when := fmt.Sprintf("on %s period %d", weekday(s.DayOfWeek), s.Period)
+
+	var conflicts []string
+	for rows.Next() {
+		var classId, teacherId int
+		var room string
+		if err := rows.Scan(&classId, &teacherId, &room); err != nil {
+			return err
+		}
+
+		if classId == s.ClassId {
+			conflicts = append(conflicts, fmt.Sprintf("class %d already has a lesson %s", classId, when))
+		}
+		if teacherId == s.TeacherId {
+			conflicts = append(conflicts, fmt.Sprintf("teacher %d is already teaching %s", teacherId, when))
+		}
+		if s.Room != "" && room == s.Room {
+			conflicts = append(conflicts, fmt.Sprintf("room %s is already booked %s", room, when))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%s", strings.Join(conflicts, "; "))
+	}
+
+	return nil
+}
+
+// weekday converts a day_of_week (1 = Monday .. 7 = Sunday).
+func weekday(day int) time.Weekday {
+	return time.Weekday(day % 7)
+}
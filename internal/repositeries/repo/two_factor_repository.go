@@ -0,0 +1,165 @@
+package repo
+
+import (
+	"database/sql"
+	"strings"
+)
+
+const recoveryCodeCount = 10
+
+// FindTOTPSecret returns the exec's TOTP secret and whether 2FA is enabled.
+// The secret is empty when enrollment has not started.
+func FindTOTPSecret(db *sql.DB, execId int) (string, bool, error) {
+	var secret sql.NullString
+	var enabled bool
+
+	err := db.QueryRow("SELECT totp_secret, totp_enabled FROM execs WHERE id = ?", execId).Scan(&secret, &enabled)
+	if err != nil {
+		return "", false, err
+	}
+
+	return secret.String, enabled, nil
+}
+
+// SetTOTPSecret starts enrollment with a new secret. It returns
+// sql.ErrNoRows when 2FA is already enabled.
+func SetTOTPSecret(db *sql.DB, execId int, secret string) error {
+	result, err := db.Exec("UPDATE execs SET totp_secret = ? WHERE id = ? AND totp_enabled = FALSE", secret, execId)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// EnableTwoFactor finishes enrollment once a code for step has been
+// checked against the pending secret, and returns a fresh set of recovery
+// codes.
+func EnableTwoFactor(db *sql.DB, execId int, step int64) ([]string, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec("UPDATE execs SET totp_enabled = TRUE, totp_enabled_at = NOW(), totp_last_step = ? WHERE id = ?", step, execId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	codes, err := replaceRecoveryCodes(tx, execId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return codes, tx.Commit()
+}
+
+// DisableTwoFactor removes the secret and recovery codes.
+func DisableTwoFactor(db *sql.DB, execId int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE execs SET totp_secret = NULL, totp_enabled = FALSE, totp_enabled_at = NULL, totp_last_step = 0 WHERE id = ?", execId)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM exec_recovery_codes WHERE exec_id = ?", execId); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RegenerateRecoveryCodes replaces all of the exec's recovery codes, used
+// or not.
+func RegenerateRecoveryCodes(db *sql.DB, execId int) ([]string, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := replaceRecoveryCodes(tx, execId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return codes, tx.Commit()
+}
+
+func CountRecoveryCodes(db *sql.DB, execId int) (int, error) {
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM exec_recovery_codes WHERE exec_id = ? AND used_at IS NULL", execId).Scan(&count)
+	return count, err
+}
+
+// UseTOTPStep spends a time step. It reports false when that step, or a
+// later one, was already accepted, so a code cannot be replayed.
+func UseTOTPStep(db *sql.DB, execId int, step int64) (bool, error) {
+	result, err := db.Exec("UPDATE execs SET totp_last_step = ? WHERE id = ? AND totp_enabled = TRUE AND totp_last_step < ?", step, execId, step)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	return rowsAffected == 1, err
+}
+
+// UseRecoveryCode spends one of the exec's recovery codes. It reports false
+// when the code is unknown or already used.
+func UseRecoveryCode(db *sql.DB, execId int, code string) (bool, error) {
+	result, err := db.Exec("UPDATE exec_recovery_codes SET used_at = NOW() WHERE exec_id = ? AND code_hash = ? AND used_at IS NULL",
+		execId, hashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	return rowsAffected == 1, err
+}
+
+func replaceRecoveryCodes(tx *sql.Tx, execId int) ([]string, error) {
+	if _, err := tx.Exec("DELETE FROM exec_recovery_codes WHERE exec_id = ?", execId); err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		raw, err := randomToken(5)
+		if err != nil {
+			return nil, err
+		}
+
+		// shown as xxxxx-xxxxx
+		code := raw[:5] + "-" + raw[5:]
+
+		_, err = tx.Exec("INSERT INTO exec_recovery_codes (exec_id, code_hash) VALUES (?,?)", execId, hashToken(normalizeRecoveryCode(code)))
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+
+	return codes, nil
+}
+
+// recovery codes are matched without the dash and regardless of case
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
@@ -0,0 +1,96 @@
+// Package ical writes iCalendar (RFC 5545) files with weekly recurring
+// events, enough for calendar apps to import a timetable.
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"time"
+)
+
+const (
+	localLayout = "20060102T150405"
+	utcLayout   = "20060102T150405Z"
+)
+
+// Event is a single event. Times are written as floating local times; when
+// Until is set the event repeats weekly up to and including that moment.
+type Event struct {
+	UID         string
+	Summary     string
+	Location    string
+	Description string
+	Start       time.Time
+	End         time.Time
+	Until       time.Time
+}
+
+type Calendar struct {
+	name   string
+	events []Event
+}
+
+func New(name string) *Calendar {
+	return &Calendar{name: name}
+}
+
+func (c *Calendar) Add(e Event) {
+	c.events = append(c.events, e)
+}
+
+func (c *Calendar) Bytes() []byte {
+	var buf bytes.Buffer
+	stamp := time.Now().UTC().Format(utcLayout)
+
+	writeLine(&buf, "BEGIN:VCALENDAR")
+	writeLine(&buf, "VERSION:2.0")
+	writeLine(&buf, "PRODID:-//school-api//timetable//EN")
+	writeLine(&buf, "CALSCALE:GREGORIAN")
+	writeLine(&buf, "X-WR-CALNAME:"+escape(c.name))
+
+	for _, e := range c.events {
+		writeLine(&buf, "BEGIN:VEVENT")
+		writeLine(&buf, "UID:"+escape(e.UID))
+		writeLine(&buf, "DTSTAMP:"+stamp)
+		writeLine(&buf, "DTSTART:"+e.Start.Format(localLayout))
+		writeLine(&buf, "DTEND:"+e.End.Format(localLayout))
+		if !e.Until.IsZero() {
+			writeLine(&buf, "RRULE:FREQ=WEEKLY;UNTIL="+e.Until.Format(localLayout))
+		}
+		writeLine(&buf, "SUMMARY:"+escape(e.Summary))
+		if e.Location != "" {
+			writeLine(&buf, "LOCATION:"+escape(e.Location))
+		}
+		if e.Description != "" {
+			writeLine(&buf, "DESCRIPTION:"+escape(e.Description))
+		}
+		writeLine(&buf, "END:VEVENT")
+	}
+
+	writeLine(&buf, "END:VCALENDAR")
+
+	return buf.Bytes()
+}
+
+func escape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+// writeLine folds lines longer than 75 octets as the spec requires, without
+// splitting a UTF-8 sequence.
+func writeLine(buf *bytes.Buffer, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74 // the leading space counts towards the next line
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
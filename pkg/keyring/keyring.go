@@ -0,0 +1,323 @@
+// Package keyring holds the keys tokens are signed and verified with. Each
+// key has an id, sent as the kid header, and a window in which it signs.
+// A key keeps verifying for a grace period after its window closes, so
+// tokens it signed stay good while they live.
+//
+// Keys come from the JSON file named by JWT_KEYS_FILE:
+//
+//	[
+//	  {"kid": "2026-10", "alg": "EdDSA", "private_key_file": "/etc/school-api/jwt-2026-10.pem",
+//	   "not_before": "2026-10-01T00:00:00Z", "not_after": "2026-11-01T00:00:00Z"},
+//	  {"kid": "2026-11", "alg": "RS256", "private_key_file": "/etc/school-api/jwt-2026-11.pem",
+//	   "not_before": "2026-11-01T00:00:00Z"},
+//	  {"kid": "default", "alg": "HS256", "secret_env": "JWT_SECRET",
+//	   "not_after": "2026-10-01T00:00:00Z"}
+//	]
+//
+// Rotation is scheduled by adding the next key with a future not_before.
+// Without JWT_KEYS_FILE there is a single HS256 key, "default", using
+// JWT_SECRET. Tokens without a kid are checked against "default".
+package keyring
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+
+	// LegacyKeyID is the key tokens without a kid are checked against.
+	LegacyKeyID = "default"
+
+	defaultGrace = time.Hour
+)
+
+var (
+	ErrNoSigningKey = errors.New("keyring: no key is active for signing")
+	ErrUnknownKey   = errors.New("keyring: unknown or retired key")
+)
+
+type Key struct {
+	ID        string
+	Algorithm string
+	NotBefore time.Time
+	NotAfter  time.Time
+
+	signKey   any
+	verifyKey any
+}
+
+func (k *Key) method() jwt.SigningMethod {
+	return jwt.GetSigningMethod(k.Algorithm)
+}
+
+// signs reports whether the key is the one to sign with at t, ignoring
+// newer keys.
+func (k *Key) signs(t time.Time) bool {
+	return !t.Before(k.NotBefore) && (k.NotAfter.IsZero() || t.Before(k.NotAfter))
+}
+
+// verifies reports whether tokens signed by the key are still accepted at t.
+func (k *Key) verifies(t time.Time, grace time.Duration) bool {
+	return k.NotAfter.IsZero() || t.Before(k.NotAfter.Add(grace))
+}
+
+type Keyring struct {
+	keys  []*Key
+	grace time.Duration
+}
+
+// config is one entry of the JWT_KEYS_FILE.
+type config struct {
+	ID             string    `json:"kid"`
+	Algorithm      string    `json:"alg"`
+	PrivateKeyFile string    `json:"private_key_file"`
+	Secret         string    `json:"secret"`
+	SecretEnv      string    `json:"secret_env"`
+	NotBefore      time.Time `json:"not_before"`
+	NotAfter       time.Time `json:"not_after"`
+}
+
+var (
+	once    sync.Once
+	current *Keyring
+	loadErr error
+)
+
+// Default is the keyring configured by the environment, loaded on first
+// use. JWT_KEY_GRACE sets the grace period, one hour by default.
+func Default() (*Keyring, error) {
+	once.Do(func() {
+		current, loadErr = Load(os.Getenv("JWT_KEYS_FILE"))
+	})
+	return current, loadErr
+}
+
+// Load reads a keys file, or sets up the single JWT_SECRET key when path
+// is empty.
+func Load(path string) (*Keyring, error) {
+	grace := defaultGrace
+	if d, err := time.ParseDuration(os.Getenv("JWT_KEY_GRACE")); err == nil && d >= 0 {
+		grace = d
+	}
+
+	configs := []config{{ID: LegacyKeyID, Algorithm: HS256, SecretEnv: "JWT_SECRET"}}
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		configs = nil
+		if err := json.Unmarshal(data, &configs); err != nil {
+			return nil, fmt.Errorf("keyring: %s: %w", path, err)
+		}
+	}
+
+	kr := &Keyring{grace: grace}
+	seen := map[string]bool{}
+
+	for _, c := range configs {
+		if c.ID == "" || seen[c.ID] {
+			return nil, fmt.Errorf("keyring: missing or duplicate kid %q", c.ID)
+		}
+		seen[c.ID] = true
+
+		k, err := newKey(c)
+		if err != nil {
+			return nil, fmt.Errorf("keyring: key %s: %w", c.ID, err)
+		}
+		kr.keys = append(kr.keys, k)
+	}
+
+	return kr, nil
+}
+
+func newKey(c config) (*Key, error) {
+	k := &Key{ID: c.ID, Algorithm: c.Algorithm, NotBefore: c.NotBefore, NotAfter: c.NotAfter}
+
+	if c.Algorithm == HS256 {
+		secret := c.Secret
+		if c.SecretEnv != "" {
+			secret = os.Getenv(c.SecretEnv)
+		}
+		if secret == "" {
+			return nil, errors.New("HS256 needs a secret")
+		}
+		k.signKey, k.verifyKey = []byte(secret), []byte(secret)
+		return k, nil
+	}
+
+	pem, err := os.ReadFile(c.PrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	switch c.Algorithm {
+	case RS256:
+		private, err := jwt.ParseRSAPrivateKeyFromPEM(pem)
+		if err != nil {
+			return nil, err
+		}
+		k.signKey, k.verifyKey = private, &private.PublicKey
+
+	case EdDSA:
+		private, err := jwt.ParseEdPrivateKeyFromPEM(pem)
+		if err != nil {
+			return nil, err
+		}
+		k.signKey, k.verifyKey = private, private.(crypto.Signer).Public()
+
+	default:
+		return nil, fmt.Errorf("unsupported alg %q", c.Algorithm)
+	}
+
+	return k, nil
+}
+
+// SigningKey is the key to sign with now: of the keys whose window is open,
+// the one that opened last.
+func (kr *Keyring) SigningKey() (*Key, error) {
+	now := time.Now()
+
+	var signer *Key
+	for _, k := range kr.keys {
+		if k.signs(now) && (signer == nil || !k.NotBefore.Before(signer.NotBefore)) {
+			signer = k
+		}
+	}
+
+	if signer == nil {
+		return nil, ErrNoSigningKey
+	}
+	return signer, nil
+}
+
+// Sign signs claims with the current signing key and sets its kid.
+func (kr *Keyring) Sign(claims jwt.Claims) (string, error) {
+	k, err := kr.SigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(k.method(), claims)
+	token.Header["kid"] = k.ID
+
+	return token.SignedString(k.signKey)
+}
+
+// Parse verifies a token against the key named by its kid and returns its
+// claims. The token's alg has to match the key's.
+func (kr *Keyring) Parse(tokenStr string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+
+	_, err := jwt.ParseWithClaims(tokenStr, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		if kid == "" {
+			kid = LegacyKeyID
+		}
+
+		k := kr.find(kid)
+		if k == nil || !k.verifies(time.Now(), kr.grace) {
+			return nil, ErrUnknownKey
+		}
+
+		if t.Method.Alg() != k.Algorithm {
+			return nil, jwt.ErrSignatureInvalid
+		}
+
+		return k.verifyKey, nil
+	}, jwt.WithValidMethods([]string{HS256, RS256, EdDSA}))
+
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (kr *Keyring) find(kid string) *Key {
+	for _, k := range kr.keys {
+		if k.ID == kid {
+			return k
+		}
+	}
+	return nil
+}
+
+// JWK is a public key in JSON Web Key form.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// Ed25519
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS lists the public keys that sign now, will sign later or still
+// verify. HS256 keys are secret and never listed.
+func (kr *Keyring) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	now := time.Now()
+
+	for _, k := range kr.keys {
+		if !k.verifies(now, kr.grace) {
+			continue
+		}
+
+		jwk := JWK{Kid: k.ID, Use: "sig", Alg: k.Algorithm}
+
+		switch public := k.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = b64(public.N.Bytes())
+			jwk.E = b64(bigEndian(public.E))
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = b64(public)
+		default:
+			continue
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// bigEndian is n in the fewest bytes, as JWK wants the RSA exponent.
+func bigEndian(n int) []byte {
+	var b []byte
+	for ; n > 0; n >>= 8 {
+		b = append([]byte{byte(n)}, b...)
+	}
+	return b
+}
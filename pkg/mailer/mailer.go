@@ -0,0 +1,83 @@
+// Package mailer sends email. The backend is picked by MAIL_DRIVER:
+//
+//	smtp  MAIL_SMTP_HOST, MAIL_SMTP_PORT (587), MAIL_SMTP_USERNAME,
+//	      MAIL_SMTP_PASSWORD and MAIL_SMTP_TLS ("starttls", the default,
+//	      "tls" for implicit TLS on connect, or "none" for a local stand-in
+//	      such as MailHog)
+//	file  writes each message to MAIL_FILE_DIR as an .eml file
+//	log   writes who each message is for to the server log, but not the
+//	      body, which can hold reset links (the default)
+//
+// MAIL_FROM is the sender address for every backend.
+package mailer
+
+import (
+	"errors"
+	"log"
+	"os"
+	"strconv"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// FromEnv builds the mailer the environment configures.
+func FromEnv() (Mailer, error) {
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "no-reply@localhost"
+	}
+
+	switch os.Getenv("MAIL_DRIVER") {
+	case "smtp":
+		host := os.Getenv("MAIL_SMTP_HOST")
+		if host == "" {
+			return nil, errors.New("mailer: MAIL_SMTP_HOST is required for the smtp driver")
+		}
+
+		port := 587
+		if p, err := strconv.Atoi(os.Getenv("MAIL_SMTP_PORT")); err == nil {
+			port = p
+		}
+
+		return &SMTP{
+			Host:     host,
+			Port:     port,
+			Username: os.Getenv("MAIL_SMTP_USERNAME"),
+			Password: os.Getenv("MAIL_SMTP_PASSWORD"),
+			TLS:      os.Getenv("MAIL_SMTP_TLS"),
+			From:     from,
+		}, nil
+
+	case "file":
+		dir := os.Getenv("MAIL_FILE_DIR")
+		if dir == "" {
+			dir = "tmp/mail"
+		}
+		return NewFile(dir, from)
+
+	case "", "log":
+		return Log{From: from}, nil
+	}
+
+	return nil, errors.New("mailer: unknown MAIL_DRIVER " + os.Getenv("MAIL_DRIVER"))
+}
+
+// Log notes messages in the server log without their bodies, since those
+// can carry links that work as credentials. Use File to read them.
+type Log struct {
+	From string
+}
+
+func (l Log) Send(msg Message) error {
+	log.Printf("mail from %s to %s: %s (body not logged, set MAIL_DRIVER=file to keep messages)", l.From, msg.To, msg.Subject)
+	return nil
+}
@@ -0,0 +1,86 @@
+package mailer
+
+import (
+	"log"
+	"time"
+)
+
+// Queued is a message waiting in the outbox.
+type Queued struct {
+	ID       int
+	Template string
+	Attempts int
+	Message  Message
+}
+
+// Store is where queued mail waits until it is delivered.
+type Store interface {
+	// Claim takes up to limit due messages and hides them from other
+	// workers for lease.
+	Claim(limit int, lease time.Duration) ([]Queued, error)
+	MarkSent(id int) error
+	// MarkFailed counts a failed attempt. The message is due again after
+	// retryIn, or never when giveUp is set.
+	MarkFailed(id int, sendErr error, retryIn time.Duration, giveUp bool) error
+}
+
+// Outbox delivers queued mail, retrying failures with doubling delays.
+type Outbox struct {
+	Store         Store
+	Mailer        Mailer
+	Batch         int
+	Lease         time.Duration
+	RetryDelay    time.Duration
+	MaxRetryDelay time.Duration
+	MaxAttempts   int
+}
+
+// RetryIn is how long to wait after a message's nth failed attempt.
+func (o *Outbox) RetryIn(attempts int) time.Duration {
+	wait := o.RetryDelay
+	for i := 1; i < attempts && wait < o.MaxRetryDelay; i++ {
+		wait *= 2
+	}
+	return min(wait, o.MaxRetryDelay)
+}
+
+// Deliver sends one batch and returns how many messages it claimed.
+func (o *Outbox) Deliver() (int, error) {
+	batch, err := o.Store.Claim(o.Batch, o.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, q := range batch {
+		err := o.Mailer.Send(q.Message)
+
+		if err == nil {
+			err = o.Store.MarkSent(q.ID)
+		} else {
+			attempts := q.Attempts + 1
+			log.Printf("mail outbox: %s to %s, attempt %d: %v", q.Template, q.Message.To, attempts, err)
+			err = o.Store.MarkFailed(q.ID, err, o.RetryIn(attempts), attempts >= o.MaxAttempts)
+		}
+
+		if err != nil {
+			log.Println("mail outbox:", err)
+		}
+	}
+
+	return len(batch), nil
+}
+
+// Run delivers every interval, draining the backlog each time.
+func (o *Outbox) Run(interval time.Duration) {
+	for range time.Tick(interval) {
+		for {
+			n, err := o.Deliver()
+			if err != nil {
+				log.Println("mail outbox:", err)
+			}
+			if n < o.Batch {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package mailer
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// memoryStore is an outbox in memory. Claimed messages are leased until
+// they are marked.
+type memoryStore struct {
+	now    time.Time
+	queued map[int]*storedMail
+	nextID int
+}
+
+type storedMail struct {
+	Queued
+	status  string
+	dueAt   time.Time
+	lastErr error
+	retryIn time.Duration
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{now: time.Unix(1_700_000_000, 0), queued: map[int]*storedMail{}}
+}
+
+func (s *memoryStore) add(to string) int {
+	s.nextID++
+	s.queued[s.nextID] = &storedMail{
+		Queued: Queued{ID: s.nextID, Template: Lockout, Message: Message{To: to, Subject: "Locked"}},
+		status: "pending",
+		dueAt:  s.now,
+	}
+	return s.nextID
+}
+
+func (s *memoryStore) Claim(limit int, lease time.Duration) ([]Queued, error) {
+	var claimed []Queued
+	for id := 1; id <= s.nextID && len(claimed) < limit; id++ {
+		m := s.queued[id]
+		if m.status == "pending" && !m.dueAt.After(s.now) {
+			m.dueAt = s.now.Add(lease)
+			claimed = append(claimed, m.Queued)
+		}
+	}
+	return claimed, nil
+}
+
+func (s *memoryStore) MarkSent(id int) error {
+	m := s.queued[id]
+	m.status = "sent"
+	m.Attempts++
+	return nil
+}
+
+func (s *memoryStore) MarkFailed(id int, sendErr error, retryIn time.Duration, giveUp bool) error {
+	m := s.queued[id]
+	m.Attempts++
+	m.lastErr = sendErr
+	m.retryIn = retryIn
+	m.dueAt = s.now.Add(retryIn)
+	if giveUp {
+		m.status = "failed"
+	}
+	return nil
+}
+
+// recorder is a mailer that fails for chosen recipients.
+type recorder struct {
+	sent    []string
+	failFor map[string]bool
+}
+
+func (r *recorder) Send(msg Message) error {
+	if r.failFor[msg.To] {
+		return errors.New("connection refused")
+	}
+	r.sent = append(r.sent, msg.To)
+	return nil
+}
+
+func newOutbox(store Store, m Mailer) *Outbox {
+	return &Outbox{
+		Store:         store,
+		Mailer:        m,
+		Batch:         2,
+		Lease:         10 * time.Minute,
+		RetryDelay:    time.Minute,
+		MaxRetryDelay: time.Hour,
+		MaxAttempts:   3,
+	}
+}
+
+func TestOutboxClaimsInBatches(t *testing.T) {
+	store := newMemoryStore()
+	for _, to := range []string{"a@x.test", "b@x.test", "c@x.test"} {
+		store.add(to)
+	}
+
+	m := &recorder{}
+	outbox := newOutbox(store, m)
+
+	n, err := outbox.Deliver()
+	if err != nil || n != 2 {
+		t.Fatalf("first Deliver = %d, %v; want 2", n, err)
+	}
+
+	n, err = outbox.Deliver()
+	if err != nil || n != 1 {
+		t.Fatalf("second Deliver = %d, %v; want 1", n, err)
+	}
+
+	if n, _ := outbox.Deliver(); n != 0 {
+		t.Fatalf("third Deliver claimed %d sent messages again", n)
+	}
+
+	if len(m.sent) != 3 {
+		t.Errorf("sent %v, want all three", m.sent)
+	}
+	for id, q := range store.queued {
+		if q.status != "sent" || q.Attempts != 1 {
+			t.Errorf("message %d: status %s after %d attempts", id, q.status, q.Attempts)
+		}
+	}
+}
+
+func TestOutboxRetryBackoff(t *testing.T) {
+	outbox := newOutbox(nil, nil)
+
+	for attempts, want := range map[int]time.Duration{
+		1:  time.Minute,
+		2:  2 * time.Minute,
+		3:  4 * time.Minute,
+		6:  32 * time.Minute,
+		7:  time.Hour,
+		20: time.Hour,
+	} {
+		if got := outbox.RetryIn(attempts); got != want {
+			t.Errorf("RetryIn(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+
+	store := newMemoryStore()
+	id := store.add("down@x.test")
+	outbox = newOutbox(store, &recorder{failFor: map[string]bool{"down@x.test": true}})
+
+	outbox.Deliver()
+	if q := store.queued[id]; q.status != "pending" || q.retryIn != time.Minute || q.lastErr == nil {
+		t.Fatalf("after one failure: status %s, retry in %s, error %v", q.status, q.retryIn, q.lastErr)
+	}
+
+	// not due yet
+	if n, _ := outbox.Deliver(); n != 0 {
+		t.Fatalf("retried before the backoff ran out")
+	}
+
+	store.now = store.now.Add(time.Minute)
+	outbox.Deliver()
+	if q := store.queued[id]; q.Attempts != 2 || q.retryIn != 2*time.Minute {
+		t.Fatalf("after two failures: %d attempts, retry in %s", q.Attempts, q.retryIn)
+	}
+}
+
+func TestOutboxGivesUp(t *testing.T) {
+	store := newMemoryStore()
+	id := store.add("down@x.test")
+	outbox := newOutbox(store, &recorder{failFor: map[string]bool{"down@x.test": true}})
+
+	for i := 0; i < 5; i++ {
+		outbox.Deliver()
+		store.now = store.now.Add(time.Hour)
+	}
+
+	q := store.queued[id]
+	if q.status != "failed" || q.Attempts != outbox.MaxAttempts {
+		t.Fatalf("status %s after %d attempts, want failed after %d", q.status, q.Attempts, outbox.MaxAttempts)
+	}
+}
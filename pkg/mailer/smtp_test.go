@@ -0,0 +1,204 @@
+package mailer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+// standIn is a minimal SMTP server that records one conversation.
+type standIn struct {
+	addr     *net.TCPAddr
+	starttls bool
+
+	done     chan struct{}
+	mailFrom string
+	rcptTo   []string
+	data     string
+}
+
+func newStandIn(t *testing.T, starttls bool) *standIn {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	s := &standIn{addr: l.Addr().(*net.TCPAddr), starttls: starttls, done: make(chan struct{})}
+	go s.serve(l)
+	return s
+}
+
+func (s *standIn) serve(l net.Listener) {
+	defer close(s.done)
+
+	c, err := l.Accept()
+	if err != nil {
+		return
+	}
+	defer c.Close()
+
+	r := bufio.NewReader(c)
+	reply := func(line string) { fmt.Fprint(c, line+"\r\n") }
+
+	reply("220 stand-in ready")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.Fields(line + " ")[0])
+
+		switch verb {
+		case "EHLO", "HELO":
+			if s.starttls {
+				reply("250-stand-in")
+				reply("250 STARTTLS")
+			} else {
+				reply("250 stand-in")
+			}
+		case "MAIL":
+			s.mailFrom = line
+			reply("250 ok")
+		case "RCPT":
+			s.rcptTo = append(s.rcptTo, line)
+			reply("250 ok")
+		case "DATA":
+			reply("354 end with .")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			s.data = data.String()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func TestSMTPSendToStandIn(t *testing.T) {
+	server := newStandIn(t, false)
+
+	s := &SMTP{Host: "127.0.0.1", Port: server.addr.Port, TLS: "none", From: "School <no-reply@school.test>"}
+
+	msg := Message{
+		To:      "Ann Lee <ann@example.test>",
+		Subject: "Reset your password",
+		Text:    "Hello Ann,\nuse https://school.test/reset/abc to reset.",
+		HTML:    "<p>Hello Ann,</p><p><a href=\"https://school.test/reset/abc\">Reset</a></p>",
+	}
+
+	if err := s.Send(msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	<-server.done
+
+	if !strings.HasPrefix(server.mailFrom, "MAIL FROM:<no-reply@school.test>") {
+		t.Errorf("envelope sender = %q", server.mailFrom)
+	}
+	if len(server.rcptTo) != 1 || server.rcptTo[0] != "RCPT TO:<ann@example.test>" {
+		t.Errorf("envelope recipients = %q", server.rcptTo)
+	}
+
+	m, err := mail.ReadMessage(strings.NewReader(server.data))
+	if err != nil {
+		t.Fatalf("reading message: %v", err)
+	}
+
+	for header, want := range map[string]string{
+		"From":         "School <no-reply@school.test>",
+		"To":           "Ann Lee <ann@example.test>",
+		"Subject":      "Reset your password",
+		"MIME-Version": "1.0",
+	} {
+		if got := m.Header.Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+
+	if id := m.Header.Get("Message-ID"); !strings.HasSuffix(id, "@school.test>") {
+		t.Errorf("Message-ID = %q", id)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(m.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q (%v)", m.Header.Get("Content-Type"), err)
+	}
+
+	parts := map[string]string{}
+	mr := multipart.NewReader(m.Body, params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+
+		// the reader undoes the quoted-printable encoding
+		body, err := io.ReadAll(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		contentType, _, _ := mime.ParseMediaType(p.Header.Get("Content-Type"))
+		parts[contentType] = string(body)
+	}
+
+	if got := parts["text/plain"]; got != strings.ReplaceAll(msg.Text, "\n", "\r\n") {
+		t.Errorf("text part = %q", got)
+	}
+	if got := parts["text/html"]; got != msg.HTML {
+		t.Errorf("html part = %q", got)
+	}
+}
+
+func TestSMTPRefusesWithoutSTARTTLS(t *testing.T) {
+	server := newStandIn(t, false)
+
+	s := &SMTP{Host: "127.0.0.1", Port: server.addr.Port, From: "no-reply@school.test"}
+
+	err := s.Send(Message{To: "ann@example.test", Subject: "Hi", Text: "secret link"})
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Fatalf("Send = %v, want a STARTTLS error", err)
+	}
+	<-server.done
+
+	if server.data != "" {
+		t.Errorf("message was sent in the clear: %q", server.data)
+	}
+}
+
+func TestComposeStripsHeaderInjection(t *testing.T) {
+	data, err := compose("no-reply@school.test", Message{To: "ann@example.test\r\nBcc: eve@example.test", Subject: "Hi", Text: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bcc := m.Header.Get("Bcc"); bcc != "" {
+		t.Errorf("Bcc header injected: %q", bcc)
+	}
+}
@@ -0,0 +1,68 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	htmltemplate "html/template"
+	"strings"
+	"text/template"
+)
+
+// Templates. Each has a .txt file, whose "subject" block is the subject,
+// and an .html file defining the "body" of layout.html.
+const (
+	PasswordReset  = "password_reset"
+	Invitation     = "invitation"
+	Lockout        = "lockout"
+	GuardianNotice = "guardian_notice"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+var htmlFuncs = htmltemplate.FuncMap{
+	// paragraphs splits free text on blank lines
+	"paragraphs": func(s string) []string {
+		var out []string
+		for _, p := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n\n") {
+			if p = strings.TrimSpace(p); p != "" {
+				out = append(out, p)
+			}
+		}
+		return out
+	},
+}
+
+// Render fills in the named template for one recipient.
+func Render(to, name string, data any) (Message, error) {
+	text, err := template.ParseFS(templateFS, "templates/"+name+".txt")
+	if err != nil {
+		return Message{}, err
+	}
+
+	html, err := htmltemplate.New(name).Funcs(htmlFuncs).ParseFS(templateFS, "templates/layout.html", "templates/"+name+".html")
+	if err != nil {
+		return Message{}, err
+	}
+
+	var subject, body, htmlBody bytes.Buffer
+
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, err
+	}
+
+	if err := text.Execute(&body, data); err != nil {
+		return Message{}, err
+	}
+
+	if err := html.ExecuteTemplate(&htmlBody, "layout", data); err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    body.String(),
+		HTML:    htmlBody.String(),
+	}, nil
+}
@@ -0,0 +1,55 @@
+package mailer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderTemplates(t *testing.T) {
+	data := map[string]any{
+		"Name":     "Ann <Lee>",
+		"Username": "ann",
+		"Role":     "staff",
+		"Link":     "https://school.test/reset?token=abc&x=1",
+		"Minutes":  30,
+		"Hours":    72,
+		"Lockout":  "15 minutes",
+		"Student":  "Bob Lee",
+		"Subject":  "School trip",
+		"Message":  "First paragraph.\n\nSecond <b>paragraph</b>.",
+	}
+
+	for name, subject := range map[string]string{
+		PasswordReset:  "Reset your password",
+		Invitation:     "You have been invited to sign in",
+		Lockout:        "Your account has been locked",
+		GuardianNotice: "School trip",
+	} {
+		msg, err := Render("ann@example.test", name, data)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if msg.To != "ann@example.test" || msg.Subject != subject {
+			t.Errorf("%s: to %q, subject %q", name, msg.To, msg.Subject)
+		}
+
+		if !strings.Contains(msg.Text, "Ann <Lee>") {
+			t.Errorf("%s: text part lacks the name: %q", name, msg.Text)
+		}
+
+		if strings.Contains(msg.HTML, "<Lee>") || !strings.Contains(msg.HTML, "Ann &lt;Lee&gt;") {
+			t.Errorf("%s: html part does not escape the name", name)
+		}
+	}
+
+	msg, _ := Render("ann@example.test", PasswordReset, data)
+	if !strings.Contains(msg.Text, "https://school.test/reset?token=abc&x=1") {
+		t.Errorf("text part mangles the link: %q", msg.Text)
+	}
+
+	msg, _ = Render("ann@example.test", GuardianNotice, data)
+	if !strings.Contains(msg.HTML, "<p>First paragraph.</p>") || !strings.Contains(msg.HTML, "<p>Second &lt;b&gt;paragraph&lt;/b&gt;.</p>") {
+		t.Errorf("guardian notice paragraphs: %s", msg.HTML)
+	}
+}
@@ -0,0 +1,209 @@
+// Package pdf renders simple text documents (headings, paragraphs and
+// tables) as PDF using only the standard library and the built-in Helvetica
+// fonts, so reports can be produced in-process.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	pageWidth  = 595.28 // A4
+	pageHeight = 841.89
+	margin     = 50.0
+
+	fontRegular = "F1"
+	fontBold    = "F2"
+)
+
+type Document struct {
+	title string
+	pages []*bytes.Buffer
+	cur   *bytes.Buffer
+	y     float64
+}
+
+func New(title string) *Document {
+	d := &Document{title: title}
+	d.newPage()
+	return d
+}
+
+func (d *Document) newPage() {
+	d.cur = &bytes.Buffer{}
+	d.pages = append(d.pages, d.cur)
+	d.y = pageHeight - margin
+}
+
+// ensure starts a new page when less than h points are left.
+func (d *Document) ensure(h float64) {
+	if d.y-h < margin {
+		d.newPage()
+	}
+}
+
+func (d *Document) line(font string, size, x float64, text string) {
+	fmt.Fprintf(d.cur, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, d.y, escape(text))
+}
+
+func (d *Document) Heading(text string) {
+	d.ensure(26)
+	d.y -= 18
+	d.line(fontBold, 16, margin, text)
+	d.y -= 8
+}
+
+func (d *Document) Subheading(text string) {
+	d.ensure(22)
+	d.y -= 14
+	d.line(fontBold, 12, margin, text)
+	d.y -= 6
+}
+
+// Text writes a paragraph, wrapping it to the page width.
+func (d *Document) Text(text string) {
+	const size = 10
+	for _, l := range wrap(text, pageWidth-2*margin, size) {
+		d.ensure(14)
+		d.y -= 12
+		d.line(fontRegular, size, margin, l)
+	}
+	d.y -= 2
+}
+
+func (d *Document) Space(h float64) {
+	d.y -= h
+}
+
+// Table writes rows in fixed-width columns. widths are fractions of the
+// usable page width; cells that do not fit are truncated.
+func (d *Document) Table(widths []float64, header []string, rows [][]string) {
+	const size = 10
+	usable := pageWidth - 2*margin
+
+	writeRow := func(font string, cells []string) {
+		d.ensure(16)
+		d.y -= 14
+		x := margin
+		for i, cell := range cells {
+			if i >= len(widths) {
+				break
+			}
+			w := widths[i] * usable
+			d.line(font, size, x, truncate(cell, w-6, size))
+			x += w
+		}
+	}
+
+	writeRow(fontBold, header)
+	fmt.Fprintf(d.cur, "%.2f %.2f m %.2f %.2f l S\n", margin, d.y-4, pageWidth-margin, d.y-4)
+	d.y -= 2
+	for _, row := range rows {
+		writeRow(fontRegular, row)
+	}
+	d.y -= 6
+}
+
+// Bytes assembles the PDF file.
+func (d *Document) Bytes() []byte {
+	var out bytes.Buffer
+	var offsets []int
+
+	obj := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1-4 are fixed; each page then takes two objects: the page
+	// dictionary followed by its content stream.
+	const firstPageObj = 5
+	var kids []string
+	for i := range d.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", firstPageObj+i*2))
+	}
+
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range d.pages {
+		obj(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /%s 3 0 R /%s 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, fontRegular, fontBold, firstPageObj+i*2+1,
+		))
+		obj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	obj(fmt.Sprintf("<< /Title (%s) /Producer (school-api) >>", escape(d.title)))
+	infoObj := len(offsets)
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, infoObj, xref)
+
+	return out.Bytes()
+}
+
+// escape converts text to a WinAnsi PDF string literal body. Characters
+// outside Latin-1 are replaced with '?'.
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteByte(byte(r))
+		case r == '\n' || r == '\r' || r == '\t':
+			b.WriteByte(' ')
+		case r < 0x20 || r > 0xff:
+			b.WriteByte('?')
+		default:
+			b.WriteByte(byte(r))
+		}
+	}
+	return b.String()
+}
+
+// textWidth approximates Helvetica glyph widths as half the font size.
+func textWidth(s string, size float64) float64 {
+	return float64(len([]rune(s))) * size * 0.5
+}
+
+func wrap(text string, width, size float64) []string {
+	var lines []string
+	var current string
+
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if current != "" {
+			candidate = current + " " + word
+		}
+		if current != "" && textWidth(candidate, size) > width {
+			lines = append(lines, current)
+			current = word
+			continue
+		}
+		current = candidate
+	}
+	if current != "" || len(lines) == 0 {
+		lines = append(lines, current)
+	}
+
+	return lines
+}
+
+func truncate(s string, width, size float64) string {
+	runes := []rune(s)
+	for len(runes) > 0 && textWidth(string(runes), size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes)
+}
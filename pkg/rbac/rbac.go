@@ -0,0 +1,128 @@
+// Package rbac maps exec roles to the permissions they hold. A permission
+// is "resource:action", for example "students:delete".
+package rbac
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	RoleAdmin    = "admin"
+	RoleManager  = "manager"
+	RoleTeacher  = "teacher"
+	RoleStaff    = "staff"
+	RoleReadOnly = "read_only"
+)
+
+const (
+	Read   = "read"
+	Create = "create"
+	Update = "update"
+	Delete = "delete"
+)
+
+var Resources = []string{
+	"admissions",
+	"attachments",
+	"attendance",
+	"classes",
+	"exams",
+	"execs",
+	"fees",
+	"grades",
+	"guardians",
+	"homework",
+	"oauth_clients",
+	"students",
+	"teachers",
+	"terms",
+	"timetable",
+}
+
+var Actions = []string{Read, Create, Update, Delete}
+
+// grants per role. "*" matches any resource or action.
+var roleGrants = map[string][]string{
+	RoleAdmin: {"*:*"},
+	RoleManager: {
+		"*:read",
+		"admissions:*", "attachments:*", "attendance:*", "classes:*", "exams:*", "fees:*", "grades:*",
+		"guardians:*", "homework:*", "students:*", "teachers:*", "terms:*", "timetable:*",
+	},
+	RoleTeacher: {
+		"students:read", "teachers:read", "classes:read", "terms:read", "guardians:read", "timetable:read", "exams:read",
+		"attendance:read", "attendance:create",
+		"grades:read", "grades:create", "grades:update",
+		"homework:*",
+		"attachments:read", "attachments:create",
+	},
+	RoleStaff: {
+		"classes:read", "teachers:read", "terms:read", "timetable:read", "exams:read", "attendance:read",
+		"students:read", "students:create", "students:update",
+		"guardians:read", "guardians:create", "guardians:update",
+		"admissions:read", "admissions:create", "admissions:update",
+		"fees:read", "fees:create", "fees:update",
+		"attachments:read", "attachments:create",
+	},
+	RoleReadOnly: {
+		"admissions:read", "attachments:read", "attendance:read", "classes:read", "exams:read", "fees:read",
+		"grades:read", "guardians:read", "homework:read", "students:read", "teachers:read", "terms:read",
+		"timetable:read",
+	},
+}
+
+func IsValidRole(role string) bool {
+	_, ok := roleGrants[role]
+	return ok
+}
+
+// Roles lists the known roles.
+func Roles() []string {
+	roles := make([]string, 0, len(roleGrants))
+	for role := range roleGrants {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// Can reports whether role holds permission. Unknown roles hold nothing.
+func Can(role, permission string) bool {
+	resource, action, _ := strings.Cut(permission, ":")
+
+	for _, grant := range roleGrants[role] {
+		r, a, _ := strings.Cut(grant, ":")
+		if (r == "*" || r == resource) && (a == "*" || a == action) {
+			return true
+		}
+	}
+	return false
+}
+
+// Permissions expands the grants of role into the sorted list of concrete
+// permissions it holds.
+func Permissions(role string) []string {
+	permissions := []string{}
+	for _, resource := range Resources {
+		for _, action := range Actions {
+			if p := resource + ":" + action; Can(role, p) {
+				permissions = append(permissions, p)
+			}
+		}
+	}
+	return permissions
+}
+
+// RequiresTwoFactor reports whether execs with role must use two-factor
+// authentication. The roles are listed in TOTP_REQUIRED_ROLES, comma
+// separated; none are by default.
+func RequiresTwoFactor(role string) bool {
+	for _, r := range strings.Split(os.Getenv("TOTP_REQUIRED_ROLES"), ",") {
+		if strings.TrimSpace(r) == role && role != "" {
+			return true
+		}
+	}
+	return false
+}
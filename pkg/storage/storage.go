@@ -0,0 +1,98 @@
+// Package storage keeps uploaded files behind a small interface so the
+// backend can change without touching handlers. Files are addressed by the
+// SHA-256 of their content, which deduplicates identical uploads.
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+)
+
+var ErrTooLarge = errors.New("file exceeds the size limit")
+
+type Storage interface {
+	Put(key string, r io.Reader) error
+	Open(key string) (io.ReadSeekCloser, error)
+	Exists(key string) (bool, error)
+	Delete(key string) error
+}
+
+// Blob describes uploaded content.
+type Blob struct {
+	Key         string
+	Size        int64
+	ContentType string
+}
+
+// Upload is content held in a temporary file until it is stored, so it can
+// be checked before anything reaches the store. The content type is sniffed
+// from the data.
+type Upload struct {
+	Blob
+	tmp *os.File
+}
+
+// Stage reads r into an Upload and hashes it. Reading more than maxBytes
+// fails with ErrTooLarge. The caller must Close the upload.
+func Stage(r io.Reader, maxBytes int64) (*Upload, error) {
+	tmp, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		return nil, err
+	}
+	u := &Upload{tmp: tmp}
+
+	hash := sha256.New()
+
+	size, err := io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(r, maxBytes+1))
+	if err != nil {
+		u.Close()
+		return nil, err
+	}
+
+	if size > maxBytes {
+		u.Close()
+		return nil, ErrTooLarge
+	}
+
+	head := make([]byte, 512)
+	n, err := tmp.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		u.Close()
+		return nil, err
+	}
+
+	u.Blob = Blob{
+		Key:         hex.EncodeToString(hash.Sum(nil)),
+		Size:        size,
+		ContentType: http.DetectContentType(head[:n]),
+	}
+	return u, nil
+}
+
+// Store writes the upload to s under its checksum, skipping the write when
+// the same content is already stored. It reports whether it wrote anything.
+func (u *Upload) Store(s Storage) (bool, error) {
+	exists, err := s.Exists(u.Key)
+	if err != nil || exists {
+		return false, err
+	}
+
+	if _, err := u.tmp.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+
+	if err := s.Put(u.Key, u.tmp); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Close removes the temporary file.
+func (u *Upload) Close() error {
+	u.tmp.Close()
+	return os.Remove(u.tmp.Name())
+}
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// UserFromContext returns the exec id and role that JWTMiddleware stored on
+// the request context.
+func UserFromContext(ctx context.Context) (int, string) {
+	id, _ := strconv.Atoi(fmt.Sprint(ctx.Value("userId")))
+	role, _ := ctx.Value("role").(string)
+
+	return id, role
+}
+
+// SessionFromContext returns the session id of the access token.
+func SessionFromContext(ctx context.Context) string {
+	sid, _ := ctx.Value("sid").(string)
+	return sid
+}
+
+// TwoFactorFromContext reports whether the session behind the access token
+// passed a second factor.
+func TwoFactorFromContext(ctx context.Context) bool {
+	mfa, _ := ctx.Value("mfa").(bool)
+	return mfa
+}
+
+// APIKeyPermissionsFromContext returns the permissions on the API key the
+// request was made with. ok is false when no API key was used.
+func APIKeyPermissionsFromContext(ctx context.Context) (permissions []string, ok bool) {
+	permissions, ok = ctx.Value("apiKeyPermissions").([]string)
+	return permissions, ok
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+)
+
+const (
+	CSRFCookie = "csrf_token"
+	CSRFHeader = "X-CSRF-Token"
+)
+
+// CSRFToken is the anti-CSRF token for a session. It is an HMAC of the
+// session id, so it needs no storage and cannot be made up without
+// CSRF_SECRET (JWT_SECRET when unset). Clients read it from the csrf_token
+// cookie and echo it in the X-CSRF-Token header.
+func CSRFToken(sessionId string) string {
+	mac := hmac.New(sha256.New, []byte("csrf:"+csrfSecret()))
+	mac.Write([]byte(sessionId))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ValidCSRFToken never accepts a token when no secret is configured.
+func ValidCSRFToken(sessionId, token string) bool {
+	return sessionId != "" && csrfSecret() != "" && hmac.Equal([]byte(CSRFToken(sessionId)), []byte(token))
+}
+
+// CheckCSRFSecret fails when there is no secret to make CSRF tokens with,
+// as happens when only RS256 or EdDSA keys are configured. Every write made
+// with cookies would then be refused.
+func CheckCSRFSecret() error {
+	if csrfSecret() == "" {
+		return errors.New("CSRF_SECRET is required when JWT_SECRET is not set")
+	}
+	return nil
+}
+
+func csrfSecret() string {
+	if secret := os.Getenv("CSRF_SECRET"); secret != "" {
+		return secret
+	}
+	return os.Getenv("JWT_SECRET")
+}
@@ -1,34 +1,122 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"school-api/pkg/keyring"
 	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func SignToken(userId int, username, role string) (string, error) {
+const (
+	defaultAccessTokenTTL  = 15 * time.Minute
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+
+	// how long a login has to present its second factor
+	challengeTokenTTL  = 5 * time.Minute
+	challengeTokenType = "2fa_challenge"
+)
+
+var ErrInvalidChallenge = errors.New("challenge token is invalid or expired")
+
+// AccessTokenTTL is how long an access token lives, from ACCESS_TOKEN_TTL
+// (a Go duration such as "15m").
+func AccessTokenTTL() time.Duration {
+	return durationEnv("ACCESS_TOKEN_TTL", defaultAccessTokenTTL)
+}
+
+// RefreshTokenTTL is how long a session lasts without being refreshed, from
+// REFRESH_TOKEN_TTL.
+func RefreshTokenTTL() time.Duration {
+	return durationEnv("REFRESH_TOKEN_TTL", defaultRefreshTokenTTL)
+}
+
+func durationEnv(name string, fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(name)); err == nil && d > 0 {
+		return d
+	}
+	return fallback
+}
+
+// SignToken issues a short-lived access token for a session. It returns
+// the token and when it expires. twoFactor records whether the session
+// passed a second factor.
+func SignToken(userId int, username, role, sessionId string, twoFactor bool) (string, time.Time, error) {
 	id := strconv.Itoa(userId)
-	jwtSecret := os.Getenv("JWT_SECRET")
+	now := time.Now()
+	expires := now.Add(AccessTokenTTL())
 
 	claims := jwt.MapClaims{
 		"uid":  id,
 		"user": username,
 		"role": role,
-		"exp":  jwt.NewNumericDate(time.Now().Add(20000 * time.Second)),
+		"sid":  sessionId,
+		"mfa":  twoFactor,
+		"iat":  jwt.NewNumericDate(now),
+		"exp":  jwt.NewNumericDate(expires),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signedToken, err := sign(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return signedToken, expires, nil
+}
 
-	signedToken, err := token.SignedString([]byte(jwtSecret))
+// SignChallengeToken issues the token the first login step hands back when
+// a second factor is still needed. It is not an access token: it has no
+// session and JWTMiddleware refuses it.
+func SignChallengeToken(userId int) (string, time.Time, error) {
+	now := time.Now()
+	expires := now.Add(challengeTokenTTL)
 
+	signedToken, err := sign(jwt.MapClaims{
+		"uid": strconv.Itoa(userId),
+		"typ": challengeTokenType,
+		"iat": jwt.NewNumericDate(now),
+		"exp": jwt.NewNumericDate(expires),
+	})
 	if err != nil {
-		fmt.Println("JWT error", err)
-		return "", nil
+		return "", time.Time{}, err
 	}
 
-	return signedToken, nil
+	return signedToken, expires, nil
+}
+
+// ParseChallengeToken returns the exec id of a challenge token, or
+// ErrInvalidChallenge.
+func ParseChallengeToken(tokenStr string) (int, error) {
+	claims, err := ParseToken(tokenStr)
+	if err != nil || claims["typ"] != challengeTokenType {
+		return 0, ErrInvalidChallenge
+	}
+
+	userId, err := strconv.Atoi(fmt.Sprint(claims["uid"]))
+	if err != nil {
+		return 0, ErrInvalidChallenge
+	}
+
+	return userId, nil
+}
 
+// ParseToken verifies a token against the keyring and returns its claims.
+func ParseToken(tokenStr string) (jwt.MapClaims, error) {
+	kr, err := keyring.Default()
+	if err != nil {
+		return nil, err
+	}
+	return kr.Parse(tokenStr)
+}
+
+// sign signs claims with the keyring's current signing key.
+func sign(claims jwt.Claims) (string, error) {
+	kr, err := keyring.Default()
+	if err != nil {
+		return "", err
+	}
+	return kr.Sign(claims)
 }
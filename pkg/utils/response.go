@@ -3,6 +3,8 @@ package utils
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 // ----------------------
@@ -87,6 +89,29 @@ func Http404(w http.ResponseWriter, msg string) {
 	AppError(w, msg, nil)
 }
 
+// ----------------------
+// FORBIDDEN (403)
+// ----------------------
+
+func Forbidden(w http.ResponseWriter, msg string) {
+	WriteJSON(w, http.StatusForbidden, ErrorResponse{
+		Success: false,
+		Message: msg,
+	})
+}
+
+// ----------------------
+// TOO MANY ATTEMPTS (429)
+// ----------------------
+
+func Http429(w http.ResponseWriter, retryAfter time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+	WriteJSON(w, http.StatusTooManyRequests, ErrorResponse{
+		Success: false,
+		Message: "Too many failed attempts, try again in " + retryAfter.String(),
+	})
+}
+
 // ----------------------
 // REAL SERVER ERROR (500)
 // ----------------------